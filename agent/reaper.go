@@ -0,0 +1,97 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+	"github.com/woodpecker-ci/woodpecker/pipeline/rpc"
+)
+
+// Reaper removes the resources of pipelines that were never destroyed,
+// e.g. because the agent running them crashed.
+type Reaper struct {
+	client  rpc.Peer
+	engine  backend.Reaper
+	counter *State
+}
+
+func NewReaper(client rpc.Peer, engine backend.Reaper, state *State) Reaper {
+	return Reaper{
+		client:  client,
+		engine:  engine,
+		counter: state,
+	}
+}
+
+// Run reaps orphaned resources immediately and then every interval
+// until the context is canceled.
+func (r *Reaper) Run(ctx context.Context, interval time.Duration) {
+	for {
+		if err := r.Reap(ctx); err != nil {
+			log.Error().Err(err).Msg("cannot reap orphaned pipeline resources")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Reap removes the resources of all pipelines that are neither running
+// on this agent nor active according to the server.
+func (r *Reaper) Reap(ctx context.Context) error {
+	ids, err := r.engine.Pipelines(ctx)
+	if err != nil {
+		return err
+	}
+
+	var candidates []string
+	r.counter.Lock()
+	for _, id := range ids {
+		if _, ok := r.counter.Metadata[id]; !ok {
+			candidates = append(candidates, id)
+		}
+	}
+	r.counter.Unlock()
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	active, err := r.client.Active(ctx, candidates)
+	if err != nil {
+		return err
+	}
+	keep := map[string]bool{}
+	for _, id := range active {
+		keep[id] = true
+	}
+
+	for _, id := range candidates {
+		if keep[id] {
+			continue
+		}
+		log.Info().Str("id", id).Msg("reaping resources of orphaned pipeline")
+		if err := r.engine.Reap(ctx, id); err != nil {
+			log.Error().Err(err).Str("id", id).Msg("cannot reap pipeline resources")
+		}
+	}
+	return nil
+}
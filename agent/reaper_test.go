@@ -0,0 +1,92 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/rpc"
+)
+
+type mockPeer struct {
+	rpc.Peer
+	active map[string]bool
+	asked  []string
+}
+
+func (m *mockPeer) Active(_ context.Context, ids []string) ([]string, error) {
+	m.asked = ids
+	var active []string
+	for _, id := range ids {
+		if m.active[id] {
+			active = append(active, id)
+		}
+	}
+	return active, nil
+}
+
+type mockReaper struct {
+	pipelines []string
+	reaped    []string
+}
+
+func (m *mockReaper) Pipelines(context.Context) ([]string, error) {
+	return m.pipelines, nil
+}
+
+func (m *mockReaper) Reap(_ context.Context, id string) error {
+	m.reaped = append(m.reaped, id)
+	return nil
+}
+
+func TestReap(t *testing.T) {
+	peer := &mockPeer{active: map[string]bool{"2": true}}
+	engine := &mockReaper{pipelines: []string{"1", "2", "3", "4"}}
+	state := &State{Metadata: map[string]Info{"3": {ID: "3"}}}
+
+	reaper := NewReaper(peer, engine, state)
+	if err := reaper.Reap(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// pipelines running on this agent are never reaped.
+	if want := []string{"1", "2", "4"}; !reflect.DeepEqual(peer.asked, want) {
+		t.Errorf("Want active pipelines asked for %v, got %v", want, peer.asked)
+	}
+	sort.Strings(engine.reaped)
+	if want := []string{"1", "4"}; !reflect.DeepEqual(engine.reaped, want) {
+		t.Errorf("Want reaped pipelines %v, got %v", want, engine.reaped)
+	}
+}
+
+func TestReapNothing(t *testing.T) {
+	peer := &mockPeer{}
+	engine := &mockReaper{pipelines: []string{"1"}}
+	state := &State{Metadata: map[string]Info{"1": {ID: "1"}}}
+
+	reaper := NewReaper(peer, engine, state)
+	if err := reaper.Reap(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if peer.asked != nil {
+		t.Errorf("Want server not to be asked, got %v", peer.asked)
+	}
+	if len(engine.reaped) != 0 {
+		t.Errorf("Want nothing reaped, got %v", engine.reaped)
+	}
+}
@@ -16,10 +16,13 @@ package agent
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"io/ioutil"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,6 +32,7 @@ import (
 
 	"github.com/woodpecker-ci/woodpecker/pipeline"
 	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+	"github.com/woodpecker-ci/woodpecker/pipeline/cache"
 	"github.com/woodpecker-ci/woodpecker/pipeline/multipart"
 	"github.com/woodpecker-ci/woodpecker/pipeline/rpc"
 )
@@ -41,21 +45,34 @@ const (
 	maxFileUpload = 1000000
 )
 
+// Pool provides the engines pipelines run on.
+type Pool interface {
+	// Reserve blocks until an engine is available and returns it, or nil
+	// if the context is canceled first.
+	Reserve(ctx context.Context) backend.Engine
+	// Release releases the reserved engine.
+	Release(engine backend.Engine)
+}
+
 type Runner struct {
-	client   rpc.Peer
-	filter   rpc.Filter
-	hostname string
-	counter  *State
-	engine   *backend.Engine
+	client         rpc.Peer
+	filter         rpc.Filter
+	hostname       string
+	counter        *State
+	pool           Pool
+	cache          *cache.Cache
+	cloneReference bool
 }
 
-func NewRunner(workEngine rpc.Peer, f rpc.Filter, h string, state *State, backend *backend.Engine) Runner {
+func NewRunner(workEngine rpc.Peer, f rpc.Filter, h string, state *State, pool Pool, cache *cache.Cache, cloneReference bool) Runner {
 	return Runner{
-		client:   workEngine,
-		filter:   f,
-		hostname: h,
-		counter:  state,
-		engine:   backend,
+		client:         workEngine,
+		filter:         f,
+		hostname:       h,
+		counter:        state,
+		pool:           pool,
+		cache:          cache,
+		cloneReference: cloneReference,
 	}
 }
 
@@ -135,6 +152,19 @@ func (r *Runner) Run(ctx context.Context) error {
 		logger.Error().Err(err).Msg("pipeline initialization failed")
 	}
 
+	// the engine is reserved once the pipeline is received, so that it
+	// runs on the least loaded host which is healthy at that time
+	engine := r.pool.Reserve(ctx)
+	if engine == nil {
+		logger.Warn().Msg("pipeline canceled before an engine was available")
+		state.Finished = time.Now().Unix()
+		state.Exited = true
+		state.ExitCode = 137
+		state.Error = "pipeline canceled before an engine was available"
+		return r.client.Done(ctxmeta, work.ID, state)
+	}
+	defer r.pool.Release(engine)
+
 	var uploads sync.WaitGroup
 	defaultLogger := pipeline.LogFunc(func(proc *backend.Step, rc multipart.Reader) error {
 
@@ -248,6 +278,9 @@ func (r *Runner) Run(ctx context.Context) error {
 			ExitCode: state.Process.ExitCode,
 			Started:  time.Now().Unix(), // TODO do not do this
 			Finished: time.Now().Unix(),
+
+			PullStarted:  state.Process.PullStarted,
+			PullFinished: state.Process.PullFinished,
 		}
 		defer func() {
 			proclogger.Debug().Msg("update step status")
@@ -291,11 +324,22 @@ func (r *Runner) Run(ctx context.Context) error {
 		return nil
 	})
 
+	// label all resources so they can be removed by the reaper if the
+	// agent dies before the pipeline is destroyed.
+	labelResources(work.Config, map[string]string{
+		backend.LabelPipeline: work.ID,
+		backend.LabelAgent:    r.hostname,
+	})
+	if r.cloneReference {
+		withCloneReference(work.Config)
+	}
+
 	err = pipeline.New(work.Config,
 		pipeline.WithContext(ctx),
 		pipeline.WithLogger(defaultLogger),
 		pipeline.WithTracer(defaultTracer),
-		pipeline.WithEngine(*r.engine),
+		pipeline.WithEngine(engine),
+		pipeline.WithCache(r.cache),
 	).Run()
 
 	state.Finished = time.Now().Unix()
@@ -348,3 +392,106 @@ func extractRepositoryName(config *backend.Config) string {
 func extractBuildNumber(config *backend.Config) string {
 	return config.Stages[0].Steps[0].Environment["DRONE_BUILD_NUMBER"]
 }
+
+// labelResources sets the labels on the pipeline and all of its steps.
+func labelResources(config *backend.Config, labels map[string]string) {
+	if config.Labels == nil {
+		config.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		config.Labels[k] = v
+	}
+	for _, stage := range config.Stages {
+		for _, step := range stage.Steps {
+			if step.Labels == nil {
+				step.Labels = map[string]string{}
+			}
+			for k, v := range labels {
+				step.Labels[k] = v
+			}
+		}
+	}
+}
+
+// referencePath is the path the reference repository is mounted at in
+// the clone step.
+const referencePath = "/woodpecker/reference"
+
+// referenceGit runs git without hooks or the system and global config, so
+// that neither the clone nor the reference repository execute commands.
+const referenceGit = "git -c core.hooksPath=/dev/null -c core.fsmonitor=false"
+
+// referenceInit creates the reference repository if the volume is empty.
+const referenceInit = referenceGit + " init --bare --quiet " + referencePath + " || true"
+
+// referenceUpdate fetches the objects of a complete clone into the
+// reference repository. Shallow clones are skipped, they would make the
+// reference repository shallow.
+const referenceUpdate = `if [ "$(` + referenceGit + ` rev-parse --is-shallow-repository)" = false ]; then
+  ` + referenceGit + ` --git-dir=` + referencePath + ` fetch --quiet --no-tags "$PWD" +HEAD:refs/heads/reference
+fi || true`
+
+// withCloneReference mounts a volume holding a reference repository of
+// the repo into the default clone step. The volume persists between
+// builds, clone images supporting the reference setting borrow the
+// objects of the repository from it instead of fetching them. The
+// reference repository is created before and updated after the clone
+// step using the git binary of the clone image. Custom clone steps run
+// images of the pipeline and never get the volume.
+func withCloneReference(config *backend.Config) {
+	var stages []*backend.Stage
+	for _, stage := range config.Stages {
+		if !stage.DefaultClone {
+			stages = append(stages, stage)
+			continue
+		}
+		before := &backend.Stage{Name: stage.Name + "_reference_init", Alias: stage.Alias}
+		after := &backend.Stage{Name: stage.Name + "_reference_update", Alias: stage.Alias}
+		for _, step := range stage.Steps {
+			repo := step.Environment["CI_REPO"]
+			if repo == "" {
+				continue
+			}
+			step.Volumes = append(step.Volumes, referenceVolume(repo)+":"+referencePath)
+			step.Environment["PLUGIN_REFERENCE"] = referencePath
+
+			before.Steps = append(before.Steps, referenceStep(step, "_reference_init", referenceInit))
+			after.Steps = append(after.Steps, referenceStep(step, "_reference_update", referenceUpdate))
+		}
+		if len(before.Steps) == 0 {
+			stages = append(stages, stage)
+			continue
+		}
+		stages = append(stages, before, stage, after)
+	}
+	config.Stages = stages
+}
+
+// referenceVolume returns the name of the volume holding the reference
+// repository of the repo. The name is derived from a hash of the full
+// repository name so that distinct repositories never share a volume.
+func referenceVolume(repo string) string {
+	sum := sha256.Sum256([]byte(repo))
+	return "woodpecker_reference_" + hex.EncodeToString(sum[:])
+}
+
+// referenceStep returns a copy of the clone step running the shell
+// script instead of the clone plugin. The script does not need the
+// credentials of the clone step, they are not passed to it.
+func referenceStep(clone *backend.Step, suffix, script string) *backend.Step {
+	step := *clone
+	step.Name = clone.Name + suffix
+	step.Pull = false
+	step.Entrypoint = []string{"/bin/sh", "-c"}
+	step.Command = []string{script}
+	step.Environment = map[string]string{}
+	for k, v := range clone.Environment {
+		if !strings.Contains(k, "NETRC") {
+			step.Environment[k] = v
+		}
+	}
+	step.Environment["GIT_CONFIG_NOSYSTEM"] = "1"
+	step.Environment["GIT_CONFIG_GLOBAL"] = "/dev/null"
+	step.Volumes = append([]string{}, clone.Volumes...)
+	return &step
+}
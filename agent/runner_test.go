@@ -0,0 +1,85 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+)
+
+func TestWithCloneReference(t *testing.T) {
+	clone := &backend.Step{Name: "clone", Environment: map[string]string{"CI_REPO": "Octocat/hello-world", "CI_NETRC_PASSWORD": "password"}}
+	build := &backend.Step{Name: "build", Environment: map[string]string{"CI_REPO": "Octocat/hello-world"}}
+	custom := &backend.Step{Name: "custom", Environment: map[string]string{"CI_REPO": "Octocat/hello-world"}}
+	config := &backend.Config{
+		Stages: []*backend.Stage{
+			{Name: "clone", Alias: "clone", DefaultClone: true, Steps: []*backend.Step{clone}},
+			{Name: "build", Alias: "build", Steps: []*backend.Step{build}},
+			// custom clone steps named clone are not the default clone step
+			{Name: "custom", Alias: "clone", Steps: []*backend.Step{custom}},
+		},
+	}
+	withCloneReference(config)
+
+	volume := referenceVolume("Octocat/hello-world")
+	want := []string{volume + ":/woodpecker/reference"}
+	if !reflect.DeepEqual(clone.Volumes, want) {
+		t.Errorf("Want clone volumes %v, got %v", want, clone.Volumes)
+	}
+	if clone.Environment["PLUGIN_REFERENCE"] != "/woodpecker/reference" {
+		t.Errorf("Want reference setting on the clone step")
+	}
+	for _, step := range []*backend.Step{build, custom} {
+		if len(step.Volumes) != 0 || step.Environment["PLUGIN_REFERENCE"] != "" {
+			t.Errorf("Want no reference on step %s", step.Name)
+		}
+	}
+
+	var stages []string
+	for _, stage := range config.Stages {
+		stages = append(stages, stage.Name)
+	}
+	wantStages := []string{"clone_reference_init", "clone", "clone_reference_update", "build", "custom"}
+	if !reflect.DeepEqual(stages, wantStages) {
+		t.Errorf("Want stages %v, got %v", wantStages, stages)
+	}
+	for _, stage := range []*backend.Stage{config.Stages[0], config.Stages[2]} {
+		step := stage.Steps[0]
+		if stage.Alias != "clone" || !reflect.DeepEqual(step.Volumes, want) {
+			t.Errorf("Want reference step %s to report to the clone step with the reference volume", step.Name)
+		}
+		if len(step.Command) != 1 || !strings.Contains(step.Command[0], "/woodpecker/reference") {
+			t.Errorf("Want reference step %s to run a script on the reference repository", step.Name)
+		}
+		if !strings.Contains(step.Command[0], "core.hooksPath=/dev/null") {
+			t.Errorf("Want reference step %s to run git without hooks", step.Name)
+		}
+		if _, ok := step.Environment["CI_NETRC_PASSWORD"]; ok {
+			t.Errorf("Want reference step %s to run without the netrc credentials", step.Name)
+		}
+	}
+}
+
+func TestReferenceVolume(t *testing.T) {
+	if referenceVolume("a_b/c") == referenceVolume("a/b_c") {
+		t.Errorf("Want distinct volumes for distinct repositories")
+	}
+	if referenceVolume("octocat/hello-world") == referenceVolume("Octocat/hello-world") {
+		t.Errorf("Want distinct volumes for repositories differing in case")
+	}
+}
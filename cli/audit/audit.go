@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/woodpecker-go/woodpecker"
+)
+
+// Command exports the audit command.
+var Command = &cli.Command{
+	Name:  "audit",
+	Usage: "query the audit log",
+	Flags: common.GlobalFlags,
+	Subcommands: []*cli.Command{
+		auditListCmd,
+		auditExportCmd,
+	},
+}
+
+var filterFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "actor",
+		Usage: "filter by user login of the actor",
+	},
+	&cli.StringFlag{
+		Name:  "action",
+		Usage: "filter by action (e.g. secret.create)",
+	},
+	&cli.StringFlag{
+		Name:  "repository",
+		Usage: "filter by repository name (e.g. octocat/hello-world)",
+	},
+	&cli.DurationFlag{
+		Name:  "since",
+		Usage: "only show events newer than duration (e.g. 24h)",
+	},
+	&cli.DurationFlag{
+		Name:  "until",
+		Usage: "only show events older than duration (e.g. 1h)",
+	},
+}
+
+func parseFilter(c *cli.Context) *woodpecker.AuditFilter {
+	filter := &woodpecker.AuditFilter{
+		Actor:  c.String("actor"),
+		Action: c.String("action"),
+		Repo:   c.String("repository"),
+	}
+	if since := c.Duration("since"); since != 0 {
+		filter.After = time.Now().Add(-since).Unix()
+	}
+	if until := c.Duration("until"); until != 0 {
+		filter.Before = time.Now().Add(-until).Unix()
+	}
+	return filter
+}
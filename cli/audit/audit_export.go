@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+var auditExportCmd = &cli.Command{
+	Name:      "export",
+	Usage:     "export audit events as json lines",
+	ArgsUsage: " ",
+	Action:    auditExport,
+	Flags: append(append(common.GlobalFlags, filterFlags...),
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "write to file instead of stdout",
+		},
+	),
+}
+
+func auditExport(c *cli.Context) error {
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	body, err := client.AuditExport(parseFilter(c))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var out io.Writer = os.Stdout
+	if path := c.String("output"); path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	_, err = io.Copy(out, body)
+	return err
+}
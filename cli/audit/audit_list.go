@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+var auditListCmd = &cli.Command{
+	Name:      "ls",
+	Usage:     "list audit events",
+	ArgsUsage: " ",
+	Action:    auditList,
+	Flags: append(append(common.GlobalFlags, filterFlags...),
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "limit the list size",
+			Value: 25,
+		},
+		common.FormatFlag(tmplAuditList),
+	),
+}
+
+func auditList(c *cli.Context) error {
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	filter := parseFilter(c)
+	filter.Limit = c.Int("limit")
+	events, err := client.AuditList(filter)
+	if err != nil || len(events) == 0 {
+		return err
+	}
+
+	tmpl, err := template.New("_").Funcs(auditFuncMap).Parse(c.String("format") + "\n")
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		tmpl.Execute(os.Stdout, event)
+	}
+	return nil
+}
+
+// template for audit list items
+var tmplAuditList = "\x1b[33m{{ .Action }} \x1b[0m" + `
+Time: {{ time .Created }}
+Actor: {{ .Actor }}
+{{- if .Repo }}
+Repo: {{ .Repo }}
+{{- end }}
+{{- if .Target }}
+Target: {{ .Target }}
+{{- end }}
+{{- if .Data }}
+Data: {{ data .Data }}
+{{- end }}
+`
+
+var auditFuncMap = template.FuncMap{
+	"time": func(t int64) string {
+		return time.Unix(t, 0).Format(time.RFC3339)
+	},
+	"data": func(m map[string]string) string {
+		var pairs []string
+		for k, v := range m {
+			pairs = append(pairs, k+"="+v)
+		}
+		return strings.Join(pairs, ", ")
+	},
+}
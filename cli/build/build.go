@@ -20,6 +20,7 @@ var Command = &cli.Command{
 		buildStartCmd,
 		buildApproveCmd,
 		buildDeclineCmd,
+		buildPromoteCmd,
 		buildQueueCmd,
 		buildKillCmd,
 		buildPsCmd,
@@ -0,0 +1,69 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+var buildPromoteCmd = &cli.Command{
+	Name:      "promote",
+	Usage:     "promote a build to an environment",
+	ArgsUsage: "<repo/name> <build> <environment>",
+	Action:    buildPromote,
+	Flags: append(common.GlobalFlags,
+		common.FormatFlag(tmplBuildPromote),
+		&cli.StringSliceFlag{
+			Name:    "param",
+			Aliases: []string{"p"},
+			Usage:   "custom parameters to be injected into the job environment. Format: KEY=value",
+		},
+	),
+}
+
+func buildPromote(c *cli.Context) error {
+	repo := c.Args().First()
+	owner, name, err := internal.ParseRepo(repo)
+	if err != nil {
+		return err
+	}
+	number, err := strconv.Atoi(c.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	target := c.Args().Get(2)
+	if target == "" {
+		return fmt.Errorf("Please specify the target environment (ie production)")
+	}
+
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	params := internal.ParseKeyPair(c.StringSlice("param"))
+	build, err := client.Promote(owner, name, number, target, params)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(os.Stdout, build)
+}
+
+// template for build promotion information
+var tmplBuildPromote = `Number: {{ .Number }}
+Parent: {{ .Parent }}
+Status: {{ .Status }}
+Commit: {{ .Commit }}
+Branch: {{ .Branch }}
+Target: {{ .Deploy }}`
@@ -22,6 +22,14 @@ var buildStartCmd = &cli.Command{
 			Aliases: []string{"p"},
 			Usage:   "custom parameters to be injected into the job environment. Format: KEY=value",
 		},
+		&cli.BoolFlag{
+			Name:  "failed",
+			Usage: "restart the failed workflows within the build",
+		},
+		&cli.IntSliceFlag{
+			Name:  "pid",
+			Usage: "restart the workflow with the pid within the build",
+		},
 	),
 }
 
@@ -56,6 +64,16 @@ func buildStart(c *cli.Context) (err error) {
 		}
 	}
 
+	if c.Bool("failed") || len(c.IntSlice("pid")) != 0 {
+		build, err := client.BuildRestart(owner, name, number, c.Bool("failed"), c.IntSlice("pid"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Restarting workflows of build %s/%s#%d\n", owner, name, build.Number)
+		return nil
+	}
+
 	params := internal.ParseKeyPair(c.StringSlice("param"))
 
 	build, err := client.BuildStart(owner, name, number, params)
@@ -0,0 +1,31 @@
+package environment
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+)
+
+// Command exports the environment command.
+var Command = &cli.Command{
+	Name:  "environment",
+	Usage: "manage deployment environments",
+	Flags: common.GlobalFlags,
+	Subcommands: []*cli.Command{
+		environmentListCmd,
+		environmentCreateCmd,
+		environmentUpdateCmd,
+		environmentDeleteCmd,
+		environmentHistoryCmd,
+		environmentRollbackCmd,
+	},
+}
+
+var environmentFuncMap = template.FuncMap{
+	"list": func(s []string) string {
+		return strings.Join(s, ", ")
+	},
+}
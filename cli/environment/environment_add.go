@@ -0,0 +1,44 @@
+package environment
+
+import (
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+	"github.com/woodpecker-ci/woodpecker/woodpecker-go/woodpecker"
+)
+
+var environmentCreateCmd = &cli.Command{
+	Name:      "add",
+	Usage:     "adds an environment",
+	ArgsUsage: "<repo/name> <environment>",
+	Action:    environmentCreate,
+	Flags: append(common.GlobalFlags,
+		&cli.StringSliceFlag{
+			Name:  "branch",
+			Usage: "branches allowed to deploy to the environment",
+		},
+		&cli.BoolFlag{
+			Name:  "require-approval",
+			Usage: "deployments to the environment must be approved",
+		},
+	),
+}
+
+func environmentCreate(c *cli.Context) error {
+	owner, name, err := internal.ParseRepo(c.Args().First())
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	env := &woodpecker.Environment{
+		Name:            c.Args().Get(1),
+		Branches:        c.StringSlice("branch"),
+		RequireApproval: c.Bool("require-approval"),
+	}
+	_, err = client.EnvironmentCreate(owner, name, env)
+	return err
+}
@@ -0,0 +1,62 @@
+package environment
+
+import (
+	"os"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+var environmentHistoryCmd = &cli.Command{
+	Name:      "history",
+	Usage:     "show the deployments to an environment",
+	ArgsUsage: "<repo/name> <environment>",
+	Action:    environmentHistory,
+	Flags: append(common.GlobalFlags,
+		common.FormatFlag(tmplDeploymentList),
+		&cli.IntFlag{
+			Name:  "page",
+			Usage: "page of the history",
+			Value: 1,
+		},
+	),
+}
+
+func environmentHistory(c *cli.Context) error {
+	owner, name, err := internal.ParseRepo(c.Args().First())
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	builds, err := client.DeploymentList(owner, name, c.Args().Get(1), c.Int("page"))
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+	if err != nil {
+		return err
+	}
+	for _, build := range builds {
+		if err := tmpl.Execute(os.Stdout, build); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// template for deployment list items
+var tmplDeploymentList = "\x1b[33mBuild #{{ .Number }} \x1b[0m" + `
+Status: {{ .Status }}
+Commit: {{ .Commit }}
+Branch: {{ .Branch }}
+Sender: {{ .Sender }}
+{{- if .Parent }}
+Parent: #{{ .Parent }}
+{{- end }}
+`
@@ -0,0 +1,59 @@
+package environment
+
+import (
+	"os"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+var environmentListCmd = &cli.Command{
+	Name:      "ls",
+	Usage:     "list environments and their deployed commits",
+	ArgsUsage: "<repo/name>",
+	Action:    environmentList,
+	Flags: append(common.GlobalFlags,
+		common.FormatFlag(tmplEnvironmentList),
+	),
+}
+
+func environmentList(c *cli.Context) error {
+	owner, name, err := internal.ParseRepo(c.Args().First())
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	list, err := client.EnvironmentList(owner, name)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("_").Funcs(environmentFuncMap).Parse(c.String("format") + "\n")
+	if err != nil {
+		return err
+	}
+	for _, env := range list {
+		if err := tmpl.Execute(os.Stdout, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// template for environment list items
+var tmplEnvironmentList = "\x1b[33m{{ .Name }} \x1b[0m" + `
+{{- if .Branches }}
+Branches: {{ list .Branches }}
+{{- else }}
+Branches: <any>
+{{- end }}
+Approval: {{ .RequireApproval }}
+{{- if .Deployed }}
+Deployed: #{{ .Deployed.Number }} {{ .Deployed.Commit }} ({{ .Deployed.Branch }})
+{{- end }}
+`
@@ -0,0 +1,28 @@
+package environment
+
+import (
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+var environmentDeleteCmd = &cli.Command{
+	Name:      "rm",
+	Usage:     "remove an environment",
+	ArgsUsage: "<repo/name> <environment>",
+	Action:    environmentDelete,
+	Flags:     common.GlobalFlags,
+}
+
+func environmentDelete(c *cli.Context) error {
+	owner, name, err := internal.ParseRepo(c.Args().First())
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	return client.EnvironmentDelete(owner, name, c.Args().Get(1))
+}
@@ -0,0 +1,84 @@
+package environment
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+	"github.com/woodpecker-ci/woodpecker/woodpecker-go/woodpecker"
+)
+
+var environmentRollbackCmd = &cli.Command{
+	Name:      "rollback",
+	Usage:     "deploy a previous deployment to an environment again",
+	ArgsUsage: "<repo/name> <environment> [build]",
+	Action:    environmentRollback,
+	Flags: append(common.GlobalFlags,
+		common.FormatFlag(tmplDeploymentList),
+	),
+}
+
+func environmentRollback(c *cli.Context) error {
+	owner, name, err := internal.ParseRepo(c.Args().First())
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	env := c.Args().Get(1)
+	if env == "" {
+		return fmt.Errorf("Please specify the environment (ie production)")
+	}
+
+	var number int
+	if arg := c.Args().Get(2); arg != "" {
+		number, err = strconv.Atoi(arg)
+		if err != nil {
+			return err
+		}
+	} else {
+		// roll back to the successful deployment before the deployed one
+		builds, err := client.DeploymentList(owner, name, env, 1)
+		if err != nil {
+			return err
+		}
+		number = previousDeployment(builds)
+		if number == 0 {
+			return fmt.Errorf("No previous successful deployment to %s", env)
+		}
+	}
+
+	build, err := client.Rollback(owner, name, env, number)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(os.Stdout, build)
+}
+
+// previousDeployment returns the number of the successful deployment
+// before the deployed one, the deployments are ordered by build number
+// descending.
+func previousDeployment(builds []*woodpecker.Build) int {
+	var deployed bool
+	for _, build := range builds {
+		if build.Status != woodpecker.StatusSuccess {
+			continue
+		}
+		if deployed {
+			return build.Number
+		}
+		deployed = true
+	}
+	return 0
+}
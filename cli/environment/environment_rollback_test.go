@@ -0,0 +1,23 @@
+package environment
+
+import (
+	"testing"
+
+	"github.com/woodpecker-ci/woodpecker/woodpecker-go/woodpecker"
+)
+
+func TestPreviousDeployment(t *testing.T) {
+	builds := []*woodpecker.Build{
+		{Number: 9, Status: woodpecker.StatusFailure},
+		{Number: 7, Status: woodpecker.StatusSuccess},
+		{Number: 5, Status: woodpecker.StatusKilled},
+		{Number: 3, Status: woodpecker.StatusSuccess},
+		{Number: 1, Status: woodpecker.StatusSuccess},
+	}
+	if got := previousDeployment(builds); got != 3 {
+		t.Errorf("Want previous deployment #3, got #%d", got)
+	}
+	if got := previousDeployment(builds[:3]); got != 0 {
+		t.Errorf("Want no previous deployment, got #%d", got)
+	}
+}
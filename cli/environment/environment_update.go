@@ -0,0 +1,52 @@
+package environment
+
+import (
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+	"github.com/woodpecker-ci/woodpecker/woodpecker-go/woodpecker"
+)
+
+var environmentUpdateCmd = &cli.Command{
+	Name:      "update",
+	Usage:     "update the protection rules of an environment",
+	ArgsUsage: "<repo/name> <environment>",
+	Action:    environmentUpdate,
+	Flags: append(common.GlobalFlags,
+		&cli.StringSliceFlag{
+			Name:  "branch",
+			Usage: "branches allowed to deploy to the environment",
+		},
+		&cli.BoolFlag{
+			Name:  "require-approval",
+			Usage: "deployments to the environment must be approved",
+		},
+	),
+}
+
+func environmentUpdate(c *cli.Context) error {
+	owner, name, err := internal.ParseRepo(c.Args().First())
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+
+	var (
+		branches        = c.StringSlice("branch")
+		requireApproval = c.Bool("require-approval")
+	)
+
+	patch := new(woodpecker.EnvironmentPatch)
+	if c.IsSet("branch") {
+		patch.Branches = &branches
+	}
+	if c.IsSet("require-approval") {
+		patch.RequireApproval = &requireApproval
+	}
+	_, err = client.EnvironmentPatch(owner, name, c.Args().Get(1), patch)
+	return err
+}
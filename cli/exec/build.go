@@ -0,0 +1,232 @@
+package exec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/compiler"
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+	"github.com/woodpecker-ci/woodpecker/server/shared"
+	"github.com/woodpecker-ci/woodpecker/woodpecker-go/woodpecker"
+)
+
+// execFromBuild executes the workflows of a build of the server locally
+// with the configs and metadata the build was created with.
+func execFromBuild(c *cli.Context) error {
+	owner, name, number, err := parseBuild(c.String("from-build"))
+	if err != nil {
+		return err
+	}
+
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	repo, err := client.Repo(owner, name)
+	if err != nil {
+		return err
+	}
+	build, err := client.Build(owner, name, number)
+	if err != nil {
+		return err
+	}
+	configs, err := client.BuildConfig(owner, name, number)
+	if err != nil {
+		return err
+	}
+	builds, err := client.BuildList(owner, name)
+	if err != nil {
+		return err
+	}
+
+	// check the workflows before secrets are prompted for
+	workflows, err := selectWorkflows(build.Procs, c.String("workflow"))
+	if err != nil {
+		return err
+	}
+	for _, workflow := range workflows {
+		if workflowConfig(configs, workflow.Name) == nil {
+			return fmt.Errorf("config of workflow %s not found", workflow.Name)
+		}
+	}
+
+	// the stored configs are evaluated and resolved already
+	var files []*remote.FileMeta
+	for _, config := range configs {
+		files = append(files, &remote.FileMeta{Name: config.Name, Data: config.Data})
+	}
+	// reproduce the metadata of the server unless the system is overridden
+	system := shared.DefaultSystem
+	if c.IsSet("system-name") {
+		system = c.String("system-name")
+	}
+	return execConfigs(c, files, ".", repoFromClient(repo), buildFromClient(build), buildFromClient(prevBuild(build, builds)), system, c.String("server"))
+}
+
+// parseBuild parses a build reference in the format owner/name#number.
+func parseBuild(str string) (owner, name string, number int, err error) {
+	parts := strings.SplitN(str, "#", 2)
+	if len(parts) != 2 {
+		return "", "", 0, fmt.Errorf("Error: Invalid build %q. eg octocat/hello-world#42", str)
+	}
+	owner, name, err = internal.ParseRepo(parts[0])
+	if err != nil {
+		return "", "", 0, err
+	}
+	number, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("Error: Invalid build number %q", parts[1])
+	}
+	return owner, name, number, nil
+}
+
+// selectWorkflows returns the workflows of the build with the name, or
+// all workflows if the name is empty. Skipped workflows are ignored.
+func selectWorkflows(procs []*woodpecker.Proc, name string) ([]*woodpecker.Proc, error) {
+	var workflows []*woodpecker.Proc
+	var names []string
+	for _, proc := range procs {
+		if proc.PPID != 0 || proc.State == "skipped" {
+			continue
+		}
+		names = append(names, proc.Name)
+		if name == "" || proc.Name == name || strconv.Itoa(proc.PID) == name {
+			workflows = append(workflows, proc)
+		}
+	}
+	if len(workflows) == 0 {
+		return nil, fmt.Errorf("workflow %q not found, the build has the workflows %s", name, strings.Join(names, ", "))
+	}
+	return workflows, nil
+}
+
+// workflowConfig returns the config the server names the workflow after.
+func workflowConfig(configs []*woodpecker.Config, workflow string) *woodpecker.Config {
+	for _, config := range configs {
+		if shared.SanitizePath(config.Name) == workflow {
+			return config
+		}
+	}
+	return nil
+}
+
+// prevBuild returns the latest build of the branch before the build, or
+// an empty build if the list of recent builds does not contain one.
+func prevBuild(build *woodpecker.Build, builds []*woodpecker.Build) *woodpecker.Build {
+	prev := new(woodpecker.Build)
+	for _, b := range builds {
+		if b.Branch == build.Branch && b.Number < build.Number && b.Number > prev.Number {
+			prev = b
+		}
+	}
+	return prev
+}
+
+// return the repository of the server as trusted repository, local
+// builds are not restricted.
+func repoFromClient(repo *woodpecker.Repo) *model.Repo {
+	return &model.Repo{
+		FullName:  repo.FullName,
+		Link:      repo.Link,
+		Clone:     repo.Clone,
+		Branch:    repo.Branch,
+		IsPrivate: repo.IsPrivate,
+		IsTrusted: true,
+	}
+}
+
+// return the build of the server.
+func buildFromClient(build *woodpecker.Build) *model.Build {
+	return &model.Build{
+		Number:   int64(build.Number),
+		Parent:   int64(build.Parent),
+		Created:  build.Created,
+		Started:  build.Started,
+		Finished: build.Finished,
+		Status:   build.Status,
+		Event:    build.Event,
+		Link:     build.Link,
+		Deploy:   build.Deploy,
+		Commit:   build.Commit,
+		Ref:      build.Ref,
+		Refspec:  build.Refspec,
+		Branch:   build.Branch,
+		Message:  build.Message,
+		Author:   build.Author,
+		Email:    build.Email,
+		Avatar:   build.Avatar,
+	}
+}
+
+// secretStore provides the secret values of local builds. Values missing
+// in the secrets file are prompted for once if prompt is true.
+type secretStore struct {
+	values map[string]string
+	prompt bool
+	reader *bufio.Reader
+}
+
+func newSecretStore(c *cli.Context, prompt bool) (*secretStore, error) {
+	s := &secretStore{
+		values: map[string]string{},
+		prompt: prompt,
+		reader: bufio.NewReader(os.Stdin),
+	}
+	if file := c.String("secrets-file"); file != "" {
+		values, err := godotenv.Read(file)
+		if err != nil {
+			return nil, err
+		}
+		// secret names are case insensitive
+		for k, v := range values {
+			s.values[strings.ToLower(k)] = v
+		}
+	}
+	return s, nil
+}
+
+// secrets returns the secrets requested by the steps of the config.
+func (s *secretStore) secrets(conf *yaml.Config) ([]compiler.Secret, error) {
+	var secrets []compiler.Secret
+	seen := map[string]bool{}
+	for _, container := range append(conf.Pipeline.Containers, conf.Services.Containers...) {
+		for _, requested := range container.Secrets.Secrets {
+			name := strings.ToLower(requested.Source)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			value, ok := s.values[name]
+			if !ok && s.prompt {
+				fmt.Fprintf(os.Stderr, "secret %s: ", name)
+				line, err := s.reader.ReadString('\n')
+				if err != nil && err != io.EOF {
+					return nil, err
+				}
+				if err == io.EOF && line == "" {
+					return nil, fmt.Errorf("secret %s is missing, provide it with --secrets-file", name)
+				}
+				value, ok = strings.TrimRight(line, "\r\n"), true
+				s.values[name] = value
+			}
+			if ok {
+				secrets = append(secrets, compiler.Secret{
+					Name:  name,
+					Value: value,
+				})
+			}
+		}
+	}
+	return secrets, nil
+}
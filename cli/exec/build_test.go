@@ -0,0 +1,75 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/woodpecker-ci/woodpecker/woodpecker-go/woodpecker"
+)
+
+func TestParseBuild(t *testing.T) {
+	owner, name, number, err := parseBuild("octocat/hello-world#42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner != "octocat" || name != "hello-world" || number != 42 {
+		t.Errorf("Unexpected build %s/%s#%d", owner, name, number)
+	}
+
+	for _, str := range []string{"octocat/hello-world", "octocat#42", "octocat/hello-world#latest"} {
+		if _, _, _, err := parseBuild(str); err == nil {
+			t.Errorf("Wanted error parsing %q", str)
+		}
+	}
+}
+
+func TestPrevBuild(t *testing.T) {
+	builds := []*woodpecker.Build{
+		{Number: 5, Branch: "main"},
+		{Number: 4, Branch: "develop"},
+		{Number: 3, Branch: "main"},
+		{Number: 2, Branch: "main"},
+	}
+	if prev := prevBuild(&woodpecker.Build{Number: 4, Branch: "main"}, builds); prev.Number != 3 {
+		t.Errorf("Wanted build 3, got %d", prev.Number)
+	}
+	if prev := prevBuild(&woodpecker.Build{Number: 1, Branch: "main"}, builds); prev.Number != 0 {
+		t.Errorf("Wanted no build, got %d", prev.Number)
+	}
+}
+
+func TestSelectWorkflows(t *testing.T) {
+	procs := []*woodpecker.Proc{
+		{PID: 1, Name: "test"},
+		{PID: 2, Name: "test"},
+		{PID: 3, Name: "deploy", State: "skipped"},
+		{PID: 4, PPID: 1, Name: "clone"},
+	}
+
+	workflows, err := selectWorkflows(procs, "test")
+	if err != nil || len(workflows) != 2 {
+		t.Errorf("Wanted both test workflows, got %v, %v", workflows, err)
+	}
+	workflows, err = selectWorkflows(procs, "2")
+	if err != nil || len(workflows) != 1 || workflows[0].PID != 2 {
+		t.Errorf("Wanted workflow 2, got %v, %v", workflows, err)
+	}
+	if _, err := selectWorkflows(procs, "deploy"); err == nil {
+		t.Error("Wanted error selecting a skipped workflow")
+	}
+}
+
+func TestWorkflowConfig(t *testing.T) {
+	configs := []*woodpecker.Config{
+		{Name: ".woodpecker/build.star"},
+		{Name: ".woodpecker/test.yml"},
+	}
+	if config := workflowConfig(configs, "test"); config == nil || config.Name != ".woodpecker/test.yml" {
+		t.Errorf("Unexpected config %v", config)
+	}
+	if config := workflowConfig(configs, "build"); config == nil || config.Name != ".woodpecker/build.star" {
+		t.Errorf("Unexpected config %v", config)
+	}
+	if config := workflowConfig(configs, "lint"); config != nil {
+		t.Errorf("Unexpected config %v", config)
+	}
+}
@@ -5,106 +5,174 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
 
-	"github.com/drone/envsubst"
 	"github.com/urfave/cli/v2"
 
 	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
 	"github.com/woodpecker-ci/woodpecker/pipeline"
 	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
 	"github.com/woodpecker-ci/woodpecker/pipeline/backend/docker"
-	"github.com/woodpecker-ci/woodpecker/pipeline/frontend"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/starlark"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/compiler"
-	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/linter"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/include"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/matrix"
 	"github.com/woodpecker-ci/woodpecker/pipeline/interrupt"
 	"github.com/woodpecker-ci/woodpecker/pipeline/multipart"
+	"github.com/woodpecker-ci/woodpecker/server"
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+	"github.com/woodpecker-ci/woodpecker/server/shared"
 )
 
 // Command exports the exec command.
 var Command = &cli.Command{
 	Name:      "exec",
 	Usage:     "execute a local build",
-	ArgsUsage: "[path/to/.woodpecker.yml]",
+	ArgsUsage: "[path/to/.woodpecker.yml|.star|.woodpecker/]",
 	Action:    exec,
 	Flags:     append(common.GlobalFlags, flags...),
 }
 
 func exec(c *cli.Context) error {
-	file := c.Args().First()
-	if file == "" {
-		file = ".woodpecker.yml"
+	if c.String("from-build") != "" {
+		return execFromBuild(c)
 	}
 
-	dat, err := ioutil.ReadFile(file)
+	files, dir, err := readConfigs(c.Args().First())
 	if err != nil {
 		return err
 	}
 
-	axes, err := matrix.ParseString(string(dat))
-	if err != nil {
-		return fmt.Errorf("Parse matrix fail")
-	}
-
-	if len(axes) == 0 {
-		axes = append(axes, matrix.Axis{})
-	}
-	for _, axis := range axes {
-		err := execWithAxis(c, axis)
-		if err != nil {
-			return err
-		}
+	repo, build, last := modelsFromContext(c)
+	if err := shared.EvalConfigs(repo, build, c.String("system-name"), c.String("system-link"), files); err != nil {
+		return err
 	}
-	return nil
+	return execConfigs(c, files, dir, repo, build, last, c.String("system-name"), c.String("system-link"))
 }
 
-func execWithAxis(c *cli.Context, axis matrix.Axis) error {
-	file := c.Args().First()
+// readConfigs reads the config file or the configs of the directory.
+// Without path the configs are looked up like the server does, the
+// .woodpecker directory is preferred over the .woodpecker.yml file. The
+// returned directory is mounted into the workspace of local builds.
+func readConfigs(file string) ([]*remote.FileMeta, string, error) {
 	if file == "" {
 		file = ".woodpecker.yml"
+		if info, err := os.Stat(".woodpecker"); err == nil && info.IsDir() {
+			file = ".woodpecker"
+		}
 	}
 
-	metadata := metadataFromContext(c, axis)
-	environ := metadata.Environ()
-	var secrets []compiler.Secret
-	for k, v := range metadata.EnvironDrone() {
-		environ[k] = v
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, "", err
 	}
-	for key, val := range metadata.Job.Matrix {
-		environ[key] = val
-		secrets = append(secrets, compiler.Secret{
-			Name:  key,
-			Value: val,
-		})
+	if !info.IsDir() {
+		dat, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, "", err
+		}
+		return []*remote.FileMeta{{Name: filepath.ToSlash(file), Data: dat}}, filepath.Dir(file), nil
 	}
 
-	droneEnv := make(map[string]string)
-	for _, env := range c.StringSlice("env") {
-		envs := strings.SplitN(env, "=", 2)
-		droneEnv[envs[0]] = envs[1]
+	entries, err := ioutil.ReadDir(file)
+	if err != nil {
+		return nil, "", err
 	}
+	var files []*remote.FileMeta
+	for _, entry := range entries {
+		if entry.IsDir() || !(strings.HasSuffix(entry.Name(), ".yml") || starlark.IsConfig(entry.Name())) {
+			continue
+		}
+		name := filepath.Join(file, entry.Name())
+		dat, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, "", err
+		}
+		files = append(files, &remote.FileMeta{Name: filepath.ToSlash(name), Data: dat})
+	}
+	if len(files) == 0 {
+		return nil, "", fmt.Errorf("no configs found in %s", file)
+	}
+	return files, filepath.Dir(file), nil
+}
 
-	tmpl, err := envsubst.ParseFile(file)
+// execConfigs builds the workflows of the configs like the server does and
+// executes the selected workflows in the order of their dependencies. The
+// directory is mounted into the workspace of local builds.
+func execConfigs(c *cli.Context, files []*remote.FileMeta, dir string, repo *model.Repo, build, last *model.Build, system, link string) error {
+	// secrets are prompted for when reproducing a build of the server
+	secrets, err := newSecretStore(c, c.String("from-build") != "")
 	if err != nil {
 		return err
 	}
-	confstr, err := tmpl.Execute(func(name string) string {
-		return environ[name]
-	})
+
+	b := shared.ProcBuilder{
+		Repo:  repo,
+		Curr:  build,
+		Last:  last,
+		Netrc: &model.Netrc{},
+		Link:  link,
+		Yamls: files,
+		Envs:  internal.ParseKeyPair(c.StringSlice("env")),
+		// the limits and defaults of the server do not apply to local builds,
+		// the volumes, networks and privileged plugins are options below
+		Pipeline: server.PipelineConfig{
+			MatrixLimits: matrix.DefaultLimits,
+		},
+		System:    system,
+		JobNumber: c.Int("job-number"),
+		// resolve the includes of the same repository from the working directory
+		Resolver: localFetcher{},
+		Options: func(proc *model.Proc, conf *yaml.Config) ([]compiler.Option, error) {
+			return compilerOptions(c, dir, secrets, proc, conf)
+		},
+	}
+	items, err := b.Build()
 	if err != nil {
 		return err
 	}
-
-	conf, err := yaml.ParseString(confstr)
+	items, err = selectItems(items, c.String("workflow"))
 	if err != nil {
 		return err
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), c.Duration("timeout"))
+	defer cancel()
+	ctx = interrupt.WithContext(ctx)
+
+	return runWorkflows(items, c.String("system-arch"), func(item *shared.BuildItem) error {
+		engine, err := docker.NewEnv()
+		if err != nil {
+			return err
+		}
+
+		// prefix the logs with the workflow if workflows run in parallel
+		var workflow string
+		if len(items) > 1 {
+			workflow = item.Proc.Name
+		}
+		return pipeline.New(item.Config,
+			pipeline.WithContext(ctx),
+			pipeline.WithTracer(pipeline.DefaultTracer),
+			pipeline.WithLogger(logger(workflow)),
+			pipeline.WithEngine(engine),
+		).Run()
+	})
+}
+
+// compilerOptions returns the compiler options of local builds, they
+// replace the options of the server.
+func compilerOptions(c *cli.Context, dir string, store *secretStore, proc *model.Proc, conf *yaml.Config) ([]compiler.Option, error) {
+	// workflows running in parallel must not share containers and volumes
+	prefix := fmt.Sprintf("%s_%d", c.String("prefix"), proc.PID)
+
 	// configure volumes for local execution
 	volumes := c.StringSlice("volumes")
 	if c.Bool("local") {
@@ -118,22 +186,27 @@ func execWithAxis(c *cli.Context, axis matrix.Axis) error {
 		if workspacePath == "" {
 			workspacePath = c.String("workspace-path")
 		}
-		dir, _ := filepath.Abs(filepath.Dir(file))
+		dir, _ := filepath.Abs(dir)
 
 		if runtime.GOOS == "windows" {
 			dir = convertPathForWindows(dir)
 		}
-		volumes = append(volumes, c.String("prefix")+"_default:"+workspaceBase)
+		volumes = append(volumes, prefix+"_default:"+workspaceBase)
 		volumes = append(volumes, dir+":"+path.Join(workspaceBase, workspacePath))
 	}
 
-	// lint the yaml file
-	if lerr := linter.New(linter.WithTrusted(true)).Lint(conf); lerr != nil {
-		return lerr
+	secrets, err := store.secrets(conf)
+	if err != nil {
+		return nil, err
+	}
+	for key, val := range proc.Environ {
+		secrets = append(secrets, compiler.Secret{
+			Name:  key,
+			Value: val,
+		})
 	}
 
-	// compiles the yaml file
-	compiled := compiler.New(
+	return []compiler.Option{
 		compiler.WithEscalated(
 			c.StringSlice("privileged")...,
 		),
@@ -145,10 +218,7 @@ func execWithAxis(c *cli.Context, axis matrix.Axis) error {
 		compiler.WithNetworks(
 			c.StringSlice("network")...,
 		),
-		compiler.WithPrefix(
-			c.String("prefix"),
-		),
-		compiler.WithProxy(),
+		compiler.WithPrefix(prefix),
 		compiler.WithLocal(
 			c.Bool("local"),
 		),
@@ -157,90 +227,57 @@ func execWithAxis(c *cli.Context, axis matrix.Axis) error {
 			c.String("netrc-password"),
 			c.String("netrc-machine"),
 		),
-		compiler.WithMetadata(metadata),
 		compiler.WithSecret(secrets...),
-		compiler.WithEnviron(droneEnv),
-	).Compile(conf)
-	engine, err := docker.NewEnv()
-	if err != nil {
-		return err
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), c.Duration("timeout"))
-	defer cancel()
-	ctx = interrupt.WithContext(ctx)
-
-	return pipeline.New(compiled,
-		pipeline.WithContext(ctx),
-		pipeline.WithTracer(pipeline.DefaultTracer),
-		pipeline.WithLogger(defaultLogger),
-		pipeline.WithEngine(engine),
-	).Run()
+	}, nil
 }
 
-// return the metadata from the cli context.
-func metadataFromContext(c *cli.Context, axis matrix.Axis) frontend.Metadata {
-	return frontend.Metadata{
-		Repo: frontend.Repo{
-			Name:    c.String("repo-name"),
-			Link:    c.String("repo-link"),
-			Remote:  c.String("repo-remote-url"),
-			Private: c.Bool("repo-private"),
-		},
-		Curr: frontend.Build{
-			Number:   c.Int64("build-number"),
-			Parent:   c.Int64("parent-build-number"),
-			Created:  c.Int64("build-created"),
-			Started:  c.Int64("build-started"),
-			Finished: c.Int64("build-finished"),
-			Status:   c.String("build-status"),
-			Event:    c.String("build-event"),
-			Link:     c.String("build-link"),
-			Target:   c.String("build-target"),
-			Commit: frontend.Commit{
-				Sha:     c.String("commit-sha"),
-				Ref:     c.String("commit-ref"),
-				Refspec: c.String("commit-refspec"),
-				Branch:  c.String("commit-branch"),
-				Message: c.String("commit-message"),
-				Author: frontend.Author{
-					Name:   c.String("commit-author-name"),
-					Email:  c.String("commit-author-email"),
-					Avatar: c.String("commit-author-avatar"),
-				},
-			},
-		},
-		Prev: frontend.Build{
-			Number:   c.Int64("prev-build-number"),
-			Created:  c.Int64("prev-build-created"),
-			Started:  c.Int64("prev-build-started"),
-			Finished: c.Int64("prev-build-finished"),
-			Status:   c.String("prev-build-status"),
-			Event:    c.String("prev-build-event"),
-			Link:     c.String("prev-build-link"),
-			Commit: frontend.Commit{
-				Sha:     c.String("prev-commit-sha"),
-				Ref:     c.String("prev-commit-ref"),
-				Refspec: c.String("prev-commit-refspec"),
-				Branch:  c.String("prev-commit-branch"),
-				Message: c.String("prev-commit-message"),
-				Author: frontend.Author{
-					Name:   c.String("prev-commit-author-name"),
-					Email:  c.String("prev-commit-author-email"),
-					Avatar: c.String("prev-commit-author-avatar"),
-				},
-			},
-		},
-		Job: frontend.Job{
-			Number: c.Int("job-number"),
-			Matrix: axis,
-		},
-		Sys: frontend.System{
-			Name: c.String("system-name"),
-			Link: c.String("system-link"),
-			Arch: c.String("system-arch"),
-		},
+// return the repository and the current and previous build from the cli
+// context.
+func modelsFromContext(c *cli.Context) (*model.Repo, *model.Build, *model.Build) {
+	repo := &model.Repo{
+		FullName:  c.String("repo-name"),
+		Link:      c.String("repo-link"),
+		Clone:     c.String("repo-remote-url"),
+		IsPrivate: c.Bool("repo-private"),
+		IsTrusted: true,
+	}
+	build := &model.Build{
+		Number:   c.Int64("build-number"),
+		Parent:   c.Int64("parent-build-number"),
+		Created:  c.Int64("build-created"),
+		Started:  c.Int64("build-started"),
+		Finished: c.Int64("build-finished"),
+		Status:   c.String("build-status"),
+		Event:    c.String("build-event"),
+		Link:     c.String("build-link"),
+		Deploy:   c.String("build-target"),
+		Commit:   c.String("commit-sha"),
+		Ref:      c.String("commit-ref"),
+		Refspec:  c.String("commit-refspec"),
+		Branch:   c.String("commit-branch"),
+		Message:  c.String("commit-message"),
+		Author:   c.String("commit-author-name"),
+		Email:    c.String("commit-author-email"),
+		Avatar:   c.String("commit-author-avatar"),
+	}
+	last := &model.Build{
+		Number:   c.Int64("prev-build-number"),
+		Created:  c.Int64("prev-build-created"),
+		Started:  c.Int64("prev-build-started"),
+		Finished: c.Int64("prev-build-finished"),
+		Status:   c.String("prev-build-status"),
+		Event:    c.String("prev-build-event"),
+		Link:     c.String("prev-build-link"),
+		Commit:   c.String("prev-commit-sha"),
+		Ref:      c.String("prev-commit-ref"),
+		Refspec:  c.String("prev-commit-refspec"),
+		Branch:   c.String("prev-commit-branch"),
+		Message:  c.String("prev-commit-message"),
+		Author:   c.String("prev-commit-author-name"),
+		Email:    c.String("prev-commit-author-email"),
+		Avatar:   c.String("prev-commit-author-avatar"),
 	}
+	return repo, build, last
 }
 
 func convertPathForWindows(path string) string {
@@ -254,14 +291,33 @@ func convertPathForWindows(path string) string {
 	return filepath.ToSlash(path)
 }
 
-var defaultLogger = pipeline.LogFunc(func(proc *backend.Step, rc multipart.Reader) error {
-	part, err := rc.NextPart()
-	if err != nil {
-		return err
+// logger returns the logger of the workflow, the lines are prefixed with
+// the workflow name unless it is empty.
+func logger(workflow string) pipeline.LogFunc {
+	return func(proc *backend.Step, rc multipart.Reader) error {
+		part, err := rc.NextPart()
+		if err != nil {
+			return err
+		}
+
+		name := proc.Alias
+		if workflow != "" {
+			name = workflow + "/" + name
+		}
+		logstream := NewLineWriter(name)
+		io.Copy(logstream, part)
+
+		return nil
 	}
+}
 
-	logstream := NewLineWriter(proc.Alias)
-	io.Copy(logstream, part)
+// localFetcher reads included files of the same repository from the
+// working directory.
+type localFetcher struct{}
 
-	return nil
-})
+func (localFetcher) Fetch(_ context.Context, source include.Source) ([]byte, error) {
+	if source.Repo != "" || source.Template != "" {
+		return nil, fmt.Errorf("only files of the same repository can be included by local builds")
+	}
+	return ioutil.ReadFile(filepath.FromSlash(source.Path))
+}
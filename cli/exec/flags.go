@@ -21,6 +21,21 @@ import (
 )
 
 var flags = []cli.Flag{
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_FROM_BUILD"},
+		Name:    "from-build",
+		Usage:   "execute the configs of a build of the server, eg octocat/hello-world#42",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_WORKFLOW"},
+		Name:    "workflow",
+		Usage:   "name or pid of the workflow to execute",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_SECRETS_FILE"},
+		Name:    "secrets-file",
+		Usage:   "file with the secrets of the build in the format NAME=value",
+	},
 	&cli.BoolFlag{
 		EnvVars: []string{"WOODPECKER_LOCAL"},
 		Name:    "local",
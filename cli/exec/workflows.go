@@ -0,0 +1,144 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/queue"
+	"github.com/woodpecker-ci/woodpecker/server/shared"
+)
+
+// selectItems returns the workflows with the name or pid, or all
+// workflows if the name is empty.
+func selectItems(items []*shared.BuildItem, name string) ([]*shared.BuildItem, error) {
+	if name == "" {
+		return items, nil
+	}
+
+	var selected []*shared.BuildItem
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Proc.Name)
+		if item.Proc.Name == name || strconv.Itoa(item.Proc.PID) == name {
+			selected = append(selected, item)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("workflow %q not found, the workflows are %s", name, strings.Join(names, ", "))
+	}
+	return selected, nil
+}
+
+// runWorkflows runs the workflows in the order of their dependencies like
+// the queue of the server. Workflows run in parallel once the workflows
+// they depend on are finished, dependencies which are not run are
+// satisfied. Workflows for another platform are skipped.
+func runWorkflows(items []*shared.BuildItem, platform string, run func(*shared.BuildItem) error) error {
+	var pending []*shared.BuildItem
+	for _, item := range items {
+		switch {
+		case item.Proc.State == model.StatusSkipped:
+			fmt.Fprintf(os.Stderr, "workflow %s skipped\n", item.Proc.Name)
+		case item.Platform != platform:
+			fmt.Fprintf(os.Stderr, "workflow %s skipped, it runs on %s\n", item.Proc.Name, item.Platform)
+		default:
+			pending = append(pending, item)
+		}
+	}
+
+	type result struct {
+		item *shared.BuildItem
+		err  error
+	}
+	results := make(chan result)
+	running := map[*shared.BuildItem]bool{}
+	status := map[string]string{}
+	var failed []string
+
+	finish := func(item *shared.BuildItem, state string) {
+		// a workflow of a matrix fails all workflows of the same name
+		if status[item.Proc.Name] != queue.StatusFailure {
+			status[item.Proc.Name] = state
+		}
+	}
+
+	for len(pending) != 0 || len(running) != 0 {
+		var waiting []*shared.BuildItem
+		for _, item := range pending {
+			if !depsFinished(item, pending, running) {
+				waiting = append(waiting, item)
+				continue
+			}
+
+			task := &queue.Task{
+				DepStatus: map[string]string{},
+				RunOn:     item.RunsOn,
+			}
+			for _, dep := range item.DependsOn {
+				if state, ok := status[dep]; ok {
+					task.DepStatus[dep] = state
+				}
+			}
+			if !task.ShouldRun() {
+				fmt.Fprintf(os.Stderr, "workflow %s skipped\n", item.Proc.Name)
+				finish(item, queue.StatusSkipped)
+				continue
+			}
+
+			running[item] = true
+			go func(item *shared.BuildItem) {
+				results <- result{item, run(item)}
+			}(item)
+		}
+		// skipped workflows may satisfy the dependencies of waiting ones
+		if len(waiting) != len(pending) && len(running) == 0 {
+			pending = waiting
+			continue
+		}
+		pending = waiting
+
+		if len(running) == 0 {
+			var names []string
+			for _, item := range pending {
+				names = append(names, item.Proc.Name)
+			}
+			return fmt.Errorf("workflows %s depend on each other", strings.Join(names, ", "))
+		}
+
+		r := <-results
+		delete(running, r.item)
+		if r.err != nil {
+			fmt.Fprintf(os.Stderr, "workflow %s failed: %s\n", r.item.Proc.Name, r.err)
+			failed = append(failed, r.item.Proc.Name)
+			finish(r.item, queue.StatusFailure)
+		} else {
+			finish(r.item, queue.StatusSuccess)
+		}
+	}
+
+	if len(failed) != 0 {
+		return fmt.Errorf("workflows %s failed", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// depsFinished returns true if none of the workflows the item depends on
+// is pending or running.
+func depsFinished(item *shared.BuildItem, pending []*shared.BuildItem, running map[*shared.BuildItem]bool) bool {
+	for _, dep := range item.DependsOn {
+		for _, other := range pending {
+			if other.Proc.Name == dep {
+				return false
+			}
+		}
+		for other := range running {
+			if other.Proc.Name == dep {
+				return false
+			}
+		}
+	}
+	return true
+}
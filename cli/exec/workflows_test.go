@@ -0,0 +1,102 @@
+package exec
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/shared"
+)
+
+func item(pid int, name string, dependsOn []string, runsOn ...string) *shared.BuildItem {
+	return &shared.BuildItem{
+		Proc:      &model.Proc{PID: pid, Name: name, State: model.StatusPending},
+		Platform:  "linux/amd64",
+		DependsOn: dependsOn,
+		RunsOn:    runsOn,
+	}
+}
+
+func TestSelectItems(t *testing.T) {
+	items := []*shared.BuildItem{
+		item(1, "test", nil),
+		item(2, "test", nil),
+		item(3, "deploy", nil),
+	}
+
+	selected, err := selectItems(items, "test")
+	if err != nil || len(selected) != 2 {
+		t.Errorf("Wanted both test workflows, got %v, %v", selected, err)
+	}
+	selected, err = selectItems(items, "3")
+	if err != nil || len(selected) != 1 || selected[0].Proc.Name != "deploy" {
+		t.Errorf("Wanted workflow 3, got %v, %v", selected, err)
+	}
+	if _, err := selectItems(items, "lint"); err == nil {
+		t.Error("Wanted error selecting an unknown workflow")
+	}
+}
+
+func TestRunWorkflows(t *testing.T) {
+	skipped := item(6, "skipped", nil)
+	skipped.Proc.State = model.StatusSkipped
+	arm := item(7, "arm", nil)
+	arm.Platform = "linux/arm64"
+
+	items := []*shared.BuildItem{
+		item(1, "build", nil),
+		item(2, "test", []string{"build"}),
+		item(3, "deploy", []string{"test"}),
+		item(4, "notify", []string{"deploy"}, "failure"),
+		item(5, "cleanup", []string{"test"}, "success", "failure"),
+		skipped,
+		arm,
+		item(8, "lint", []string{"skipped"}),
+	}
+
+	var mu sync.Mutex
+	var order []string
+	err := runWorkflows(items, "linux/amd64", func(item *shared.BuildItem) error {
+		mu.Lock()
+		order = append(order, item.Proc.Name)
+		mu.Unlock()
+		if item.Proc.Name == "test" {
+			return errors.New("exit code 1")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "workflows test failed" {
+		t.Errorf("Wanted test to fail, got %v", err)
+	}
+
+	ran := map[string]int{}
+	for i, name := range order {
+		ran[name] = i + 1
+	}
+	for _, name := range []string{"build", "test", "cleanup", "notify", "lint"} {
+		if ran[name] == 0 {
+			t.Errorf("Wanted workflow %s to run, got %v", name, order)
+		}
+	}
+	// deploy is skipped as test failed, notify runs as deploy did not succeed
+	for _, name := range []string{"deploy", "skipped", "arm"} {
+		if ran[name] != 0 {
+			t.Errorf("Wanted workflow %s to be skipped, got %v", name, order)
+		}
+	}
+	if ran["build"] > ran["test"] || ran["test"] > ran["cleanup"] {
+		t.Errorf("Wanted workflows in dependency order, got %v", order)
+	}
+}
+
+func TestRunWorkflowsCycle(t *testing.T) {
+	items := []*shared.BuildItem{
+		item(1, "a", []string{"b"}),
+		item(2, "b", []string{"a"}),
+	}
+	err := runWorkflows(items, "linux/amd64", func(*shared.BuildItem) error { return nil })
+	if err == nil || err.Error() != "workflows a, b depend on each other" {
+		t.Errorf("Wanted cycle error, got %v", err)
+	}
+}
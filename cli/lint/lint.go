@@ -2,13 +2,17 @@ package lint
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/urfave/cli/v2"
+	"github.com/xeipuuv/gojsonschema"
 
 	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/starlark"
 	"github.com/woodpecker-ci/woodpecker/pipeline/schema"
 )
 
@@ -16,7 +20,7 @@ import (
 var Command = &cli.Command{
 	Name:      "lint",
 	Usage:     "lint a pipeline configuration file",
-	ArgsUsage: "[path/to/.woodpecker.yml]",
+	ArgsUsage: "[path/to/.woodpecker.yml|.star]",
 	Action:    lint,
 	Flags:     common.GlobalFlags,
 }
@@ -41,7 +45,7 @@ func lint(c *cli.Context) error {
 		}
 
 		// check if it is a regular file (not dir)
-		if info.Mode().IsRegular() && strings.HasSuffix(info.Name(), ".yml") {
+		if info.Mode().IsRegular() && (strings.HasSuffix(info.Name(), ".yml") || starlark.IsConfig(info.Name())) {
 			fmt.Println("#", info.Name())
 			lintFile(path)
 			fmt.Println("")
@@ -53,7 +57,15 @@ func lint(c *cli.Context) error {
 }
 
 func lintFile(file string) error {
-	err, configErrors := schema.Lint(file)
+	var (
+		err          error
+		configErrors []gojsonschema.ResultError
+	)
+	if starlark.IsConfig(file) {
+		err, configErrors = lintStarlark(file)
+	} else {
+		err, configErrors = schema.Lint(file)
+	}
 	if err != nil {
 		fmt.Println("❌ Config is invalid")
 		for _, configError := range configErrors {
@@ -65,3 +77,19 @@ func lintFile(file string) error {
 	fmt.Println("✅ Config is valid")
 	return nil
 }
+
+// lintStarlark evaluates the starlark config for a push to the default
+// branch and validates the generated config.
+func lintStarlark(file string) (error, []gojsonschema.ResultError) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err, nil
+	}
+	data, err = starlark.Eval(file, data, frontend.Metadata{
+		Curr: frontend.Build{Event: "push"},
+	})
+	if err != nil {
+		return err, nil
+	}
+	return schema.LintBytes(data)
+}
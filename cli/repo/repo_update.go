@@ -41,6 +41,22 @@ var repoUpdateCmd = &cli.Command{
 			Name:  "build-counter",
 			Usage: "repository starting build number",
 		},
+		&cli.IntFlag{
+			Name:  "approval-count",
+			Usage: "number of approvals a gated build requires",
+		},
+		&cli.StringSliceFlag{
+			Name:  "approval-org",
+			Usage: "organization allowed to approve gated builds",
+		},
+		&cli.BoolFlag{
+			Name:  "approve-members",
+			Usage: "builds of organization members are approved automatically",
+		},
+		&cli.BoolFlag{
+			Name:  "gate-forks",
+			Usage: "only pull requests of forks are gated",
+		},
 		&cli.BoolFlag{
 			Name:  "unsafe",
 			Usage: "validate updating the build-counter is unsafe",
@@ -68,6 +84,11 @@ func repoUpdate(c *cli.Context) error {
 		gated        = c.Bool("gated")
 		buildCounter = c.Int("build-counter")
 		unsafe       = c.Bool("unsafe")
+
+		approvalCount  = c.Int("approval-count")
+		approvalOrgs   = c.StringSlice("approval-org")
+		approveMembers = c.Bool("approve-members")
+		gateForks      = c.Bool("gate-forks")
 	)
 
 	patch := new(woodpecker.RepoPatch)
@@ -90,6 +111,18 @@ func repoUpdate(c *cli.Context) error {
 			patch.Visibility = &visibility
 		}
 	}
+	if c.IsSet("approval-count") {
+		patch.ApprovalCount = &approvalCount
+	}
+	if c.IsSet("approval-org") {
+		patch.ApprovalOrgs = &approvalOrgs
+	}
+	if c.IsSet("approve-members") {
+		patch.ApproveMembers = &approveMembers
+	}
+	if c.IsSet("gate-forks") {
+		patch.GateForks = &gateForks
+	}
 	if c.IsSet("build-counter") && !unsafe {
 		fmt.Printf("Setting the build counter is an unsafe operation that could put your repository in an inconsistent state. Please use --unsafe to proceed")
 	}
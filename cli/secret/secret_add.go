@@ -37,6 +37,10 @@ var secretCreateCmd = &cli.Command{
 			Name:  "image",
 			Usage: "secret limited to these images",
 		},
+		&cli.StringSliceFlag{
+			Name:  "environment",
+			Usage: "secret limited to deployments to these environments",
+		},
 	),
 }
 
@@ -58,6 +62,8 @@ func secretCreate(c *cli.Context) error {
 		Value:  c.String("value"),
 		Images: c.StringSlice("image"),
 		Events: c.StringSlice("event"),
+
+		Environments: c.StringSlice("environment"),
 	}
 	if len(secret.Events) == 0 {
 		secret.Events = defaultSecretEvents
@@ -63,6 +63,9 @@ Images: {{ list .Images }}
 {{- else }}
 Images: <any>
 {{- end }}
+{{- if .Environments }}
+Environments: {{ list .Environments }}
+{{- end }}
 `
 
 var secretFuncMap = template.FuncMap{
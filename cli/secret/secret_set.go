@@ -37,6 +37,10 @@ var secretUpdateCmd = &cli.Command{
 			Name:  "image",
 			Usage: "secret limited to these images",
 		},
+		&cli.StringSliceFlag{
+			Name:  "environment",
+			Usage: "secret limited to deployments to these environments",
+		},
 	),
 }
 
@@ -58,6 +62,8 @@ func secretUpdate(c *cli.Context) error {
 		Value:  c.String("value"),
 		Images: c.StringSlice("image"),
 		Events: c.StringSlice("event"),
+
+		Environments: c.StringSlice("environment"),
 	}
 	if strings.HasPrefix(secret.Value, "@") {
 		path := strings.TrimPrefix(secret.Value, "@")
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+// Command exports the webhook command.
+var Command = &cli.Command{
+	Name:  "webhook",
+	Usage: "manage outbound webhooks",
+	Flags: common.GlobalFlags,
+	Subcommands: []*cli.Command{
+		webhookCreateCmd,
+		webhookDeleteCmd,
+		webhookListCmd,
+		webhookTestCmd,
+		webhookDeliveriesCmd,
+	},
+}
+
+var scopeFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "repository",
+		Usage: "repository name (e.g. octocat/hello-world)",
+	},
+	&cli.BoolFlag{
+		Name:  "global",
+		Usage: "manage the global webhooks of all repositories",
+	},
+}
+
+// parseScope returns the repository owner and name of the webhooks,
+// both are empty for the global webhooks.
+func parseScope(c *cli.Context) (string, string, error) {
+	if c.Bool("global") {
+		return "", "", nil
+	}
+	reponame := c.String("repository")
+	if reponame == "" {
+		reponame = c.Args().First()
+	}
+	return internal.ParseRepo(reponame)
+}
@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"os"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+	"github.com/woodpecker-ci/woodpecker/woodpecker-go/woodpecker"
+)
+
+var webhookCreateCmd = &cli.Command{
+	Name:      "add",
+	Usage:     "adds a webhook",
+	ArgsUsage: "[repo/name]",
+	Action:    webhookCreate,
+	Flags: append(append(common.GlobalFlags, scopeFlags...),
+		&cli.StringFlag{
+			Name:  "url",
+			Usage: "webhook url",
+		},
+		&cli.StringFlag{
+			Name:  "secret",
+			Usage: "secret used to sign the payload",
+		},
+		&cli.StringSliceFlag{
+			Name:  "event",
+			Usage: "webhook limited to these events (enqueued, started, finished, cancelled, proc_finished)",
+		},
+		common.FormatFlag(tmplWebhookList),
+	),
+}
+
+func webhookCreate(c *cli.Context) error {
+	owner, name, err := parseScope(c)
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	webhook, err := client.WebhookCreate(owner, name, &woodpecker.Webhook{
+		URL:    c.String("url"),
+		Secret: c.String("secret"),
+		Events: c.StringSlice("event"),
+	})
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("_").Funcs(webhookFuncMap).Parse(c.String("format") + "\n")
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(os.Stdout, webhook)
+}
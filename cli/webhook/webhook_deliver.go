@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"os"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+var webhookTestCmd = &cli.Command{
+	Name:      "test",
+	Usage:     "send a test delivery to a webhook",
+	ArgsUsage: "[repo/name]",
+	Action:    webhookTest,
+	Flags: append(append(common.GlobalFlags, scopeFlags...),
+		&cli.Int64Flag{
+			Name:  "id",
+			Usage: "webhook id",
+		},
+		common.FormatFlag(tmplDeliveryList),
+	),
+}
+
+func webhookTest(c *cli.Context) error {
+	owner, name, err := parseScope(c)
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	delivery, err := client.WebhookTest(owner, name, c.Int64("id"))
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(os.Stdout, delivery)
+}
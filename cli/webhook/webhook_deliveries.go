@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"os"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+var webhookDeliveriesCmd = &cli.Command{
+	Name:      "deliveries",
+	Usage:     "list the recent deliveries of a webhook",
+	ArgsUsage: "[repo/name]",
+	Action:    webhookDeliveries,
+	Flags: append(append(common.GlobalFlags, scopeFlags...),
+		&cli.Int64Flag{
+			Name:  "id",
+			Usage: "webhook id",
+		},
+		common.FormatFlag(tmplDeliveryList),
+	),
+}
+
+func webhookDeliveries(c *cli.Context) error {
+	owner, name, err := parseScope(c)
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	list, err := client.WebhookDeliveries(owner, name, c.Int64("id"))
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+	if err != nil {
+		return err
+	}
+	for _, delivery := range list {
+		tmpl.Execute(os.Stdout, delivery)
+	}
+	return nil
+}
+
+// template for webhook delivery list items
+var tmplDeliveryList = "\x1b[33m{{ .ID }}: {{ .Event }} \x1b[0m" + `
+Success: {{ .Success }}
+Attempts: {{ .Attempts }}
+Status: {{ .Status }}
+{{- if .Error }}
+Error: {{ .Error }}
+{{- end }}
+`
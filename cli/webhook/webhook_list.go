@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+var webhookListCmd = &cli.Command{
+	Name:      "ls",
+	Usage:     "list webhooks",
+	ArgsUsage: "[repo/name]",
+	Action:    webhookList,
+	Flags: append(append(common.GlobalFlags, scopeFlags...),
+		common.FormatFlag(tmplWebhookList),
+	),
+}
+
+func webhookList(c *cli.Context) error {
+	owner, name, err := parseScope(c)
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	list, err := client.WebhookList(owner, name)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New("_").Funcs(webhookFuncMap).Parse(c.String("format") + "\n")
+	if err != nil {
+		return err
+	}
+	for _, webhook := range list {
+		tmpl.Execute(os.Stdout, webhook)
+	}
+	return nil
+}
+
+// template for webhook list items
+var tmplWebhookList = "\x1b[33m{{ .ID }}: {{ .URL }} \x1b[0m" + `
+Active: {{ .Active }}
+{{- if .Events }}
+Events: {{ list .Events }}
+{{- else }}
+Events: <any>
+{{- end }}
+`
+
+var webhookFuncMap = template.FuncMap{
+	"list": func(s []string) string {
+		return strings.Join(s, ", ")
+	},
+}
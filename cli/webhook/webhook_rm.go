@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/cli/common"
+	"github.com/woodpecker-ci/woodpecker/cli/internal"
+)
+
+var webhookDeleteCmd = &cli.Command{
+	Name:      "rm",
+	Usage:     "remove a webhook",
+	ArgsUsage: "[repo/name]",
+	Action:    webhookDelete,
+	Flags: append(append(common.GlobalFlags, scopeFlags...),
+		&cli.Int64Flag{
+			Name:  "id",
+			Usage: "webhook id",
+		},
+	),
+}
+
+func webhookDelete(c *cli.Context) error {
+	owner, name, err := parseScope(c)
+	if err != nil {
+		return err
+	}
+	client, err := internal.NewClient(c)
+	if err != nil {
+		return err
+	}
+	return client.WebhookDelete(owner, name, c.Int64("id"))
+}
@@ -31,7 +31,7 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	"github.com/woodpecker-ci/woodpecker/agent"
-	"github.com/woodpecker-ci/woodpecker/pipeline/backend/docker"
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
 	"github.com/woodpecker-ci/woodpecker/pipeline/rpc"
 )
 
@@ -74,7 +74,17 @@ func loop(c *cli.Context) error {
 		zerolog.SetGlobalLevel(lvl)
 	}
 
-	counter.Polling = c.Int("max-procs")
+	pool, err := newPool(c)
+	if err != nil {
+		return err
+	}
+
+	pipelineCache, err := newCache(c)
+	if err != nil {
+		return err
+	}
+
+	counter.Polling = pool.Capacity()
 	counter.Running = 0
 
 	if c.Bool("healthcheck") {
@@ -126,26 +136,40 @@ func loop(c *cli.Context) error {
 		sigterm.Set()
 	})
 
+	// check the health of the hosts before the first pipeline is placed
+	pool.Check(ctx)
+	go pool.Monitor(ctx, c.Duration("docker-healthcheck-interval"))
+
+	// remove resources left behind by pipelines of crashed agents.
+	for _, engine := range pool.Engines() {
+		engine, ok := engine.(backend.Reaper)
+		if !ok {
+			continue
+		}
+		reaper := agent.NewReaper(client, engine, counter)
+		if interval := c.Duration("reaper-interval"); interval > 0 {
+			go reaper.Run(ctx, interval)
+		} else if err := reaper.Reap(ctx); err != nil {
+			log.Error().Err(err).Msg("cannot reap orphaned pipeline resources")
+		}
+	}
+
 	var wg sync.WaitGroup
-	parallel := c.Int("max-procs")
+	parallel := pool.Capacity()
 	wg.Add(parallel)
 
 	for i := 0; i < parallel; i++ {
 		go func() {
 			defer wg.Done()
+
+			// the runner reserves the docker engine of the least loaded
+			// host for every pipeline it receives
+			r := agent.NewRunner(client, filter, hostname, counter, pool, pipelineCache, c.Bool("clone-reference"))
 			for {
 				if sigterm.IsSet() {
 					return
 				}
 
-				// new docker engine
-				engine, err := docker.NewEnv()
-				if err != nil {
-					log.Error().Err(err).Msg("cannot create docker client")
-					return
-				}
-
-				r := agent.NewRunner(client, filter, hostname, counter, &engine)
 				if err := r.Run(ctx); err != nil {
 					log.Error().Err(err).Msg("pipeline done with error")
 					return
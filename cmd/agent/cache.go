@@ -0,0 +1,43 @@
+package main
+
+import (
+	"github.com/docker/go-units"
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/cache"
+)
+
+// newCache returns the pipeline cache, nil if neither a cache
+// directory nor a bucket is configured.
+func newCache(c *cli.Context) (*cache.Cache, error) {
+	maxSize, err := units.FromHumanSize(c.String("cache-max-size"))
+	if err != nil {
+		return nil, err
+	}
+	maxEntrySize, err := units.FromHumanSize(c.String("cache-max-entry-size"))
+	if err != nil {
+		return nil, err
+	}
+
+	var store cache.Store
+	switch {
+	case c.String("cache-s3-bucket") != "":
+		store, err = cache.NewS3(cache.S3Options{
+			Endpoint:  c.String("cache-s3-endpoint"),
+			Bucket:    c.String("cache-s3-bucket"),
+			Region:    c.String("cache-s3-region"),
+			AccessKey: c.String("cache-s3-access-key"),
+			SecretKey: c.String("cache-s3-secret-key"),
+			Prefix:    c.String("cache-s3-prefix"),
+			PathStyle: c.Bool("cache-s3-path-style"),
+		}, maxSize)
+	case c.String("cache-dir") != "":
+		store, err = cache.NewLocal(c.String("cache-dir"), maxSize)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(store, maxEntrySize), nil
+}
@@ -79,9 +79,89 @@ var flags = []cli.Flag{
 	&cli.IntFlag{
 		EnvVars: []string{"WOODPECKER_MAX_PROCS"},
 		Name:    "max-procs",
-		Usage:   "agent parallel builds",
+		Usage:   "agent parallel builds on the local docker host, 0 to only use the additional docker hosts",
 		Value:   1,
 	},
+	&cli.DurationFlag{
+		EnvVars: []string{"WOODPECKER_REAPER_INTERVAL"},
+		Name:    "reaper-interval",
+		Usage:   "interval to remove resources of pipelines that are no longer active, 0 to only remove them on startup",
+		Value:   time.Minute * 10,
+	},
+	&cli.BoolFlag{
+		EnvVars: []string{"WOODPECKER_CLONE_REFERENCE"},
+		Name:    "clone-reference",
+		Usage:   "keep a reference repository of each repo in a volume to speed up clones",
+	},
+	&cli.StringSliceFlag{
+		EnvVars: []string{"WOODPECKER_DOCKER_HOSTS"},
+		Name:    "docker-hosts",
+		Usage:   "additional docker hosts in the format <host>[=<capacity>], e.g. tcp://build1:2376=4",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_DOCKER_CERT_PATH"},
+		Name:    "docker-cert-path",
+		Usage:   "directory with the tls certificates of the additional docker hosts, optionally in a sub directory per hostname",
+	},
+	&cli.DurationFlag{
+		EnvVars: []string{"WOODPECKER_DOCKER_HEALTHCHECK_INTERVAL"},
+		Name:    "docker-healthcheck-interval",
+		Usage:   "interval to check the health of the docker hosts",
+		Value:   time.Second * 30,
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CACHE_DIR"},
+		Name:    "cache-dir",
+		Usage:   "directory to store the pipeline cache in",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CACHE_MAX_SIZE"},
+		Name:    "cache-max-size",
+		Usage:   "maximum size of the pipeline cache, the least recently used entries are evicted",
+		Value:   "10GB",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CACHE_MAX_ENTRY_SIZE"},
+		Name:    "cache-max-entry-size",
+		Usage:   "maximum size of a single pipeline cache entry",
+		Value:   "1GB",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CACHE_S3_ENDPOINT"},
+		Name:    "cache-s3-endpoint",
+		Usage:   "endpoint of the s3 compatible object storage to store the pipeline cache in, defaults to aws",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CACHE_S3_BUCKET"},
+		Name:    "cache-s3-bucket",
+		Usage:   "bucket to store the pipeline cache in, enables the s3 cache",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CACHE_S3_REGION"},
+		Name:    "cache-s3-region",
+		Usage:   "region of the pipeline cache bucket",
+		Value:   "us-east-1",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CACHE_S3_ACCESS_KEY"},
+		Name:    "cache-s3-access-key",
+		Usage:   "access key of the pipeline cache bucket",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CACHE_S3_SECRET_KEY"},
+		Name:    "cache-s3-secret-key",
+		Usage:   "secret key of the pipeline cache bucket",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CACHE_S3_PREFIX"},
+		Name:    "cache-s3-prefix",
+		Usage:   "prefix of the pipeline cache object names",
+	},
+	&cli.BoolFlag{
+		EnvVars: []string{"WOODPECKER_CACHE_S3_PATH_STYLE"},
+		Name:    "cache-s3-path-style",
+		Usage:   "use path style bucket addressing, required by most self-hosted object storages",
+	},
 	&cli.BoolFlag{
 		EnvVars: []string{"WOODPECKER_HEALTHCHECK"},
 		Name:    "healthcheck",
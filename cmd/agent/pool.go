@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend/docker"
+)
+
+// newPool returns the pool of the local docker host and all additional
+// docker hosts.
+func newPool(c *cli.Context) (*docker.Pool, error) {
+	pool := docker.NewPool()
+
+	if procs := c.Int("max-procs"); procs > 0 {
+		cli, err := docker.NewEnvClient()
+		if err != nil {
+			return nil, err
+		}
+		pool.Add("local", cli, procs)
+	}
+
+	for _, spec := range c.StringSlice("docker-hosts") {
+		host, capacity, err := parseDockerHost(spec)
+		if err != nil {
+			return nil, err
+		}
+		cli, err := docker.NewClient(host, c.String("docker-cert-path"))
+		if err != nil {
+			return nil, fmt.Errorf("cannot create docker client for %s: %w", host, err)
+		}
+		pool.Add(host, cli, capacity)
+	}
+
+	if pool.Capacity() == 0 {
+		return nil, errors.New("no docker host configured")
+	}
+	return pool, nil
+}
+
+// parseDockerHost parses a docker host in the format <host>[=<capacity>].
+func parseDockerHost(spec string) (string, int, error) {
+	host, capacity := spec, 1
+	if i := strings.LastIndex(spec, "="); i != -1 {
+		host = spec[:i]
+		n, err := strconv.Atoi(spec[i+1:])
+		if err != nil || n < 1 {
+			return "", 0, fmt.Errorf("invalid capacity of docker host %q", spec)
+		}
+		capacity = n
+	}
+	if host == "" {
+		return "", 0, fmt.Errorf("invalid docker host %q", spec)
+	}
+	return host, capacity, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestParseDockerHost(t *testing.T) {
+	testdata := []struct {
+		spec     string
+		host     string
+		capacity int
+		err      bool
+	}{
+		{spec: "tcp://build1:2376", host: "tcp://build1:2376", capacity: 1},
+		{spec: "tcp://build1:2376=4", host: "tcp://build1:2376", capacity: 4},
+		{spec: "unix:///var/run/docker.sock=2", host: "unix:///var/run/docker.sock", capacity: 2},
+		{spec: "tcp://build1:2376=0", err: true},
+		{spec: "tcp://build1:2376=x", err: true},
+		{spec: "=2", err: true},
+	}
+	for _, test := range testdata {
+		host, capacity, err := parseDockerHost(test.spec)
+		if test.err {
+			if err == nil {
+				t.Errorf("Want error parsing %q", test.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error parsing %q: %s", test.spec, err)
+		}
+		if host != test.host || capacity != test.capacity {
+			t.Errorf("Want %q parsed to %s with capacity %d, got %s with %d", test.spec, test.host, test.capacity, host, capacity)
+		}
+	}
+}
@@ -22,9 +22,11 @@ import (
 	zlog "github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v2"
 
+	"github.com/woodpecker-ci/woodpecker/cli/audit"
 	"github.com/woodpecker-ci/woodpecker/cli/build"
 	"github.com/woodpecker-ci/woodpecker/cli/common"
 	"github.com/woodpecker-ci/woodpecker/cli/deploy"
+	"github.com/woodpecker-ci/woodpecker/cli/environment"
 	"github.com/woodpecker-ci/woodpecker/cli/exec"
 	"github.com/woodpecker-ci/woodpecker/cli/info"
 	"github.com/woodpecker-ci/woodpecker/cli/lint"
@@ -34,6 +36,7 @@ import (
 	"github.com/woodpecker-ci/woodpecker/cli/repo"
 	"github.com/woodpecker-ci/woodpecker/cli/secret"
 	"github.com/woodpecker-ci/woodpecker/cli/user"
+	"github.com/woodpecker-ci/woodpecker/cli/webhook"
 	"github.com/woodpecker-ci/woodpecker/version"
 )
 
@@ -48,6 +51,7 @@ func main() {
 		build.Command,
 		log.Command,
 		deploy.Command,
+		environment.Command,
 		exec.Command,
 		info.Command,
 		registry.Command,
@@ -56,6 +60,8 @@ func main() {
 		user.Command,
 		lint.Command,
 		loglevel.Command,
+		audit.Command,
+		webhook.Command,
 	}
 
 	zlog.Logger = zlog.Output(
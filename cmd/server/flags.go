@@ -143,6 +143,28 @@ var flags = []cli.Flag{
 		EnvVars: []string{"WOODPECKER_NETWORK"},
 		Name:    "network",
 	},
+	&cli.IntFlag{
+		EnvVars: []string{"WOODPECKER_MATRIX_LIMIT_TAGS"},
+		Name:    "matrix-limit-tags",
+		Usage:   "maximum number of tags of a build matrix, 0 for unlimited",
+		Value:   10,
+	},
+	&cli.IntFlag{
+		EnvVars: []string{"WOODPECKER_MATRIX_LIMIT_AXIS"},
+		Name:    "matrix-limit-axis",
+		Usage:   "maximum number of axis of a build matrix, 0 for unlimited",
+		Value:   25,
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_DEFAULT_CLONE_IMAGE"},
+		Name:    "default-clone-image",
+		Usage:   "image of the default clone step",
+	},
+	&cli.StringSliceFlag{
+		EnvVars: []string{"WOODPECKER_DEFAULT_CLONE_SETTINGS"},
+		Name:    "default-clone-settings",
+		Usage:   "settings of the default clone step in the format key=value",
+	},
 	&cli.StringFlag{
 		EnvVars: []string{"WOODPECKER_AGENT_SECRET"},
 		Name:    "agent-secret",
@@ -163,6 +185,45 @@ var flags = []cli.Flag{
 		Name:    "gating-service",
 		Usage:   "gated build endpoint",
 	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CONFIG_SERVICE_ENDPOINT"},
+		Name:    "config-service-endpoint",
+		Usage:   "config extension endpoint generating the pipeline configs",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_CONFIG_SERVICE_SECRET"},
+		Name:    "config-service-secret",
+		Usage:   "secret signing the requests to the config extension",
+	},
+	&cli.DurationFlag{
+		EnvVars: []string{"WOODPECKER_CONFIG_SERVICE_TIMEOUT"},
+		Name:    "config-service-timeout",
+		Usage:   "timeout of the requests to the config extension, the fetched configs are used after it",
+		Value:   time.Second * 10,
+	},
+	&cli.DurationFlag{
+		EnvVars: []string{"WOODPECKER_WEBHOOK_TIMEOUT"},
+		Name:    "webhook-timeout",
+		Usage:   "timeout of a single outbound webhook delivery attempt",
+		Value:   time.Second * 10,
+	},
+	&cli.IntFlag{
+		EnvVars: []string{"WOODPECKER_WEBHOOK_RETRIES"},
+		Name:    "webhook-retries",
+		Usage:   "number of retries of failed outbound webhook deliveries",
+		Value:   3,
+	},
+	&cli.DurationFlag{
+		EnvVars: []string{"WOODPECKER_WEBHOOK_BACKOFF"},
+		Name:    "webhook-backoff",
+		Usage:   "initial backoff between outbound webhook delivery attempts, doubled after each retry",
+		Value:   time.Second * 5,
+	},
+	&cli.StringSliceFlag{
+		EnvVars: []string{"WOODPECKER_WEBHOOK_ALLOWED_NETWORKS"},
+		Name:    "webhook-allowed-networks",
+		Usage:   "loopback, link-local or private networks (CIDR) outbound webhooks may be delivered to",
+	},
 	&cli.StringFlag{
 		EnvVars: []string{"WOODPECKER_DATABASE_DRIVER"},
 		Name:    "driver",
@@ -312,6 +373,41 @@ var flags = []cli.Flag{
 		Name:    "gogs-skip-verify",
 		Usage:   "gogs skip ssl verification",
 	},
+	&cli.BoolFlag{
+		EnvVars: []string{"WOODPECKER_GENERIC"},
+		Name:    "generic",
+		Usage:   "generic git driver is enabled",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_GENERIC_URL"},
+		Name:    "generic-server",
+		Usage:   "base url repositories are cloned from",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_GENERIC_USERS_FILE"},
+		Name:    "generic-users-file",
+		Usage:   "htpasswd file with the local user accounts",
+	},
+	&cli.StringSliceFlag{
+		EnvVars: []string{"WOODPECKER_GENERIC_REPOS"},
+		Name:    "generic-repos",
+		Usage:   "list of repositories in owner/name format",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_GENERIC_GIT_USERNAME"},
+		Name:    "generic-git-username",
+		Usage:   "generic git service account username",
+	},
+	&cli.StringFlag{
+		EnvVars: []string{"WOODPECKER_GENERIC_GIT_PASSWORD"},
+		Name:    "generic-git-password",
+		Usage:   "generic git service account password",
+	},
+	&cli.BoolFlag{
+		EnvVars: []string{"WOODPECKER_GENERIC_SKIP_VERIFY"},
+		Name:    "generic-skip-verify",
+		Usage:   "generic git skip ssl verification",
+	},
 	&cli.BoolFlag{
 		EnvVars: []string{"WOODPECKER_GITEA"},
 		Name:    "gitea",
@@ -40,6 +40,7 @@ import (
 	"github.com/woodpecker-ci/woodpecker/server"
 	woodpeckerGrpcServer "github.com/woodpecker-ci/woodpecker/server/grpc"
 	"github.com/woodpecker-ci/woodpecker/server/logging"
+	"github.com/woodpecker-ci/woodpecker/server/plugins/config"
 	"github.com/woodpecker-ci/woodpecker/server/plugins/sender"
 	"github.com/woodpecker-ci/woodpecker/server/pubsub"
 	"github.com/woodpecker-ci/woodpecker/server/remote"
@@ -47,6 +48,7 @@ import (
 	"github.com/woodpecker-ci/woodpecker/server/router/middleware"
 	"github.com/woodpecker-ci/woodpecker/server/router/middleware/logger"
 	"github.com/woodpecker-ci/woodpecker/server/store"
+	"github.com/woodpecker-ci/woodpecker/server/webhook"
 )
 
 func loop(c *cli.Context) error {
@@ -99,6 +101,12 @@ func loop(c *cli.Context) error {
 		)
 	}
 
+	if c.String("config-service-endpoint") != "" && c.String("config-service-secret") == "" {
+		log.Fatal().Msg(
+			"WOODPECKER_CONFIG_SERVICE_SECRET must be set to sign the requests to the config service",
+		)
+	}
+
 	remote_, err := SetupRemote(c)
 	if err != nil {
 		log.Fatal().Err(err).Msg("")
@@ -185,6 +193,15 @@ func loop(c *cli.Context) error {
 		return nil
 	})
 
+	// deliver build events to outbound webhooks
+	g.Go(func() error {
+		return server.Config.Services.Webhooks.Start(
+			context.Background(),
+			server.Config.Services.Pubsub,
+			"topic/events",
+		)
+	})
+
 	setupMetrics(&g, store_)
 
 	// start the server with tls enabled
@@ -264,11 +281,30 @@ func setupEvilGlobals(c *cli.Context, v store.Store, r remote.Remote) {
 	server.Config.Services.Secrets = setupSecretService(c, v)
 	server.Config.Services.Senders = sender.New(v, v)
 	server.Config.Services.Environ = setupEnvironService(c, v)
+	webhookNetworks, err := webhook.ParseNetworks(c.StringSlice("webhook-allowed-networks"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid webhook allowed networks")
+	}
+	server.Config.Services.Webhooks = webhook.New(
+		v,
+		c.Duration("webhook-timeout"),
+		c.Int("webhook-retries"),
+		c.Duration("webhook-backoff"),
+		webhookNetworks,
+	)
 
 	if endpoint := c.String("gating-service"); endpoint != "" {
 		server.Config.Services.Senders = sender.NewRemote(endpoint)
 	}
 
+	if endpoint := c.String("config-service-endpoint"); endpoint != "" {
+		server.Config.Services.ConfigExtension = config.NewHTTP(
+			endpoint,
+			c.String("config-service-secret"),
+			c.Duration("config-service-timeout"),
+		)
+	}
+
 	// limits
 	server.Config.Pipeline.Limits.MemSwapLimit = c.Int64("limit-mem-swap")
 	server.Config.Pipeline.Limits.MemLimit = c.Int64("limit-mem")
@@ -288,6 +324,10 @@ func setupEvilGlobals(c *cli.Context, v store.Store, r remote.Remote) {
 	server.Config.Pipeline.Networks = c.StringSlice("network")
 	server.Config.Pipeline.Volumes = c.StringSlice("volume")
 	server.Config.Pipeline.Privileged = c.StringSlice("escalate")
+	server.Config.Pipeline.DefaultCloneImage = c.String("default-clone-image")
+	server.Config.Pipeline.DefaultCloneSettings = setupCloneSettings(c)
+	server.Config.Pipeline.MatrixLimits.Tags = c.Int("matrix-limit-tags")
+	server.Config.Pipeline.MatrixLimits.Axis = c.Int("matrix-limit-axis")
 
 	// prometheus
 	server.Config.Prometheus.AuthToken = c.String("prometheus-auth-token")
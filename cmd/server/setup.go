@@ -17,6 +17,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -36,6 +37,7 @@ import (
 	"github.com/woodpecker-ci/woodpecker/server/remote/bitbucket"
 	"github.com/woodpecker-ci/woodpecker/server/remote/bitbucketserver"
 	"github.com/woodpecker-ci/woodpecker/server/remote/coding"
+	"github.com/woodpecker-ci/woodpecker/server/remote/generic"
 	"github.com/woodpecker-ci/woodpecker/server/remote/gitea"
 	"github.com/woodpecker-ci/woodpecker/server/remote/github"
 	"github.com/woodpecker-ci/woodpecker/server/remote/gitlab"
@@ -176,6 +178,21 @@ func setupEnvironService(c *cli.Context, s store.Store) model.EnvironService {
 	return environments.Filesystem(c.StringSlice("environment"))
 }
 
+// setupCloneSettings parses the key=value settings of the default
+// clone step.
+func setupCloneSettings(c *cli.Context) map[string]interface{} {
+	settings := map[string]interface{}{}
+	for _, s := range c.StringSlice("default-clone-settings") {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 {
+			log.Warn().Msgf("invalid default clone setting %q, expected key=value", s)
+			continue
+		}
+		settings[parts[0]] = parts[1]
+	}
+	return settings
+}
+
 // SetupRemote helper function to setup the remote from the CLI arguments.
 func SetupRemote(c *cli.Context) (remote.Remote, error) {
 	switch {
@@ -193,6 +210,8 @@ func SetupRemote(c *cli.Context) (remote.Remote, error) {
 		return setupGitea(c)
 	case c.Bool("coding"):
 		return setupCoding(c)
+	case c.Bool("generic"):
+		return setupGeneric(c)
 	default:
 		return nil, fmt.Errorf("version control system not configured")
 	}
@@ -221,6 +240,20 @@ func setupGogs(c *cli.Context) (remote.Remote, error) {
 	return gogs.New(opts)
 }
 
+// helper function to setup the generic remote from the CLI arguments.
+func setupGeneric(c *cli.Context) (remote.Remote, error) {
+	opts := generic.Opts{
+		URL:        c.String("generic-server"),
+		UsersFile:  c.String("generic-users-file"),
+		Repos:      c.StringSlice("generic-repos"),
+		Username:   c.String("generic-git-username"),
+		Password:   c.String("generic-git-password"),
+		SkipVerify: c.Bool("generic-skip-verify"),
+	}
+	log.Trace().Msgf("Remote (generic) opts: %#v", opts)
+	return generic.New(opts)
+}
+
 // helper function to setup the Gitea remote from the CLI arguments.
 func setupGitea(c *cli.Context) (remote.Remote, error) {
 	opts := gitea.Opts{
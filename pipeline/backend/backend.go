@@ -27,3 +27,49 @@ type Engine interface {
 	// Destroy the pipeline environment.
 	Destroy(context.Context, *Config) error
 }
+
+// Puller is implemented by engines that pull the image of a pipeline
+// step in a separate phase before the step is started.
+type Puller interface {
+	// Pull the image of the pipeline step if required and write the
+	// pull progress to the writer. Returns false if the image was not
+	// pulled.
+	Pull(context.Context, *Step, io.Writer) (bool, error)
+}
+
+// Labels set on all resources of a pipeline.
+const (
+	// LabelPipeline identifies the pipeline that created the resource.
+	LabelPipeline = "io.woodpecker-ci.pipeline"
+	// LabelAgent identifies the agent that created the resource.
+	LabelAgent = "io.woodpecker-ci.agent"
+)
+
+// Reaper is implemented by engines that can find and remove the
+// resources left behind by pipelines that were never destroyed.
+type Reaper interface {
+	// Pipelines returns the ids of all pipelines with resources.
+	Pipelines(context.Context) ([]string, error)
+
+	// Reap removes all resources of the pipeline.
+	Reap(ctx context.Context, id string) error
+}
+
+// Copier is implemented by engines that can copy files from and to a
+// pipeline step, e.g. to restore and save the pipeline cache.
+type Copier interface {
+	// CopyTo extracts the tar archive into the dst directory of the step.
+	CopyTo(ctx context.Context, step *Step, dst string, r io.Reader) error
+
+	// CopyFrom returns the src path of the step as tar archive. The
+	// error wraps os.ErrNotExist if the path does not exist.
+	CopyFrom(ctx context.Context, step *Step, src string) (io.ReadCloser, error)
+}
+
+// HealthChecker is implemented by engines that can wait for the
+// healthcheck of a detached pipeline step.
+type HealthChecker interface {
+	// WaitHealthy blocks until the step reports healthy and returns an
+	// error if it becomes unhealthy or exits.
+	WaitHealthy(context.Context, *Step) error
+}
@@ -32,9 +32,27 @@ func toConfig(proc *backend.Step) *container.Config {
 	if len(proc.Volumes) != 0 {
 		config.Volumes = toVol(proc.Volumes)
 	}
+	if proc.Healthcheck != nil {
+		config.Healthcheck = toHealthConfig(proc.Healthcheck)
+	}
 	return config
 }
 
+// returns a container healthcheck configuration. A single command is
+// run in a shell, a list of arguments is executed directly.
+func toHealthConfig(healthcheck *backend.Healthcheck) *container.HealthConfig {
+	test := []string{"CMD"}
+	if len(healthcheck.Command) == 1 {
+		test = []string{"CMD-SHELL"}
+	}
+	return &container.HealthConfig{
+		Test:     append(test, healthcheck.Command...),
+		Interval: healthcheck.Interval,
+		Timeout:  healthcheck.Timeout,
+		Retries:  healthcheck.Retries,
+	}
+}
+
 // returns a container host configuration.
 func toHostConfig(proc *backend.Step) *container.HostConfig {
 	config := &container.HostConfig{
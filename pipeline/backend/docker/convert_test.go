@@ -3,6 +3,9 @@ package docker
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
 )
 
 func TestSplitVolumeParts(t *testing.T) {
@@ -72,3 +75,32 @@ func TestSplitVolumeParts(t *testing.T) {
 		}
 	}
 }
+
+func TestToHealthConfig(t *testing.T) {
+	testdata := []struct {
+		command []string
+		test    []string
+	}{
+		{
+			command: []string{"pg_isready -U postgres"},
+			test:    []string{"CMD-SHELL", "pg_isready -U postgres"},
+		},
+		{
+			command: []string{"redis-cli", "ping"},
+			test:    []string{"CMD", "redis-cli", "ping"},
+		},
+	}
+	for _, test := range testdata {
+		config := toHealthConfig(&backend.Healthcheck{
+			Command:  test.command,
+			Interval: time.Second,
+			Retries:  3,
+		})
+		if !reflect.DeepEqual(config.Test, test.test) {
+			t.Errorf("Expected healthcheck test %q, got %q", test.test, config.Test)
+		}
+		if config.Interval != time.Second || config.Retries != 3 {
+			t.Errorf("Expected interval and retries to be passed through")
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/docker/docker/api/types"
+	"github.com/moby/moby/client"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+)
+
+// CopyTo extracts the tar archive into the dst directory of the step
+// container.
+func (e *engine) CopyTo(ctx context.Context, proc *backend.Step, dst string, r io.Reader) error {
+	return e.client.CopyToContainer(ctx, proc.Name, dst, r, types.CopyToContainerOptions{})
+}
+
+// CopyFrom returns the src path of the step container as tar archive.
+func (e *engine) CopyFrom(ctx context.Context, proc *backend.Step, src string) (io.ReadCloser, error) {
+	rc, _, err := e.client.CopyFromContainer(ctx, proc.Name, src)
+	if client.IsErrNotFound(err) {
+		return nil, fmt.Errorf("%s: %w", src, os.ErrNotExist)
+	}
+	return rc, err
+}
@@ -3,15 +3,13 @@ package docker
 import (
 	"context"
 	"io"
-	"os"
+	"io/ioutil"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/moby/moby/client"
-	"github.com/moby/moby/pkg/jsonmessage"
 	"github.com/moby/moby/pkg/stdcopy"
-	"github.com/moby/term"
 
 	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
 )
@@ -30,20 +28,26 @@ func New(cli client.APIClient) backend.Engine {
 // NewEnv returns a new Docker Engine using the client connection
 // environment variables.
 func NewEnv() (backend.Engine, error) {
-	cli, err := client.NewClientWithOpts(client.FromEnv)
+	cli, err := NewEnvClient()
 	if err != nil {
 		return nil, err
 	}
 	return New(cli), nil
 }
 
+// NewEnvClient returns a new Docker client using the client connection
+// environment variables.
+func NewEnvClient() (client.APIClient, error) {
+	return client.NewClientWithOpts(client.FromEnv)
+}
+
 func (e *engine) Setup(_ context.Context, conf *backend.Config) error {
 	for _, vol := range conf.Volumes {
 		_, err := e.client.VolumeCreate(noContext, volume.VolumeCreateBody{
 			Name:       vol.Name,
 			Driver:     vol.Driver,
 			DriverOpts: vol.DriverOpts,
-			// Labels:     defaultLabels,
+			Labels:     conf.Labels,
 		})
 		if err != nil {
 			return err
@@ -53,7 +57,7 @@ func (e *engine) Setup(_ context.Context, conf *backend.Config) error {
 		_, err := e.client.NetworkCreate(noContext, n.Name, types.NetworkCreate{
 			Driver:  n.Driver,
 			Options: n.DriverOpts,
-			// Labels:  defaultLabels,
+			Labels:  conf.Labels,
 		})
 		if err != nil {
 			return err
@@ -66,40 +70,15 @@ func (e *engine) Exec(ctx context.Context, proc *backend.Step) error {
 	config := toConfig(proc)
 	hostConfig := toHostConfig(proc)
 
-	// create pull options with encoded authorization credentials.
-	pullopts := types.ImagePullOptions{}
-	if proc.AuthConfig.Username != "" && proc.AuthConfig.Password != "" {
-		pullopts.RegistryAuth, _ = encodeAuthToBase64(proc.AuthConfig)
-	}
-
-	// automatically pull the latest version of the image if requested
-	// by the process configuration.
-	if proc.Pull {
-		responseBody, perr := e.client.ImagePull(ctx, config.Image, pullopts)
-		if perr == nil {
-			defer responseBody.Close()
-
-			fd, isTerminal := term.GetFdInfo(os.Stdout)
-			jsonmessage.DisplayJSONMessagesStream(responseBody, os.Stdout, fd, isTerminal, nil)
-		}
-		// fix for drone/drone#1917
-		if perr != nil && proc.AuthConfig.Password != "" {
-			return perr
-		}
-	}
-
 	_, err := e.client.ContainerCreate(ctx, config, hostConfig, nil, nil, proc.Name)
 	if client.IsErrNotFound(err) {
 		// automatically pull and try to re-create the image if the
 		// failure is caused because the image does not exist.
-		responseBody, perr := e.client.ImagePull(ctx, config.Image, pullopts)
-		if perr != nil {
+		pull := *proc
+		pull.Pull = true
+		if _, perr := e.Pull(ctx, &pull, ioutil.Discard); perr != nil {
 			return perr
 		}
-		defer responseBody.Close()
-		fd, isTerminal := term.GetFdInfo(os.Stdout)
-		jsonmessage.DisplayJSONMessagesStream(responseBody, os.Stdout, fd, isTerminal, nil)
-
 		_, err = e.client.ContainerCreate(ctx, config, hostConfig, nil, nil, proc.Name)
 	}
 	if err != nil {
@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+)
+
+// healthPollInterval is the interval the health status is polled at.
+var healthPollInterval = 500 * time.Millisecond
+
+// WaitHealthy blocks until the healthcheck of the step reports the
+// container healthy.
+func (e *engine) WaitHealthy(ctx context.Context, proc *backend.Step) error {
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		info, err := e.client.ContainerInspect(ctx, proc.Name)
+		if err != nil {
+			return err
+		}
+		if done, err := checkHealth(info.State); done {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkHealth returns true if the container reached a final health
+// status and an error if it is not healthy.
+func checkHealth(state *types.ContainerState) (bool, error) {
+	switch {
+	case state == nil:
+		return false, nil
+	case !state.Running:
+		return true, fmt.Errorf("container exited with code %d", state.ExitCode)
+	case state.Health == nil:
+		// the container has no healthcheck.
+		return true, nil
+	}
+
+	switch state.Health.Status {
+	case types.Healthy:
+		return true, nil
+	case types.Unhealthy:
+		if n := len(state.Health.Log); n != 0 {
+			return true, fmt.Errorf("healthcheck failed: %s", strings.TrimSpace(state.Health.Log[n-1].Output))
+		}
+		return true, fmt.Errorf("healthcheck failed")
+	}
+	return false, nil
+}
@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+func TestCheckHealth(t *testing.T) {
+	testdata := []struct {
+		state *types.ContainerState
+		done  bool
+		err   string
+	}{
+		{
+			state: &types.ContainerState{Running: true, Health: &types.Health{Status: types.Starting}},
+		},
+		{
+			state: &types.ContainerState{Running: true, Health: &types.Health{Status: types.Healthy}},
+			done:  true,
+		},
+		{
+			state: &types.ContainerState{Running: true},
+			done:  true,
+		},
+		{
+			state: &types.ContainerState{Running: true, Health: &types.Health{
+				Status: types.Unhealthy,
+				Log:    []*types.HealthcheckResult{{Output: "connection refused\n"}},
+			}},
+			done: true,
+			err:  "healthcheck failed: connection refused",
+		},
+		{
+			state: &types.ContainerState{ExitCode: 1},
+			done:  true,
+			err:   "container exited with code 1",
+		},
+	}
+	for _, test := range testdata {
+		done, err := checkHealth(test.state)
+		if done != test.done {
+			t.Errorf("Expected done %v for status %v", test.done, test.state)
+		}
+		if test.err == "" && err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		} else if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("Expected error %q, got %v", test.err, err)
+		}
+	}
+}
@@ -1,42 +1,200 @@
 package docker
 
-// import (
-
-// )
-//
-// // Pool manages a pool of Docker clients.
-// type Pool struct {
-// 	queue chan (backend.Engine)
-// }
-//
-// // NewPool returns a Pool.
-// func NewPool(engines ...backend.Engine) *Pool {
-// 	return &Pool{
-// 		queue: make(chan backend.Engine, len(engines)),
-// 	}
-// }
-//
-// // Reserve requests the next available Docker client in the pool.
-// func (p *Pool) Reserve(c context.Context) backend.Engine {
-// 	select {
-// 	case <-c.Done():
-// 	case engine := <-p.queue:
-// 		return engine
-// 	}
-// 	return nil
-// }
-//
-// // Release releases the Docker client back to the pool.
-// func (p *Pool) Release(engine backend.Engine) {
-// 	p.queue <- engine
-// }
-
-// pool := docker.Pool(
-//   docker.FromEnvironmentMust(),
-//   docker.FromEnvironmentMust(),
-//   docker.FromEnvironmentMust(),
-//   docker.FromEnvironmentMust(),
-// )
-//
-// client := pool.Reserve()
-// defer pool.Release(client)
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/moby/moby/client"
+	"github.com/rs/zerolog/log"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+)
+
+// Pool manages Docker engines of one or more hosts. Pipelines are
+// placed on the healthy host with the lowest load.
+type Pool struct {
+	sync.Mutex
+	hosts []*poolHost
+	// wait is closed and replaced whenever capacity may have become
+	// available.
+	wait chan struct{}
+}
+
+type poolHost struct {
+	host     string
+	client   client.APIClient
+	engine   backend.Engine
+	capacity int
+	running  int
+	healthy  bool
+}
+
+// NewPool returns an empty Pool.
+func NewPool() *Pool {
+	return &Pool{
+		wait: make(chan struct{}),
+	}
+}
+
+// Add adds the Docker host to the pool, which runs up to capacity
+// pipelines at the same time.
+func (p *Pool) Add(host string, cli client.APIClient, capacity int) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.hosts = append(p.hosts, &poolHost{
+		host:     host,
+		client:   cli,
+		engine:   New(cli),
+		capacity: capacity,
+		healthy:  true,
+	})
+	p.notify()
+}
+
+// Capacity returns the number of pipelines all hosts of the pool can
+// run at the same time.
+func (p *Pool) Capacity() int {
+	p.Lock()
+	defer p.Unlock()
+
+	capacity := 0
+	for _, h := range p.hosts {
+		capacity += h.capacity
+	}
+	return capacity
+}
+
+// Engines returns the engines of all hosts in the pool.
+func (p *Pool) Engines() []backend.Engine {
+	p.Lock()
+	defer p.Unlock()
+
+	engines := make([]backend.Engine, 0, len(p.hosts))
+	for _, h := range p.hosts {
+		engines = append(engines, h.engine)
+	}
+	return engines
+}
+
+// Reserve blocks until a healthy host has capacity left and returns
+// the engine of the least loaded one. Returns nil if the context is
+// canceled first.
+func (p *Pool) Reserve(ctx context.Context) backend.Engine {
+	for {
+		p.Lock()
+		var next *poolHost
+		for _, h := range p.hosts {
+			if !h.healthy || h.running >= h.capacity {
+				continue
+			}
+			// compare the load h.running/h.capacity without division.
+			if next == nil || h.running*next.capacity < next.running*h.capacity {
+				next = h
+			}
+		}
+		if next != nil {
+			next.running++
+			p.Unlock()
+			return next.engine
+		}
+		wait := p.wait
+		p.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wait:
+		}
+	}
+}
+
+// Release releases the reserved engine back to the pool.
+func (p *Pool) Release(engine backend.Engine) {
+	p.Lock()
+	defer p.Unlock()
+
+	for _, h := range p.hosts {
+		if h.engine == engine && h.running > 0 {
+			h.running--
+			p.notify()
+			return
+		}
+	}
+}
+
+// Check pings all hosts of the pool. Hosts that do not respond are
+// taken out of rotation until they respond again.
+func (p *Pool) Check(ctx context.Context) {
+	p.Lock()
+	hosts := make([]*poolHost, len(p.hosts))
+	copy(hosts, p.hosts)
+	p.Unlock()
+
+	for _, h := range hosts {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		_, err := h.client.Ping(pingCtx)
+		cancel()
+
+		p.Lock()
+		switch {
+		case err != nil && h.healthy:
+			log.Warn().Err(err).Str("host", h.host).Msg("docker host is unhealthy, taking it out of rotation")
+		case err == nil && !h.healthy:
+			log.Info().Str("host", h.host).Msg("docker host is healthy again")
+		}
+		h.healthy = err == nil
+		p.notify()
+		p.Unlock()
+	}
+}
+
+// Monitor checks the health of all hosts every interval until the
+// context is canceled. Check the hosts first to place pipelines on
+// healthy hosts only from the start.
+func (p *Pool) Monitor(ctx context.Context, interval time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		p.Check(ctx)
+	}
+}
+
+// notify wakes up all callers waiting for capacity. The caller must
+// hold the lock.
+func (p *Pool) notify() {
+	close(p.wait)
+	p.wait = make(chan struct{})
+}
+
+// NewClient returns a Docker client for the host. If certPath is set,
+// the connection is secured using the ca.pem, cert.pem and key.pem of
+// the certPath/<hostname> directory if it exists, else of certPath.
+func NewClient(host, certPath string) (client.APIClient, error) {
+	opts := []client.Opt{
+		client.WithHost(host),
+		client.WithAPIVersionNegotiation(),
+	}
+	if certPath != "" {
+		if h, err := client.ParseHostURL(host); err == nil {
+			hostPath := filepath.Join(certPath, h.Hostname())
+			if info, err := os.Stat(hostPath); err == nil && info.IsDir() {
+				certPath = hostPath
+			}
+		}
+		opts = append(opts, client.WithTLSClientConfig(
+			filepath.Join(certPath, "ca.pem"),
+			filepath.Join(certPath, "cert.pem"),
+			filepath.Join(certPath, "key.pem"),
+		))
+	}
+	return client.NewClientWithOpts(opts...)
+}
+
+const pingTimeout = 10 * time.Second
@@ -0,0 +1,102 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/moby/moby/client"
+)
+
+type mockClient struct {
+	client.APIClient
+	err error
+}
+
+func (m *mockClient) Ping(context.Context) (types.Ping, error) {
+	return types.Ping{}, m.err
+}
+
+func TestPoolReserveLeastLoaded(t *testing.T) {
+	pool := NewPool()
+	pool.Add("a", &mockClient{}, 1)
+	pool.Add("b", &mockClient{}, 3)
+	engines := pool.Engines()
+
+	if got := pool.Capacity(); got != 4 {
+		t.Errorf("Want capacity 4, got %d", got)
+	}
+
+	// a: 0/1, b: 0/3 -> a
+	// a: 1/1, b: 0/3 -> b
+	// a: 1/1, b: 1/3 -> b
+	want := []int{0, 1, 1, 1}
+	for i, w := range want {
+		if got := pool.Reserve(context.Background()); got != engines[w] {
+			t.Errorf("Want reservation %d on host %d", i, w)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if got := pool.Reserve(ctx); got != nil {
+		t.Errorf("Want no engine if the pool is exhausted")
+	}
+
+	pool.Release(engines[0])
+	if got := pool.Reserve(context.Background()); got != engines[0] {
+		t.Errorf("Want released engine to be reserved again")
+	}
+}
+
+func TestPoolReserveWaits(t *testing.T) {
+	pool := NewPool()
+	pool.Add("a", &mockClient{}, 1)
+	engine := pool.Reserve(context.Background())
+
+	reserved := make(chan struct{})
+	go func() {
+		pool.Reserve(context.Background())
+		close(reserved)
+	}()
+
+	select {
+	case <-reserved:
+		t.Fatal("Want reservation to block until the engine is released")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	pool.Release(engine)
+	select {
+	case <-reserved:
+	case <-time.After(time.Second):
+		t.Fatal("Want reservation after the engine is released")
+	}
+}
+
+func TestPoolCheck(t *testing.T) {
+	unhealthy := &mockClient{err: errors.New("connection refused")}
+
+	pool := NewPool()
+	pool.Add("a", unhealthy, 2)
+	pool.Add("b", &mockClient{}, 1)
+	engines := pool.Engines()
+	pool.Check(context.Background())
+
+	if got := pool.Reserve(context.Background()); got != engines[1] {
+		t.Errorf("Want reservation on the healthy host")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if got := pool.Reserve(ctx); got != nil {
+		t.Errorf("Want unhealthy host to be taken out of rotation")
+	}
+
+	unhealthy.err = nil
+	pool.Check(context.Background())
+	if got := pool.Reserve(context.Background()); got != engines[0] {
+		t.Errorf("Want recovered host to be back in rotation")
+	}
+}
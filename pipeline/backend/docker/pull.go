@@ -0,0 +1,88 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-units"
+	"github.com/moby/moby/pkg/jsonmessage"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+)
+
+// Pull pulls the image of the step if pulling is requested or the
+// image does not exist and writes the condensed progress to w.
+func (e *engine) Pull(ctx context.Context, proc *backend.Step, w io.Writer) (bool, error) {
+	if !proc.Pull {
+		if _, _, err := e.client.ImageInspectWithRaw(ctx, proc.Image); err == nil {
+			return false, nil
+		}
+	}
+
+	// create pull options with encoded authorization credentials.
+	pullopts := types.ImagePullOptions{}
+	if proc.AuthConfig.Username != "" && proc.AuthConfig.Password != "" {
+		pullopts.RegistryAuth, _ = encodeAuthToBase64(proc.AuthConfig)
+	}
+
+	fmt.Fprintf(w, "Pulling image %s\n", proc.Image)
+	responseBody, err := e.client.ImagePull(ctx, proc.Image, pullopts)
+	if err == nil {
+		err = writePullProgress(responseBody, w)
+		responseBody.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Error pulling image %s: %s\n", proc.Image, err)
+		// fix for drone/drone#1917: fall back to the local image if it
+		// exists and no credentials are used.
+		if proc.Pull && proc.AuthConfig.Password == "" {
+			if _, _, ierr := e.client.ImageInspectWithRaw(ctx, proc.Image); ierr == nil {
+				return true, nil
+			}
+		}
+		return true, err
+	}
+	return true, nil
+}
+
+// writePullProgress decodes the json messages of an image pull and
+// writes them condensed to one line per layer status change.
+func writePullProgress(r io.Reader, w io.Writer) error {
+	status := map[string]string{}
+	dec := json.NewDecoder(r)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if msg.ErrorMessage != "" {
+			return errors.New(msg.ErrorMessage)
+		}
+		if msg.ID == "" {
+			fmt.Fprintln(w, msg.Status)
+			continue
+		}
+
+		// skip progress updates of a layer
+		if status[msg.ID] == msg.Status {
+			continue
+		}
+		status[msg.ID] = msg.Status
+
+		if msg.Progress != nil && msg.Progress.Total > 0 {
+			fmt.Fprintf(w, "%s: %s %s\n", msg.ID, msg.Status, units.HumanSize(float64(msg.Progress.Total)))
+		} else {
+			fmt.Fprintf(w, "%s: %s\n", msg.ID, msg.Status)
+		}
+	}
+}
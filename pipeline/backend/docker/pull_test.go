@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWritePullProgress(t *testing.T) {
+	in := `{"status":"Pulling from library/alpine","id":"latest"}
+{"status":"Pulling fs layer","progressDetail":{},"id":"59bf1c3509f3"}
+{"status":"Downloading","progressDetail":{"current":28204,"total":2818413},"id":"59bf1c3509f3"}
+{"status":"Downloading","progressDetail":{"current":1418204,"total":2818413},"id":"59bf1c3509f3"}
+{"status":"Download complete","progressDetail":{},"id":"59bf1c3509f3"}
+{"status":"Pull complete","progressDetail":{},"id":"59bf1c3509f3"}
+{"status":"Digest: sha256:21a3deaa0d32a8057914f36584b5288d2e5ecc984380bc0118285c70fa8c9300"}
+{"status":"Status: Downloaded newer image for alpine:latest"}
+`
+	want := `latest: Pulling from library/alpine
+59bf1c3509f3: Pulling fs layer
+59bf1c3509f3: Downloading 2.818MB
+59bf1c3509f3: Download complete
+59bf1c3509f3: Pull complete
+Digest: sha256:21a3deaa0d32a8057914f36584b5288d2e5ecc984380bc0118285c70fa8c9300
+Status: Downloaded newer image for alpine:latest
+`
+	var out bytes.Buffer
+	if err := writePullProgress(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("Want pull progress\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestWritePullProgressError(t *testing.T) {
+	in := `{"status":"Pulling from library/missing","id":"latest"}
+{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}
+`
+	var out bytes.Buffer
+	err := writePullProgress(strings.NewReader(in), &out)
+	if err == nil || err.Error() != "manifest unknown" {
+		t.Errorf("Want error manifest unknown, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+)
+
+// Pipelines returns the ids of all pipelines with labeled containers,
+// volumes or networks.
+func (e *engine) Pipelines(ctx context.Context) ([]string, error) {
+	args := filters.NewArgs(filters.Arg("label", backend.LabelPipeline))
+
+	var labels []map[string]string
+	containers, err := e.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range containers {
+		labels = append(labels, c.Labels)
+	}
+	volumes, err := e.client.VolumeList(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range volumes.Volumes {
+		labels = append(labels, v.Labels)
+	}
+	networks, err := e.client.NetworkList(ctx, types.NetworkListOptions{Filters: args})
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range networks {
+		labels = append(labels, n.Labels)
+	}
+
+	var ids []string
+	seen := map[string]bool{}
+	for _, l := range labels {
+		id := l[backend.LabelPipeline]
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Reap removes the containers, volumes and networks of the pipeline.
+func (e *engine) Reap(ctx context.Context, id string) error {
+	args := filters.NewArgs(filters.Arg("label", backend.LabelPipeline+"="+id))
+
+	containers, err := e.client.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: args})
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if err := e.client.ContainerRemove(ctx, c.ID, reapOpts); err != nil {
+			return err
+		}
+	}
+	networks, err := e.client.NetworkList(ctx, types.NetworkListOptions{Filters: args})
+	if err != nil {
+		return err
+	}
+	for _, n := range networks {
+		if err := e.client.NetworkRemove(ctx, n.ID); err != nil {
+			return err
+		}
+	}
+	volumes, err := e.client.VolumeList(ctx, args)
+	if err != nil {
+		return err
+	}
+	for _, v := range volumes.Volumes {
+		if err := e.client.VolumeRemove(ctx, v.Name, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var reapOpts = types.ContainerRemoveOptions{
+	RemoveVolumes: true,
+	Force:         true,
+}
@@ -1,19 +1,23 @@
 package backend
 
+import "time"
+
 type (
 	// Config defines the runtime configuration of a pipeline.
 	Config struct {
-		Stages   []*Stage   `json:"pipeline"` // pipeline stages
-		Networks []*Network `json:"networks"` // network definitions
-		Volumes  []*Volume  `json:"volumes"`  // volume definitions
-		Secrets  []*Secret  `json:"secrets"`  // secret definitions
+		Stages   []*Stage          `json:"pipeline"`         // pipeline stages
+		Networks []*Network        `json:"networks"`         // network definitions
+		Volumes  []*Volume         `json:"volumes"`          // volume definitions
+		Secrets  []*Secret         `json:"secrets"`          // secret definitions
+		Labels   map[string]string `json:"labels,omitempty"` // labels of all pipeline resources
 	}
 
 	// Stage denotes a collection of one or more steps.
 	Stage struct {
-		Name  string  `json:"name,omitempty"`
-		Alias string  `json:"alias,omitempty"`
-		Steps []*Step `json:"steps,omitempty"`
+		Name         string  `json:"name,omitempty"`
+		Alias        string  `json:"alias,omitempty"`
+		DefaultClone bool    `json:"default_clone,omitempty"` // stage of the default clone step
+		Steps        []*Step `json:"steps,omitempty"`
 	}
 
 	// Step defines a container process.
@@ -48,6 +52,26 @@ type (
 		NetworkMode  string            `json:"network_mode,omitempty"`
 		IpcMode      string            `json:"ipc_mode,omitempty"`
 		Sysctls      map[string]string `json:"sysctls,omitempty"`
+		Cache        *Cache            `json:"cache,omitempty"`
+		Healthcheck  *Healthcheck      `json:"healthcheck,omitempty"`
+	}
+
+	// Healthcheck defines the command reporting a detached step healthy.
+	// A single command is run in a shell.
+	Healthcheck struct {
+		Command  []string      `json:"command"`
+		Interval time.Duration `json:"interval,omitempty"`
+		Timeout  time.Duration `json:"timeout,omitempty"`
+		Retries  int           `json:"retries,omitempty"`
+	}
+
+	// Cache defines the pipeline cache restored or saved by a step.
+	Cache struct {
+		Scope       string   `json:"scope"` // caches are isolated per scope
+		Key         string   `json:"key"`
+		RestoreKeys []string `json:"restore_keys,omitempty"`
+		Paths       []string `json:"paths"`
+		Save        bool     `json:"save,omitempty"`
 	}
 
 	// Auth defines registry authentication credentials.
@@ -93,6 +117,10 @@ type (
 		Exited bool `json:"exited"`
 		// Container is oom killed, true or false
 		OOMKilled bool `json:"oom_killed"`
+		// Image pull started, zero if the image was not pulled
+		PullStarted int64 `json:"pull_started,omitempty"`
+		// Image pull finished, zero if the image was not pulled
+		PullFinished int64 `json:"pull_finished,omitempty"`
 	}
 
 	// // State defines the pipeline and process state.
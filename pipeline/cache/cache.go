@@ -0,0 +1,254 @@
+// Package cache restores and saves the pipeline cache on the agent.
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+)
+
+// ErrNotFound is returned by a store if the cache entry does not exist.
+var ErrNotFound = errors.New("cache entry not found")
+
+// Store stores cache entries as gzip compressed tar archives.
+type Store interface {
+	// Get returns the archive of the entry, ErrNotFound if it does
+	// not exist.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Put stores the archive of size bytes as the entry and evicts the
+	// least recently used entries if the store exceeds its size limit.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+
+	// List returns the entries whose key starts with prefix.
+	List(ctx context.Context, prefix string) ([]Entry, error)
+}
+
+// Entry describes a stored cache entry.
+type Entry struct {
+	Key      string
+	Size     int64
+	Modified time.Time
+}
+
+// Cache restores and saves the cache of pipeline steps.
+type Cache struct {
+	store        Store
+	maxEntrySize int64
+}
+
+// New returns a new Cache using the store. Entries larger than
+// maxEntrySize bytes are not saved, 0 means unlimited.
+func New(store Store, maxEntrySize int64) *Cache {
+	return &Cache{
+		store:        store,
+		maxEntrySize: maxEntrySize,
+	}
+}
+
+// Restore restores the cache matching the key, or else the most recent
+// cache matching one of the restore keys, into the workspace of the
+// step. Hits and misses are written to w.
+func (c *Cache) Restore(ctx context.Context, copier backend.Copier, step *backend.Step, w io.Writer) error {
+	spec := step.Cache
+	key, err := evalKey(ctx, copier, step, spec.Key)
+	if err != nil {
+		return fmt.Errorf("cannot evaluate cache key: %w", err)
+	}
+
+	entry, err := c.find(ctx, spec.Scope, key, false)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		for _, restoreKey := range spec.RestoreKeys {
+			restoreKey, err := evalKey(ctx, copier, step, restoreKey)
+			if err != nil {
+				return fmt.Errorf("cannot evaluate cache restore key: %w", err)
+			}
+			if entry, err = c.find(ctx, spec.Scope, restoreKey, true); err != nil {
+				return err
+			}
+			if entry != nil {
+				break
+			}
+		}
+	}
+	if entry == nil {
+		fmt.Fprintf(w, "Cache miss for key %s\n", key)
+		return nil
+	}
+
+	restored := strings.TrimPrefix(entry.Key, scopePrefix(spec.Scope))
+	if restored == key {
+		fmt.Fprintf(w, "Cache hit for key %s\n", key)
+	} else {
+		fmt.Fprintf(w, "Cache miss for key %s, restoring cache of key %s\n", key, restored)
+	}
+
+	rc, err := c.store.Get(ctx, entry.Key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	gr, err := gzip.NewReader(rc)
+	if err != nil {
+		return err
+	}
+	if err := copier.CopyTo(ctx, step, step.WorkingDir, gr); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Restored cache of %s\n", units.HumanSize(float64(entry.Size)))
+	return nil
+}
+
+// Save saves the cached paths of the workspace of the step, unless a
+// cache with the key already exists.
+func (c *Cache) Save(ctx context.Context, copier backend.Copier, step *backend.Step, w io.Writer) error {
+	spec := step.Cache
+	key, err := evalKey(ctx, copier, step, spec.Key)
+	if err != nil {
+		return fmt.Errorf("cannot evaluate cache key: %w", err)
+	}
+
+	entry, err := c.find(ctx, spec.Scope, key, false)
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		fmt.Fprintf(w, "Cache for key %s already exists, skipping save\n", key)
+		return nil
+	}
+
+	tmp, err := ioutil.TempFile("", "woodpecker-cache-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	archived, err := c.archive(ctx, copier, step, tmp, w)
+	if err != nil {
+		return err
+	}
+	if archived == 0 {
+		fmt.Fprintf(w, "No cached paths exist, skipping save\n")
+		return nil
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if c.maxEntrySize > 0 && size > c.maxEntrySize {
+		fmt.Fprintf(w, "Cache of %s exceeds the limit of %s, skipping save\n",
+			units.HumanSize(float64(size)), units.HumanSize(float64(c.maxEntrySize)))
+		return nil
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := c.store.Put(ctx, scopePrefix(spec.Scope)+key, tmp, size); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Saved cache of %s with key %s\n", units.HumanSize(float64(size)), key)
+	return nil
+}
+
+// archive writes the cached paths of the step as gzip compressed tar
+// archive to w and returns the number of archived paths.
+func (c *Cache) archive(ctx context.Context, copier backend.Copier, step *backend.Step, w io.Writer, log io.Writer) (int, error) {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	archived := 0
+	for _, p := range step.Cache.Paths {
+		p = path.Clean(p)
+		rc, err := copier.CopyFrom(ctx, step, path.Join(step.WorkingDir, p))
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(log, "Cached path %s does not exist\n", p)
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		// the archive entries are relative to the parent directory of
+		// the path, but restored relative to the workspace.
+		err = appendTar(tw, tar.NewReader(rc), path.Dir(p))
+		rc.Close()
+		if err != nil {
+			return 0, err
+		}
+		archived++
+	}
+
+	if err := tw.Close(); err != nil {
+		return 0, err
+	}
+	return archived, gw.Close()
+}
+
+// find returns the entry with the key, or the most recently modified
+// entry with the key as prefix. Returns nil if there is none.
+func (c *Cache) find(ctx context.Context, scope, key string, prefix bool) (*Entry, error) {
+	key = scopePrefix(scope) + key
+	entries, err := c.store.List(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Modified.After(entries[j].Modified)
+	})
+	for _, entry := range entries {
+		if prefix || entry.Key == key {
+			entry := entry
+			return &entry, nil
+		}
+	}
+	return nil, nil
+}
+
+// appendTar copies all entries of the tar reader to the tar writer,
+// prefixing their names with dir.
+func appendTar(tw *tar.Writer, tr *tar.Reader, dir string) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if dir != "." {
+			hdr.Name = path.Join(dir, hdr.Name)
+			if hdr.Typeflag == tar.TypeLink {
+				hdr.Linkname = path.Join(dir, hdr.Linkname)
+			}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// scopePrefix returns the key prefix isolating the caches of a scope.
+func scopePrefix(scope string) string {
+	if scope == "" {
+		return ""
+	}
+	return scope + "/"
+}
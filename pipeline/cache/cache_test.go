@@ -0,0 +1,243 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+)
+
+// mockCopier is a workspace of files keyed by absolute path.
+type mockCopier struct {
+	files map[string]string
+}
+
+func (m *mockCopier) CopyTo(_ context.Context, _ *backend.Step, dst string, r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		m.files[path.Join(dst, hdr.Name)] = string(data)
+	}
+}
+
+func (m *mockCopier) CopyFrom(_ context.Context, _ *backend.Step, src string) (io.ReadCloser, error) {
+	var names []string
+	for name := range m.files {
+		if name == src || strings.HasPrefix(name, src+"/") {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s: %w", src, os.ErrNotExist)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		data := m.files[name]
+		tw.WriteHeader(&tar.Header{
+			Name:     path.Join(path.Base(src), strings.TrimPrefix(name, src)),
+			Typeflag: tar.TypeReg,
+			Mode:     0o644,
+			Size:     int64(len(data)),
+		})
+		tw.Write([]byte(data))
+	}
+	tw.Close()
+	return ioutil.NopCloser(&buf), nil
+}
+
+func newTestCache(t *testing.T, maxEntrySize int64) (*Cache, Store) {
+	dir, err := ioutil.TempDir("", "woodpecker-cache-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	store, err := NewLocal(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(store, maxEntrySize), store
+}
+
+func cacheStep(key string, restoreKeys ...string) *backend.Step {
+	return &backend.Step{
+		WorkingDir: "/woodpecker/src",
+		Cache: &backend.Cache{
+			Scope:       "octocat/hello-world",
+			Key:         key,
+			RestoreKeys: restoreKeys,
+			Paths:       []string{"node_modules", "vendor/cache", "missing"},
+		},
+	}
+}
+
+func TestCacheSaveRestore(t *testing.T) {
+	cache, _ := newTestCache(t, 0)
+	ctx := context.Background()
+
+	build := &mockCopier{files: map[string]string{
+		"/woodpecker/src/package-lock.json":          "lock",
+		"/woodpecker/src/node_modules/foo/index.js":  "foo",
+		"/woodpecker/src/vendor/cache/bar.gem":       "bar",
+		"/woodpecker/src/vendor/other/not-cached.go": "baz",
+	}}
+
+	var out bytes.Buffer
+	save := cacheStep(`npm-{{ checksum "package-lock.json" }}`)
+	save.Cache.Save = true
+	if err := cache.Save(ctx, build, save, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Cached path missing does not exist") {
+		t.Errorf("Want missing path reported, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), "Saved cache") {
+		t.Errorf("Want cache saved, got %q", out.String())
+	}
+
+	// saving again with the same key is skipped.
+	out.Reset()
+	if err := cache.Save(ctx, build, save, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "already exists") {
+		t.Errorf("Want existing cache reported, got %q", out.String())
+	}
+
+	// a build with the same lock file restores the cache.
+	out.Reset()
+	next := &mockCopier{files: map[string]string{
+		"/woodpecker/src/package-lock.json": "lock",
+	}}
+	if err := cache.Restore(ctx, next, cacheStep(`npm-{{ checksum "package-lock.json" }}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Cache hit") {
+		t.Errorf("Want cache hit, got %q", out.String())
+	}
+	for name, want := range map[string]string{
+		"/woodpecker/src/node_modules/foo/index.js": "foo",
+		"/woodpecker/src/vendor/cache/bar.gem":      "bar",
+	} {
+		if got := next.files[name]; got != want {
+			t.Errorf("Want %s restored with %q, got %q", name, want, got)
+		}
+	}
+	if _, ok := next.files["/woodpecker/src/vendor/other/not-cached.go"]; ok {
+		t.Errorf("Want only cached paths restored")
+	}
+}
+
+func TestCacheRestoreKeys(t *testing.T) {
+	cache, store := newTestCache(t, 0)
+	ctx := context.Background()
+
+	build := &mockCopier{files: map[string]string{
+		"/woodpecker/src/node_modules/foo/index.js": "foo",
+	}}
+	save := cacheStep("npm-1")
+	save.Cache.Save = true
+	if err := cache.Save(ctx, build, save, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	// caches of other scopes are never restored.
+	other := cacheStep("npm-1")
+	other.Cache.Scope = "octocat/hello"
+	var out bytes.Buffer
+	if err := cache.Restore(ctx, &mockCopier{files: map[string]string{}}, other, &out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "Cache miss for key npm-1\n"; got != want {
+		t.Errorf("Want %q, got %q", want, got)
+	}
+
+	out.Reset()
+	next := &mockCopier{files: map[string]string{}}
+	if err := cache.Restore(ctx, next, cacheStep("npm-2", "yarn-", "npm-"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Cache miss for key npm-2, restoring cache of key npm-1") {
+		t.Errorf("Want cache restored from restore key, got %q", out.String())
+	}
+	if next.files["/woodpecker/src/node_modules/foo/index.js"] != "foo" {
+		t.Errorf("Want cache restored")
+	}
+
+	entries, _ := store.List(ctx, "")
+	if len(entries) != 1 || entries[0].Key != "octocat/hello-world/npm-1" {
+		t.Errorf("Want single scoped entry, got %v", entries)
+	}
+}
+
+func TestCacheMaxEntrySize(t *testing.T) {
+	cache, store := newTestCache(t, 10)
+	ctx := context.Background()
+
+	build := &mockCopier{files: map[string]string{
+		"/woodpecker/src/node_modules/foo/index.js": strings.Repeat("foo", 1000),
+	}}
+	save := cacheStep("npm")
+	save.Cache.Save = true
+
+	var out bytes.Buffer
+	if err := cache.Save(ctx, build, save, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "exceeds the limit") {
+		t.Errorf("Want exceeded limit reported, got %q", out.String())
+	}
+	if entries, _ := store.List(ctx, ""); len(entries) != 0 {
+		t.Errorf("Want no entry saved, got %v", entries)
+	}
+}
+
+func TestEvalKey(t *testing.T) {
+	copier := &mockCopier{files: map[string]string{
+		"/woodpecker/src/go.sum": "sum",
+	}}
+	step := &backend.Step{
+		WorkingDir: "/woodpecker/src",
+		Environment: map[string]string{
+			"CI_COMMIT_BRANCH": "main",
+			"CI_SYSTEM_ARCH":   "linux/amd64",
+		},
+	}
+
+	key, err := evalKey(context.Background(), copier, step, `go-{{ arch }}-{{ env "CI_COMMIT_BRANCH" }}-{{ checksum "go.sum" }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sha256 of "sum"
+	want := "go-linux/amd64-main-09f5ffef28309853265c4a98d0e56e1be522b6b402d8193594fd05103064fc6a"
+	if key != want {
+		t.Errorf("Want key %s, got %s", want, key)
+	}
+
+	if _, err := evalKey(context.Background(), copier, step, `{{ checksum "missing" }}`); err == nil {
+		t.Errorf("Want error for checksum of missing file")
+	}
+}
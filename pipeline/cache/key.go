@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+)
+
+// evalKey evaluates the template expressions of a cache key:
+//
+//	{{ checksum "package-lock.json" }}  sha256 checksum of workspace files
+//	{{ env "CI_COMMIT_BRANCH" }}         environment variable of the step
+//	{{ arch }}                           platform of the agent, e.g. linux/amd64
+func evalKey(ctx context.Context, copier backend.Copier, step *backend.Step, key string) (string, error) {
+	if !strings.Contains(key, "{{") {
+		return key, nil
+	}
+
+	funcs := template.FuncMap{
+		"checksum": func(files ...string) (string, error) {
+			return checksum(ctx, copier, step, files)
+		},
+		"env": func(name string) string {
+			return step.Environment[name]
+		},
+		"arch": func() string {
+			return step.Environment["CI_SYSTEM_ARCH"]
+		},
+	}
+	tmpl, err := template.New("key").Funcs(funcs).Parse(key)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, nil); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// checksum returns the sha256 checksum of the contents of the workspace
+// files of the step.
+func checksum(ctx context.Context, copier backend.Copier, step *backend.Step, files []string) (string, error) {
+	h := sha256.New()
+	for _, file := range files {
+		rc, err := copier.CopyFrom(ctx, step, path.Join(step.WorkingDir, file))
+		if err != nil {
+			return "", err
+		}
+		err = hashTar(h, tar.NewReader(rc))
+		rc.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashTar writes the contents of all regular files of the tar archive
+// to the hash.
+func hashTar(w io.Writer, tr *tar.Reader) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if _, err := io.Copy(w, tr); err != nil {
+			return err
+		}
+	}
+}
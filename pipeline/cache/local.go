@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type local struct {
+	sync.Mutex
+	dir     string
+	maxSize int64
+}
+
+// NewLocal returns a store saving the entries in the directory. If the
+// entries exceed maxSize bytes the least recently used ones are evicted,
+// 0 means unlimited.
+func NewLocal(dir string, maxSize int64) (Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &local{
+		dir:     dir,
+		maxSize: maxSize,
+	}, nil
+}
+
+func (s *local) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	file := s.path(key)
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	// the modification time tracks the last use for the eviction.
+	now := time.Now()
+	_ = os.Chtimes(file, now, now)
+	return f, nil
+}
+
+func (s *local) Put(_ context.Context, key string, r io.Reader, _ int64) error {
+	tmp, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return err
+	}
+	return s.evict()
+}
+
+func (s *local) List(_ context.Context, prefix string) ([]Entry, error) {
+	entries, err := s.entries()
+	if err != nil {
+		return nil, err
+	}
+	var list []Entry
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Key, prefix) {
+			list = append(list, entry)
+		}
+	}
+	return list, nil
+}
+
+// evict removes the least recently used entries until the store does
+// not exceed its size limit.
+func (s *local) evict() error {
+	if s.maxSize <= 0 {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	entries, err := s.entries()
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, entry := range entries {
+		size += entry.Size
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Modified.Before(entries[j].Modified)
+	})
+	for _, entry := range entries {
+		if size <= s.maxSize {
+			break
+		}
+		if err := os.Remove(s.path(entry.Key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		size -= entry.Size
+	}
+	return nil
+}
+
+func (s *local) entries() ([]Entry, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".tar.gz") {
+			continue
+		}
+		key, err := url.PathUnescape(strings.TrimSuffix(file.Name(), ".tar.gz"))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{
+			Key:      key,
+			Size:     file.Size(),
+			Modified: file.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+// path returns the file of the entry. Keys are escaped to store all
+// entries in a flat directory.
+func (s *local) path(key string) string {
+	return filepath.Join(s.dir, url.PathEscape(key)+".tar.gz")
+}
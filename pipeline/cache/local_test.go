@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalEvict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "woodpecker-cache-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewLocal(dir, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	put := func(key string, age time.Duration) {
+		if err := store.Put(ctx, key, strings.NewReader("0123456789"), 10); err != nil {
+			t.Fatal(err)
+		}
+		modified := time.Now().Add(-age)
+		os.Chtimes(filepath.Join(dir, strings.ReplaceAll(key, "/", "%2F")+".tar.gz"), modified, modified)
+	}
+	put("octocat/hello-world/a", 3*time.Hour)
+	put("octocat/hello-world/b", 2*time.Hour)
+
+	// using an entry protects it from the eviction.
+	rc, err := store.Get(ctx, "octocat/hello-world/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc.Close()
+
+	put("octocat/hello-world/c", 0)
+
+	entries, err := store.List(ctx, "octocat/hello-world/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]bool{}
+	for _, entry := range entries {
+		keys[entry.Key] = true
+	}
+	if len(keys) != 2 || !keys["octocat/hello-world/a"] || !keys["octocat/hello-world/c"] {
+		t.Errorf("Want least recently used entry b evicted, got %v", keys)
+	}
+
+	if _, err := store.Get(ctx, "octocat/hello-world/b"); err != ErrNotFound {
+		t.Errorf("Want ErrNotFound for evicted entry, got %v", err)
+	}
+}
@@ -0,0 +1,303 @@
+package cache
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// S3Options configures a store in an S3 compatible object storage.
+type S3Options struct {
+	// Endpoint of the object storage, defaults to the AWS endpoint of
+	// the region.
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	// Prefix of all object names.
+	Prefix string
+	// PathStyle addresses the bucket in the path instead of the host,
+	// required by most self-hosted object storages.
+	PathStyle bool
+}
+
+type s3 struct {
+	sync.Mutex
+	opts    S3Options
+	base    *url.URL
+	client  *http.Client
+	maxSize int64
+}
+
+// NewS3 returns a store saving the entries in an S3 compatible object
+// storage. If the entries exceed maxSize bytes the least recently saved
+// ones are evicted, 0 means unlimited.
+func NewS3(opts S3Options, maxSize int64) (Store, error) {
+	if opts.Bucket == "" {
+		return nil, fmt.Errorf("s3 cache: bucket is required")
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", opts.Region)
+	}
+	base, err := url.Parse(strings.TrimSuffix(opts.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if opts.PathStyle {
+		base.Path += "/" + opts.Bucket
+	} else {
+		base.Host = opts.Bucket + "." + base.Host
+	}
+	return &s3{
+		opts:    opts,
+		base:    base,
+		client:  &http.Client{},
+		maxSize: maxSize,
+	}, nil
+}
+
+func (s *s3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, "GET", s.object(key), nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *s3) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	resp, err := s.do(ctx, "PUT", s.object(key), nil, r, size)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return s.evict(ctx)
+}
+
+func (s *s3) List(ctx context.Context, prefix string) ([]Entry, error) {
+	objects, err := s.list(ctx, s.opts.Prefix+prefix)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(objects))
+	for _, object := range objects {
+		entries = append(entries, Entry{
+			Key:      strings.TrimPrefix(object.Key, s.opts.Prefix),
+			Size:     object.Size,
+			Modified: object.LastModified,
+		})
+	}
+	return entries, nil
+}
+
+// evict removes the least recently saved entries until the store does
+// not exceed its size limit. Object storages do not track the last
+// access, the time of the upload is used instead.
+func (s *s3) evict(ctx context.Context) error {
+	if s.maxSize <= 0 {
+		return nil
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	objects, err := s.list(ctx, s.opts.Prefix)
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, object := range objects {
+		size += object.Size
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].LastModified.Before(objects[j].LastModified)
+	})
+	for _, object := range objects {
+		if size <= s.maxSize {
+			break
+		}
+		resp, err := s.do(ctx, "DELETE", s.path(object.Key), nil, nil, 0)
+		if err != nil {
+			return err
+		}
+		if err := checkResponse(resp); err != nil {
+			return err
+		}
+		resp.Body.Close()
+		size -= object.Size
+	}
+	return nil
+}
+
+type s3Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+type s3ListResult struct {
+	Contents              []s3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+// list returns all objects whose name starts with prefix.
+func (s *s3) list(ctx context.Context, prefix string) ([]s3Object, error) {
+	var objects []s3Object
+	query := url.Values{}
+	query.Set("list-type", "2")
+	query.Set("prefix", prefix)
+	for {
+		resp, err := s.do(ctx, "GET", s.path(""), query, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkResponse(resp); err != nil {
+			return nil, err
+		}
+		result := new(s3ListResult)
+		err = xml.NewDecoder(resp.Body).Decode(result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// object returns the path of the object of the entry.
+func (s *s3) object(key string) string {
+	return s.path(s.opts.Prefix + key)
+}
+
+// path returns the path of the object in the bucket.
+func (s *s3) path(name string) string {
+	return s.base.Path + "/" + name
+}
+
+// do sends a request signed with AWS signature version 4.
+func (s *s3) do(ctx context.Context, method, path string, query url.Values, body io.Reader, size int64) (*http.Response, error) {
+	u := *s.base
+	u.Path = path
+	u.RawPath = escapePath(path)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	s.sign(req, time.Now().UTC())
+	return s.client.Do(req)
+}
+
+// sign signs the request with AWS signature version 4. The payload is
+// not signed to allow streaming it.
+func (s *s3) sign(req *http.Request, now time.Time) {
+	const payloadHash = "UNSIGNED-PAYLOAD"
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.opts.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+// canonicalQuery encodes the query sorted by key with spaces encoded
+// as %20 as required by the signature.
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+// escapePath encodes all characters of the path except the unreserved
+// ones and slashes as required by the signature.
+func escapePath(path string) string {
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+func hashHex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 cache: unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is an in-memory object storage using path style addressing.
+type fakeS3 struct {
+	sync.Mutex
+	objects  map[string]string
+	modified map[string]time.Time
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch {
+	case r.Method == "GET" && name == "":
+		result := s3ListResult{}
+		for key, data := range f.objects {
+			if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+				result.Contents = append(result.Contents, s3Object{Key: key, Size: int64(len(data)), LastModified: f.modified[key]})
+			}
+		}
+		sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == "GET":
+		data, ok := f.objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(data))
+	case r.Method == "PUT":
+		data, _ := ioutil.ReadAll(r.Body)
+		f.objects[name] = string(data)
+		f.modified[name] = time.Now().Add(time.Duration(len(f.objects)) * time.Second)
+	case r.Method == "DELETE":
+		delete(f.objects, name)
+	}
+}
+
+func TestS3(t *testing.T) {
+	fake := &fakeS3{objects: map[string]string{}, modified: map[string]time.Time{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3(S3Options{
+		Endpoint:  server.URL,
+		Bucket:    "bucket",
+		AccessKey: "access",
+		SecretKey: "secret",
+		Prefix:    "cache/",
+		PathStyle: true,
+	}, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, key := range []string{"octocat/hello-world/a", "octocat/hello-world/b", "octocat/hello-world/c"} {
+		if err := store.Put(ctx, key, strings.NewReader("0123456789"), 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := fake.objects["cache/octocat/hello-world/a"]; ok {
+		t.Errorf("Want oldest object evicted")
+	}
+
+	entries, err := store.List(ctx, "octocat/hello-world/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Key != "octocat/hello-world/b" || entries[1].Key != "octocat/hello-world/c" {
+		t.Errorf("Want entries b and c, got %v", entries)
+	}
+
+	rc, err := store.Get(ctx, "octocat/hello-world/c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(rc)
+	rc.Close()
+	if string(data) != "0123456789" {
+		t.Errorf("Want object data, got %q", data)
+	}
+
+	if _, err := store.Get(ctx, "octocat/hello-world/a"); err != ErrNotFound {
+		t.Errorf("Want ErrNotFound, got %v", err)
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	if got, want := escapePath("/bucket/octocat/hello world/go-linux+amd64~1"), "/bucket/octocat/hello%20world/go-linux%2Bamd64~1"; got != want {
+		t.Errorf("Want %s, got %s", want, got)
+	}
+}
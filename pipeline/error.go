@@ -36,3 +36,19 @@ type OomError struct {
 func (e *OomError) Error() string {
 	return fmt.Sprintf("%s : received oom kill", e.Name)
 }
+
+// A HealthError reports a service never became healthy.
+type HealthError struct {
+	Name string
+	Err  error
+}
+
+// Error returns the error message in string format.
+func (e *HealthError) Error() string {
+	return fmt.Sprintf("%s : service is unhealthy: %s", e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *HealthError) Unwrap() error {
+	return e.Err
+}
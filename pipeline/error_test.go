@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -24,3 +25,14 @@ func TestOomError(t *testing.T) {
 		t.Errorf("Want error message %q, got %q", want, got)
 	}
 }
+
+func TestHealthError(t *testing.T) {
+	err := HealthError{
+		Name: "database",
+		Err:  errors.New("healthcheck failed"),
+	}
+	got, want := err.Error(), "database : service is unhealthy: healthcheck failed"
+	if got != want {
+		t.Errorf("Want error message %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,283 @@
+// Package starlark evaluates pipeline configs written in starlark.
+//
+// A starlark config defines a main function receiving the build metadata
+// and returning the config:
+//
+//	def main(ctx):
+//	    return {
+//	        "pipeline": {
+//	            "build": {
+//	                "image": "golang",
+//	                "commands": ["go build"],
+//	            },
+//	        },
+//	    }
+//
+// Configs are evaluated in a sandbox, they can not load modules or access
+// files and the network. Recursion and while loops are not allowed, the
+// execution steps and the evaluation time are limited.
+package starlark
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"gopkg.in/yaml.v3"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend"
+)
+
+// Extension is the file extension of starlark configs.
+const Extension = ".star"
+
+// Limits limits the evaluation of a config.
+type Limits struct {
+	// Steps is the maximum number of execution steps.
+	Steps int
+	// Timeout is the maximum evaluation time.
+	Timeout time.Duration
+}
+
+// DefaultLimits are the limits of Eval.
+var DefaultLimits = Limits{
+	Steps:   1000000,
+	Timeout: time.Second * 5,
+}
+
+// IsConfig returns true if the file name is a starlark config.
+func IsConfig(name string) bool {
+	return strings.HasSuffix(name, Extension)
+}
+
+// Eval evaluates the named starlark config with the build metadata and
+// returns the config in yaml format.
+func Eval(name string, data []byte, metadata frontend.Metadata) ([]byte, error) {
+	return EvalWithLimits(name, data, metadata, DefaultLimits)
+}
+
+// EvalWithLimits evaluates the named starlark config like Eval with the
+// given limits.
+func EvalWithLimits(name string, data []byte, metadata frontend.Metadata, limits Limits) ([]byte, error) {
+	type result struct {
+		value starlark.Value
+		err   error
+	}
+	thread := newThread(name, limits.Steps)
+	done := make(chan result, 1)
+	go func() {
+		value, err := eval(thread, name, data, metadata, limits.Steps)
+		done <- result{value, err}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(limits.Timeout):
+		// stop the evaluation, the interpreter observes the cancellation
+		// on its next step
+		thread.Cancel("timeout")
+		return nil, fmt.Errorf("%s: evaluation exceeds the limit of %s", name, limits.Timeout)
+	}
+	if res.err != nil {
+		var evalErr *starlark.EvalError
+		if errors.As(res.err, &evalErr) {
+			return nil, fmt.Errorf("%s", evalErr.Backtrace())
+		}
+		return nil, res.err
+	}
+
+	node, err := toNode(res.value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: main returned an invalid config: %w", name, err)
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: main must return a dict, got %s", name, res.value.Type())
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// errMaxSteps is the cancel reason of evaluations exceeding the limit of
+// execution steps.
+const errMaxSteps = "evaluation exceeds the limit of execution steps"
+
+func newThread(name string, steps int) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: name,
+		Load: func(*starlark.Thread, string) (starlark.StringDict, error) {
+			return nil, fmt.Errorf("load is not allowed")
+		},
+		Print: func(*starlark.Thread, string) {},
+		OnMaxSteps: func(thread *starlark.Thread) {
+			thread.Cancel(errMaxSteps)
+		},
+	}
+	thread.SetMaxExecutionSteps(uint64(steps))
+	return thread
+}
+
+func eval(thread *starlark.Thread, name string, data []byte, metadata frontend.Metadata, steps int) (starlark.Value, error) {
+	predeclared := starlark.StringDict{
+		"struct": starlark.NewBuiltin("struct", starlarkstruct.Make),
+	}
+
+	globals, err := starlark.ExecFile(thread, name, data, predeclared)
+	if err != nil {
+		return nil, stepsError(thread, name, steps, err)
+	}
+	main, ok := globals["main"]
+	if !ok {
+		return nil, fmt.Errorf("%s: main function not found", name)
+	}
+	value, err := starlark.Call(thread, main, starlark.Tuple{toContext(metadata)}, nil)
+	if err != nil {
+		return nil, stepsError(thread, name, steps, err)
+	}
+	return value, nil
+}
+
+// stepsError replaces the error of evaluations stopped after exceeding
+// the limit of execution steps.
+func stepsError(thread *starlark.Thread, name string, steps int, err error) error {
+	if thread.ExecutionSteps() >= uint64(steps) {
+		return fmt.Errorf("%s: %s", name, errMaxSteps)
+	}
+	return err
+}
+
+// Limits of the conversion of the value returned by main. Values can
+// contain themselves and share values, the conversion stops at cycles and
+// values expanding to too many nodes.
+const (
+	maxNodeDepth = 100
+	maxNodes     = 1 << 20
+)
+
+// toNode converts the starlark value to a yaml node keeping the order of
+// dict keys.
+func toNode(value starlark.Value) (*yaml.Node, error) {
+	nodes := 0
+	return convertNode(value, 0, &nodes)
+}
+
+func convertNode(value starlark.Value, depth int, nodes *int) (*yaml.Node, error) {
+	if depth > maxNodeDepth {
+		return nil, fmt.Errorf("value exceeds the maximum depth of %d", maxNodeDepth)
+	}
+	*nodes++
+	if *nodes > maxNodes {
+		return nil, fmt.Errorf("value exceeds the maximum of %d elements", maxNodes)
+	}
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	case starlark.Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(bool(v))}, nil
+	case starlark.Int:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: v.String()}, nil
+	case starlark.Float:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: fmt.Sprint(float64(v))}, nil
+	case starlark.String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(v)}, nil
+	case *starlark.Dict:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, item := range v.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("dict keys must be strings, got %s", item[0].Type())
+			}
+			value, err := convertNode(item[1], depth+1, nodes)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(key)}, value)
+		}
+		return node, nil
+	case *starlarkstruct.Struct:
+		dict := starlark.StringDict{}
+		v.ToStringDict(dict)
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range dict.Keys() {
+			value, err := convertNode(dict[key], depth+1, nodes)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		}
+		return node, nil
+	case starlark.Indexable:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i := 0; i < v.Len(); i++ {
+			value, err := convertNode(v.Index(i), depth+1, nodes)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		return node, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", value.Type())
+}
+
+// toContext returns the build metadata passed to the main function.
+func toContext(m frontend.Metadata) starlark.Value {
+	return newStruct(starlark.StringDict{
+		"repo": newStruct(starlark.StringDict{
+			"name":           starlark.String(m.Repo.Name),
+			"link":           starlark.String(m.Repo.Link),
+			"remote":         starlark.String(m.Repo.Remote),
+			"private":        starlark.Bool(m.Repo.Private),
+			"default_branch": starlark.String(m.Repo.Branch),
+		}),
+		"build": newStruct(starlark.StringDict{
+			"number":  starlark.MakeInt64(m.Curr.Number),
+			"parent":  starlark.MakeInt64(m.Curr.Parent),
+			"created": starlark.MakeInt64(m.Curr.Created),
+			"event":   starlark.String(m.Curr.Event),
+			"link":    starlark.String(m.Curr.Link),
+			"target":  starlark.String(m.Curr.Target),
+			"trusted": starlark.Bool(m.Curr.Trusted),
+			"commit": newStruct(starlark.StringDict{
+				"sha":     starlark.String(m.Curr.Commit.Sha),
+				"ref":     starlark.String(m.Curr.Commit.Ref),
+				"refspec": starlark.String(m.Curr.Commit.Refspec),
+				"branch":  starlark.String(m.Curr.Commit.Branch),
+				"message": starlark.String(m.Curr.Commit.Message),
+				"author": newStruct(starlark.StringDict{
+					"name":   starlark.String(m.Curr.Commit.Author.Name),
+					"email":  starlark.String(m.Curr.Commit.Author.Email),
+					"avatar": starlark.String(m.Curr.Commit.Author.Avatar),
+				}),
+				"changed_files": toList(m.Curr.Commit.ChangedFiles),
+			}),
+		}),
+		"sys": newStruct(starlark.StringDict{
+			"name":    starlark.String(m.Sys.Name),
+			"host":    starlark.String(m.Sys.Host),
+			"link":    starlark.String(m.Sys.Link),
+			"version": starlark.String(m.Sys.Version),
+		}),
+	})
+}
+
+func newStruct(fields starlark.StringDict) *starlarkstruct.Struct {
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, fields)
+}
+
+func toList(values []string) *starlark.List {
+	list := make([]starlark.Value, 0, len(values))
+	for _, value := range values {
+		list = append(list, starlark.String(value))
+	}
+	return starlark.NewList(list)
+}
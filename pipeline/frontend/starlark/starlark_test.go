@@ -0,0 +1,141 @@
+package starlark
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend"
+)
+
+func TestEval(t *testing.T) {
+	metadata := frontend.Metadata{
+		Repo: frontend.Repo{Name: "octocat/hello-world"},
+		Curr: frontend.Build{
+			Event:  "push",
+			Commit: frontend.Commit{Branch: "main"},
+		},
+	}
+	data, err := Eval(".woodpecker/build.star", []byte(`
+def step(version):
+    return {
+        "image": "golang:%s" % version,
+        "commands": ["go test ./..."],
+    }
+
+def main(ctx):
+    pipeline = {}
+    for version in ["1.16", "1.17"]:
+        pipeline["test-" + version] = step(version)
+    if ctx.build.event == "push" and ctx.build.commit.branch == "main":
+        pipeline["publish"] = {"image": "plugins/docker", "repo": ctx.repo.name, "dry_run": False}
+    return {"pipeline": pipeline}
+`), metadata)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `pipeline:
+  test-1.16:
+    image: golang:1.16
+    commands:
+      - go test ./...
+  test-1.17:
+    image: golang:1.17
+    commands:
+      - go test ./...
+  publish:
+    image: plugins/docker
+    repo: octocat/hello-world
+    dry_run: false
+`, string(data))
+}
+
+func TestEvalErr(t *testing.T) {
+	testdata := []struct {
+		data string
+		err  string
+	}{
+		{
+			data: `x = 1`,
+			err:  "build.star: main function not found",
+		},
+		{
+			data: "def main(ctx):\n    return [1]\n",
+			err:  "build.star: main must return a dict, got list",
+		},
+		{
+			data: "def main(ctx):\n    return {1: 1}\n",
+			err:  "build.star: main returned an invalid config: dict keys must be strings, got int",
+		},
+		{
+			data: "load('other.star', 'x')\n",
+			err:  "cannot load other.star: load is not allowed",
+		},
+		{
+			data: "def main(ctx):\n    return {'x': [i for i in range(1000) for j in range(1000)]}\n",
+			err:  "build.star: evaluation exceeds the limit of execution steps",
+		},
+		{
+			data: "def main(ctx):\n    return {'x': [i for i in [1] * 10000000]}\n",
+			err:  "build.star: evaluation exceeds the limit of execution steps",
+		},
+	}
+
+	for _, test := range testdata {
+		_, err := Eval("build.star", []byte(test.data), frontend.Metadata{})
+		if assert.Error(t, err, test.data) {
+			assert.Contains(t, err.Error(), test.err, test.data)
+		}
+	}
+}
+
+func TestEvalCycle(t *testing.T) {
+	testdata := []struct {
+		data string
+		err  string
+	}{
+		{
+			data: "def main(ctx):\n    l = []\n    l.append(l)\n    return {'x': l}\n",
+			err:  "build.star: main returned an invalid config: value exceeds the maximum depth of 100",
+		},
+		{
+			data: "def main(ctx):\n    d = {}\n    d['d'] = d\n    return d\n",
+			err:  "build.star: main returned an invalid config: value exceeds the maximum depth of 100",
+		},
+		{
+			data: "def main(ctx):\n    l = [1]\n    for i in range(40):\n        l = [l, l]\n    return {'x': l}\n",
+			err:  "build.star: main returned an invalid config: value exceeds the maximum of 1048576 elements",
+		},
+	}
+
+	for _, test := range testdata {
+		_, err := Eval("build.star", []byte(test.data), frontend.Metadata{})
+		assert.EqualError(t, err, test.err, test.data)
+	}
+}
+
+func TestEvalRecursion(t *testing.T) {
+	_, err := Eval("build.star", []byte("def main(ctx):\n    return main(ctx)\n"), frontend.Metadata{})
+	assert.Error(t, err)
+}
+
+func TestEvalTimeout(t *testing.T) {
+	limits := Limits{Steps: 1 << 24, Timeout: time.Millisecond}
+	_, err := EvalWithLimits("build.star", []byte("def main(ctx):\n    return {'x': [i for i in range(1 << 24)]}\n"), frontend.Metadata{}, limits)
+	assert.EqualError(t, err, "build.star: evaluation exceeds the limit of 1ms")
+}
+
+func TestEvalTimeoutCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+	limits := Limits{Steps: 1 << 40, Timeout: time.Millisecond * 10}
+	_, err := EvalWithLimits("build.star", []byte("def main(ctx):\n    return {'x': [i for i in range(1 << 40)]}\n"), frontend.Metadata{}, limits)
+	assert.Error(t, err)
+
+	// the evaluation is cancelled and its goroutine exits
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
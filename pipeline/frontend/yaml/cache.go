@@ -0,0 +1,24 @@
+package yaml
+
+import (
+	"gopkg.in/yaml.v3"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/types"
+)
+
+// Cache defines the paths cached between builds.
+type Cache struct {
+	Key         string              `yaml:"key,omitempty"`
+	RestoreKeys types.Stringorslice `yaml:"restore_keys,omitempty"`
+	Paths       types.Stringorslice `yaml:"paths,omitempty"`
+}
+
+// UnmarshalYAML implements the Unmarshaller interface. A path or a
+// list of paths is accepted as a shorthand for a cache without key.
+func (c *Cache) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return value.Decode(&c.Paths)
+	}
+	type cache Cache
+	return value.Decode((*cache)(c))
+}
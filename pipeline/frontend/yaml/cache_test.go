@@ -0,0 +1,51 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kr/pretty"
+	"gopkg.in/yaml.v3"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/types"
+)
+
+func TestUnmarshalCache(t *testing.T) {
+	testdata := []struct {
+		from string
+		want Cache
+	}{
+		{
+			from: "node_modules",
+			want: Cache{
+				Paths: types.Stringorslice{"node_modules"},
+			},
+		},
+		{
+			from: "[ node_modules, .npm ]",
+			want: Cache{
+				Paths: types.Stringorslice{"node_modules", ".npm"},
+			},
+		},
+		{
+			from: "{ key: 'npm-{{ checksum \"package-lock.json\" }}', restore_keys: npm-, paths: [ node_modules ] }",
+			want: Cache{
+				Key:         `npm-{{ checksum "package-lock.json" }}`,
+				RestoreKeys: types.Stringorslice{"npm-"},
+				Paths:       types.Stringorslice{"node_modules"},
+			},
+		},
+	}
+
+	for _, test := range testdata {
+		in := []byte(test.from)
+		got := Cache{}
+		err := yaml.Unmarshal(in, &got)
+		if err != nil {
+			t.Error(err)
+		} else if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("problem parsing cache %q", test.from)
+			pretty.Ldiff(t, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package yaml
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Clone defines the clone section, either the steps replacing the
+// default clone step or the settings of the default clone step.
+type Clone struct {
+	Containers []*Container
+	Depth      *int  `yaml:"depth,omitempty"`
+	LFS        *bool `yaml:"lfs,omitempty"`
+	Submodules bool  `yaml:"submodules,omitempty"`
+}
+
+// cloneSettings are the keys of the settings of the default clone step.
+var cloneSettings = map[string]bool{
+	"depth":      true,
+	"lfs":        true,
+	"submodules": true,
+}
+
+// UnmarshalYAML implements the Unmarshaller interface. A mapping of
+// the clone settings to scalar values configures the default clone
+// step, any other mapping defines clone steps.
+func (c *Clone) UnmarshalYAML(value *yaml.Node) error {
+	settings := 0
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if cloneSettings[value.Content[i].Value] && value.Content[i+1].Kind == yaml.ScalarNode {
+			settings++
+		}
+	}
+	switch {
+	case settings == 0:
+		containers := Containers{}
+		if err := value.Decode(&containers); err != nil {
+			return err
+		}
+		c.Containers = containers.Containers
+		return nil
+	case settings*2 != len(value.Content):
+		return fmt.Errorf("clone settings cannot be combined with clone steps")
+	}
+	type clone Clone
+	return value.Decode((*clone)(c))
+}
@@ -0,0 +1,65 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kr/pretty"
+	"gopkg.in/yaml.v3"
+)
+
+func TestUnmarshalClone(t *testing.T) {
+	lfs := false
+	depth := 50
+	full := 0
+	testdata := []struct {
+		from string
+		want Clone
+	}{
+		{
+			from: "{ depth: 50, lfs: false, submodules: true }",
+			want: Clone{
+				Depth:      &depth,
+				LFS:        &lfs,
+				Submodules: true,
+			},
+		},
+		{
+			from: "{ depth: 0 }",
+			want: Clone{
+				Depth: &full,
+			},
+		},
+		{
+			from: "{ git: { image: plugins/git } }",
+			want: Clone{
+				Containers: []*Container{{Name: "git", Image: "plugins/git"}},
+			},
+		},
+		{
+			from: "{ depth: { image: plugins/git } }",
+			want: Clone{
+				Containers: []*Container{{Name: "depth", Image: "plugins/git"}},
+			},
+		},
+	}
+
+	for _, test := range testdata {
+		got := Clone{}
+		err := yaml.Unmarshal([]byte(test.from), &got)
+		if err != nil {
+			t.Error(err)
+		} else if !reflect.DeepEqual(test.want, got) {
+			t.Errorf("problem parsing clone %q", test.from)
+			pretty.Ldiff(t, test.want, got)
+		}
+	}
+}
+
+func TestUnmarshalCloneErr(t *testing.T) {
+	got := Clone{}
+	err := yaml.Unmarshal([]byte("{ depth: 1, git: { image: plugins/git } }"), &got)
+	if err == nil {
+		t.Errorf("Expected error combining clone settings and clone steps")
+	}
+}
@@ -1,15 +1,94 @@
 package compiler
 
 import (
+	"fmt"
 	"path"
 	"strings"
 
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/types"
 )
 
+// setupCache adds the stage restoring the cache after the clone stage.
+func (c *Compiler) setupCache(conf *yaml.Config, ir *backend.Config) {
+	if c.local || len(conf.Cache.Paths) == 0 {
+		return
+	}
+	ir.Stages = append(ir.Stages, c.createCacheStage("restore_cache", conf.Cache, false))
+}
+
+// setupCacheRebuild adds the stage saving the cache after all pipeline
+// steps succeeded. Pull requests do not save the cache to prevent them
+// from poisoning it.
+func (c *Compiler) setupCacheRebuild(conf *yaml.Config, ir *backend.Config) {
+	if c.local || len(conf.Cache.Paths) == 0 || c.metadata.Curr.Event == "pull_request" {
+		return
+	}
+	ir.Stages = append(ir.Stages, c.createCacheStage("rebuild_cache", conf.Cache, true))
+}
+
+func (c *Compiler) createCacheStage(alias string, cache yaml.Cache, save bool) *backend.Stage {
+	if c.cacher != nil {
+		return c.createCacherStage(alias, cache, save)
+	}
+
+	// the cache step only mounts the workspace while the agent restores or
+	// saves the cache. It uses the clone image which is already pulled.
+	container := &yaml.Container{
+		Name:  alias,
+		Image: c.cloneImage(),
+	}
+	name := fmt.Sprintf("%s_%s", c.prefix, alias)
+	step := c.createProcess(name, container, "cache")
+	step.Entrypoint = []string{"/bin/sh", "-c"}
+	step.Command = []string{"true"}
+	step.Cache = &backend.Cache{
+		Scope:       c.metadata.Repo.Name,
+		Key:         cache.Key,
+		RestoreKeys: cache.RestoreKeys,
+		Paths:       cache.Paths,
+		Save:        save,
+	}
+
+	// without key the cache is shared by all builds of a branch and
+	// falls back to the cache of the default branch.
+	if step.Cache.Key == "" {
+		step.Cache.Key = c.metadata.Curr.Commit.Branch
+		if len(step.Cache.RestoreKeys) == 0 && c.metadata.Repo.Branch != step.Cache.Key {
+			step.Cache.RestoreKeys = []string{c.metadata.Repo.Branch}
+		}
+	}
+
+	stage := new(backend.Stage)
+	stage.Name = name
+	stage.Alias = alias
+	stage.Steps = append(stage.Steps, step)
+	return stage
+}
+
+// createCacherStage creates the cache stage of the deprecated cacher.
+func (c *Compiler) createCacherStage(alias string, cache yaml.Cache, save bool) *backend.Stage {
+	container := c.cacher.Restore(c.metadata.Repo.Name, c.metadata.Curr.Commit.Branch, cache.Paths)
+	if save {
+		container = c.cacher.Rebuild(c.metadata.Repo.Name, c.metadata.Curr.Commit.Branch, cache.Paths)
+	}
+	name := fmt.Sprintf("%s_%s", c.prefix, alias)
+	step := c.createProcess(name, container, "cache")
+
+	stage := new(backend.Stage)
+	stage.Name = name
+	stage.Alias = alias
+	stage.Steps = append(stage.Steps, step)
+	return stage
+}
+
 // Cacher defines a compiler transform that can be used
 // to implement default caching for a repository.
+//
+// Deprecated: the cache is restored and saved by the agent, see
+// backend.Cache. A configured cacher replaces the agent cache with the
+// containers of the cacher.
 type Cacher interface {
 	Restore(repo, branch string, mounts []string) *yaml.Container
 	Rebuild(repo, branch string, mounts []string) *yaml.Container
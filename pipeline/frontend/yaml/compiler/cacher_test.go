@@ -0,0 +1,49 @@
+package compiler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/types"
+)
+
+func TestCompileCache(t *testing.T) {
+	metadata := frontend.Metadata{}
+	metadata.Repo.Name = "octocat/hello-world"
+	metadata.Repo.Branch = "main"
+	metadata.Curr.Event = "push"
+	metadata.Curr.Commit.Branch = "feature"
+
+	conf := &yaml.Config{
+		Cache: yaml.Cache{Paths: types.Stringorslice{"node_modules"}},
+	}
+	ir := New(WithMetadata(metadata), WithPrefix("test")).Compile(conf)
+
+	var caches []*backend.Cache
+	for _, stage := range ir.Stages {
+		for _, step := range stage.Steps {
+			if step.Cache != nil {
+				caches = append(caches, step.Cache)
+			}
+		}
+	}
+	want := []*backend.Cache{
+		{Scope: "octocat/hello-world", Key: "feature", RestoreKeys: []string{"main"}, Paths: []string{"node_modules"}},
+		{Scope: "octocat/hello-world", Key: "feature", RestoreKeys: []string{"main"}, Paths: []string{"node_modules"}, Save: true},
+	}
+	if !reflect.DeepEqual(caches, want) {
+		t.Errorf("Want cache steps %v, got %v", want, caches)
+	}
+
+	// pull requests do not save the cache.
+	metadata.Curr.Event = "pull_request"
+	ir = New(WithMetadata(metadata), WithPrefix("test")).Compile(conf)
+	for _, stage := range ir.Stages {
+		if stage.Alias == "rebuild_cache" {
+			t.Errorf("Want no rebuild_cache stage for pull requests")
+		}
+	}
+}
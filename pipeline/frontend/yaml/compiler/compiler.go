@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend"
@@ -34,21 +35,28 @@ type ResourceLimit struct {
 	CPUSet       string
 }
 
+// DefaultClone defines the image and settings of the default clone step.
+type DefaultClone struct {
+	Image    string
+	Settings map[string]interface{}
+}
+
 // Compiler compiles the yaml
 type Compiler struct {
-	local      bool
-	escalated  []string
-	prefix     string
-	volumes    []string
-	networks   []string
-	env        map[string]string
-	base       string
-	path       string
-	metadata   frontend.Metadata
-	registries []Registry
-	secrets    map[string]Secret
-	cacher     Cacher
-	reslimit   ResourceLimit
+	local        bool
+	escalated    []string
+	prefix       string
+	volumes      []string
+	networks     []string
+	env          map[string]string
+	base         string
+	path         string
+	metadata     frontend.Metadata
+	registries   []Registry
+	secrets      map[string]Secret
+	reslimit     ResourceLimit
+	defaultClone DefaultClone
+	cacher       Cacher
 }
 
 // New creates a new Compiler with options.
@@ -109,15 +117,8 @@ func (c *Compiler) Compile(conf *yaml.Config) *backend.Config {
 	if !c.local && len(conf.Clone.Containers) == 0 && !conf.SkipClone {
 		container := &yaml.Container{
 			Name:  "clone",
-			Image: "woodpeckerci/plugin-git:latest",
-			Vargs: map[string]interface{}{"depth": "0"},
-		}
-		// TODO: migrate to woodpeckerci/plugin-git:latest (multi arch)
-		switch c.metadata.Sys.Arch {
-		case "linux/arm":
-			container.Image = "plugins/git:linux-arm"
-		case "linux/arm64":
-			container.Image = "plugins/git:linux-arm64"
+			Image: c.cloneImage(),
+			Vargs: c.cloneSettings(conf.Clone),
 		}
 		name := fmt.Sprintf("%s_clone", c.prefix)
 		step := c.createProcess(name, container, "clone")
@@ -125,6 +126,7 @@ func (c *Compiler) Compile(conf *yaml.Config) *backend.Config {
 		stage := new(backend.Stage)
 		stage.Name = name
 		stage.Alias = "clone"
+		stage.DefaultClone = true
 		stage.Steps = append(stage.Steps, step)
 
 		config.Stages = append(config.Stages, stage)
@@ -198,36 +200,36 @@ func (c *Compiler) Compile(conf *yaml.Config) *backend.Config {
 	return config
 }
 
-func (c *Compiler) setupCache(conf *yaml.Config, ir *backend.Config) {
-	if c.local || len(conf.Cache) == 0 || c.cacher == nil {
-		return
+// cloneImage returns the image of the default clone step.
+func (c *Compiler) cloneImage() string {
+	if c.defaultClone.Image != "" {
+		return c.defaultClone.Image
 	}
-
-	container := c.cacher.Restore(c.metadata.Repo.Name, c.metadata.Curr.Commit.Branch, conf.Cache)
-	name := fmt.Sprintf("%s_restore_cache", c.prefix)
-	step := c.createProcess(name, container, "cache")
-
-	stage := new(backend.Stage)
-	stage.Name = name
-	stage.Alias = "restore_cache"
-	stage.Steps = append(stage.Steps, step)
-
-	ir.Stages = append(ir.Stages, stage)
+	// TODO: migrate to woodpeckerci/plugin-git:latest (multi arch)
+	switch c.metadata.Sys.Arch {
+	case "linux/arm":
+		return "plugins/git:linux-arm"
+	case "linux/arm64":
+		return "plugins/git:linux-arm64"
+	}
+	return "woodpeckerci/plugin-git:latest"
 }
 
-func (c *Compiler) setupCacheRebuild(conf *yaml.Config, ir *backend.Config) {
-	if c.local || len(conf.Cache) == 0 || c.metadata.Curr.Event != "push" || c.cacher == nil {
-		return
+// cloneSettings returns the settings of the default clone step. The
+// settings of the pipeline override the default settings.
+func (c *Compiler) cloneSettings(clone yaml.Clone) map[string]interface{} {
+	settings := map[string]interface{}{"depth": "0"}
+	for k, v := range c.defaultClone.Settings {
+		settings[k] = v
 	}
-	container := c.cacher.Rebuild(c.metadata.Repo.Name, c.metadata.Curr.Commit.Branch, conf.Cache)
-
-	name := fmt.Sprintf("%s_rebuild_cache", c.prefix)
-	step := c.createProcess(name, container, "cache")
-
-	stage := new(backend.Stage)
-	stage.Name = name
-	stage.Alias = "rebuild_cache"
-	stage.Steps = append(stage.Steps, step)
-
-	ir.Stages = append(ir.Stages, stage)
+	if clone.Depth != nil {
+		settings["depth"] = strconv.Itoa(*clone.Depth)
+	}
+	if clone.LFS != nil {
+		settings["lfs"] = *clone.LFS
+	}
+	if clone.Submodules {
+		settings["recursive"] = true
+	}
+	return settings
 }
@@ -1 +1,81 @@
 package compiler
+
+import (
+	"testing"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml"
+)
+
+func TestCompileDefaultClone(t *testing.T) {
+	lfs := false
+	depth := 50
+	conf := &yaml.Config{
+		Clone: yaml.Clone{Depth: &depth, LFS: &lfs, Submodules: true},
+	}
+	ir := New(
+		WithPrefix("test"),
+		WithDefaultClone("registry.example.com/plugin-git:v1", map[string]interface{}{
+			"depth": "1",
+			"tags":  true,
+		}),
+	).Compile(conf)
+
+	step := ir.Stages[0].Steps[0]
+	if step.Image != "registry.example.com/plugin-git:v1" {
+		t.Errorf("Want default clone image, got %s", step.Image)
+	}
+	want := map[string]string{
+		"PLUGIN_DEPTH":     "50",
+		"PLUGIN_LFS":       "false",
+		"PLUGIN_RECURSIVE": "true",
+		"PLUGIN_TAGS":      "true",
+	}
+	for k, v := range want {
+		if got := step.Environment[k]; got != v {
+			t.Errorf("Want %s=%s, got %q", k, v, got)
+		}
+	}
+}
+
+func TestCompileDefaultCloneFullDepth(t *testing.T) {
+	depth := 0
+	conf := &yaml.Config{
+		Clone: yaml.Clone{Depth: &depth},
+	}
+	ir := New(
+		WithPrefix("test"),
+		WithDefaultClone("", map[string]interface{}{"depth": "1"}),
+	).Compile(conf)
+
+	step := ir.Stages[0].Steps[0]
+	if step.Environment["PLUGIN_DEPTH"] != "0" {
+		t.Errorf("Want clone depth 0 to override the default depth, got %q", step.Environment["PLUGIN_DEPTH"])
+	}
+}
+
+func TestCompileDefaultCloneImage(t *testing.T) {
+	ir := New(WithPrefix("test")).Compile(&yaml.Config{})
+	step := ir.Stages[0].Steps[0]
+	if step.Image != "docker.io/woodpeckerci/plugin-git:latest" {
+		t.Errorf("Want built-in clone image, got %s", step.Image)
+	}
+	if step.Environment["PLUGIN_DEPTH"] != "0" {
+		t.Errorf("Want default clone depth 0, got %q", step.Environment["PLUGIN_DEPTH"])
+	}
+}
+
+func TestCompileDefaultCloneMarker(t *testing.T) {
+	ir := New(WithPrefix("test")).Compile(&yaml.Config{})
+	if !ir.Stages[0].DefaultClone {
+		t.Errorf("Want the default clone stage to be marked")
+	}
+
+	// custom clone steps named clone are not the default clone step
+	conf := &yaml.Config{
+		Clone: yaml.Clone{Containers: []*yaml.Container{{Name: "clone", Image: "alpine"}}},
+	}
+	ir = New(WithPrefix("test")).Compile(conf)
+	if ir.Stages[0].Alias != "clone" || ir.Stages[0].DefaultClone {
+		t.Errorf("Want custom clone stage %s not to be marked", ir.Stages[0].Name)
+	}
+}
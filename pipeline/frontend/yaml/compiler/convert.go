@@ -143,6 +143,16 @@ func (c *Compiler) createProcess(name string, container *yaml.Container, section
 		cpuSet = c.reslimit.CPUSet
 	}
 
+	var healthcheck *backend.Healthcheck
+	if container.Healthcheck != nil {
+		healthcheck = &backend.Healthcheck{
+			Command:  container.Healthcheck.Command,
+			Interval: container.Healthcheck.Interval,
+			Timeout:  container.Healthcheck.Timeout,
+			Retries:  container.Healthcheck.Retries,
+		}
+	}
+
 	return &backend.Step{
 		Name:         name,
 		Alias:        container.Name,
@@ -176,5 +186,6 @@ func (c *Compiler) createProcess(name string, container *yaml.Container, section
 			container.Constraints.Status.Match("failure"),
 		NetworkMode: network_mode,
 		IpcMode:     ipc_mode,
+		Healthcheck: healthcheck,
 	}
 }
@@ -146,6 +146,8 @@ func WithEnviron(env map[string]string) Option {
 }
 
 // WithCacher configures the compiler with default cache settings.
+//
+// Deprecated: the cache is restored and saved by the agent.
 func WithCacher(cacher Cacher) Option {
 	return func(compiler *Compiler) {
 		compiler.cacher = cacher
@@ -154,6 +156,8 @@ func WithCacher(cacher Cacher) Option {
 
 // WithVolumeCacher configures the compiler with default local volume
 // caching enabled.
+//
+// Deprecated: the cache is restored and saved by the agent.
 func WithVolumeCacher(base string) Option {
 	return func(compiler *Compiler) {
 		compiler.cacher = &volumeCacher{base: base}
@@ -162,6 +166,8 @@ func WithVolumeCacher(base string) Option {
 
 // WithS3Cacher configures the compiler with default amazon s3
 // caching enabled.
+//
+// Deprecated: the cache is restored and saved by the agent.
 func WithS3Cacher(access, secret, region, bucket string) Option {
 	return func(compiler *Compiler) {
 		compiler.cacher = &s3Cacher{
@@ -212,6 +218,17 @@ func WithResourceLimit(swap, mem, shmsize, cpuQuota, cpuShares int64, cpuSet str
 	}
 }
 
+// WithDefaultClone configures the compiler with the image and settings
+// of the default clone step. An empty image keeps the built-in image.
+func WithDefaultClone(image string, settings map[string]interface{}) Option {
+	return func(compiler *Compiler) {
+		compiler.defaultClone = DefaultClone{
+			Image:    image,
+			Settings: settings,
+		}
+	}
+}
+
 // TODO(bradrydzewski) consider an alternate approach to
 // WithProxy where the proxy strings are passed directly
 // to the function as named parameters.
@@ -13,11 +13,12 @@ import (
 type (
 	// Config defines a pipeline configuration.
 	Config struct {
-		Cache     types.Stringorslice
+		Cache     Cache
 		Platform  string
 		Branches  Constraint
+		When      Constraints
 		Workspace Workspace
-		Clone     Containers
+		Clone     Clone
 		Pipeline  Containers
 		Services  Containers
 		Networks  Networks
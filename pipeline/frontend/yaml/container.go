@@ -2,6 +2,7 @@ package yaml
 
 import (
 	"fmt"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -16,6 +17,14 @@ type (
 		Email    string
 	}
 
+	// Healthcheck defines the command reporting a service healthy.
+	Healthcheck struct {
+		Command  types.Stringorslice `yaml:"command,omitempty"`
+		Interval time.Duration       `yaml:"interval,omitempty"`
+		Timeout  time.Duration       `yaml:"timeout,omitempty"`
+		Retries  int                 `yaml:"retries,omitempty"`
+	}
+
 	// Containers denotes an ordered collection of containers.
 	Containers struct {
 		Containers []*Container
@@ -40,6 +49,7 @@ type (
 		Environment   types.SliceorMap       `yaml:"environment,omitempty"`
 		ExtraHosts    []string               `yaml:"extra_hosts,omitempty"`
 		Group         string                 `yaml:"group,omitempty"`
+		Healthcheck   *Healthcheck           `yaml:"healthcheck,omitempty"`
 		Image         string                 `yaml:"image,omitempty"`
 		Isolation     string                 `yaml:"isolation,omitempty"`
 		Labels        types.SliceorMap       `yaml:"labels,omitempty"`
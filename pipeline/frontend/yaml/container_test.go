@@ -3,6 +3,7 @@ package yaml
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/kr/pretty"
 	"gopkg.in/yaml.v3"
@@ -33,6 +34,11 @@ entrypoint: /code/entrypoint.sh
 environment:
   - RACK_ENV=development
   - SHOW=true
+healthcheck:
+  command: pg_isready
+  interval: 5s
+  timeout: 2s
+  retries: 10
 extra_hosts:
  - somehost:162.242.195.82
  - otherhost:50.31.209.229
@@ -106,6 +112,12 @@ func TestUnmarshalContainer(t *testing.T) {
 				{Source: "/etc/configs", Destination: "/etc/configs/", AccessMode: "ro"},
 			},
 		},
+		Healthcheck: &Healthcheck{
+			Command:  types.Stringorslice{"pg_isready"},
+			Interval: 5 * time.Second,
+			Timeout:  2 * time.Second,
+			Retries:  10,
+		},
 		Constraints: Constraints{
 			Branch: Constraint{
 				Include: []string{"master"},
@@ -0,0 +1,441 @@
+// Package include resolves the includes and extended steps of pipeline
+// configs.
+//
+// The files listed in the top-level include section are merged into the
+// config in order, the including config overrides them. Steps can extend
+// another step of the same section, steps whose name starts with a dot
+// are only used as base of other steps and removed from the config:
+//
+//	include:
+//	  - .woodpecker/common.yml
+//	  - repo: org/templates
+//	    path: go.yml
+//	  - template: go
+//
+//	pipeline:
+//	  test:
+//	    extends: .go-test
+package include
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxDepth is the maximum depth of nested includes.
+const maxDepth = 10
+
+// maxNodes is the maximum number of nodes of a config with expanded
+// aliases, maxNodeDepth the maximum nesting of its nodes.
+const (
+	maxNodes     = 1 << 20
+	maxNodeDepth = 1000
+)
+
+// sections are the config sections containing steps.
+var sections = []string{"clone", "pipeline", "services"}
+
+// Source identifies an included config file. Without repo and template
+// the path is relative to the root of the repository of the including
+// config.
+type Source struct {
+	Repo     string `yaml:"repo,omitempty"`
+	Path     string `yaml:"path,omitempty"`
+	Ref      string `yaml:"ref,omitempty"`
+	Template string `yaml:"template,omitempty"`
+}
+
+// String returns the source in the format used in include chains.
+func (s Source) String() string {
+	switch {
+	case s.Template != "":
+		return "template " + s.Template
+	case s.Repo != "" && s.Ref != "":
+		return s.Repo + ":" + s.Path + "@" + s.Ref
+	case s.Repo != "":
+		return s.Repo + ":" + s.Path
+	}
+	return s.Path
+}
+
+// UnmarshalYAML implements the Unmarshaller interface. A string is
+// accepted as a shorthand for a path of the same repository.
+func (s *Source) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		s.Path = value.Value
+		return nil
+	}
+	type source Source
+	if err := value.Decode((*source)(s)); err != nil {
+		return err
+	}
+	if s.Path == "" && s.Template == "" {
+		return fmt.Errorf("include requires a path or a template")
+	}
+	return nil
+}
+
+// Fetcher fetches the content of included config files.
+type Fetcher interface {
+	Fetch(ctx context.Context, source Source) ([]byte, error)
+}
+
+// originComment prefixes the comment recording the include chain of
+// included steps in the resolved config.
+const originComment = "# included from "
+
+// Config is a config with resolved includes.
+type Config struct {
+	// Data is the resolved config without includes and extends.
+	Data []byte
+
+	origins map[string]string
+}
+
+// Origin returns the include chain of the file defining the step, or an
+// empty string if the step is defined by the config itself.
+func (c *Config) Origin(step string) string {
+	return c.origins[step]
+}
+
+// Resolve resolves the includes and extended steps of the named config.
+// Configs without includes and extends are returned unchanged, resolving
+// a resolved config again keeps the origins of its steps. Errors report
+// the include chain of the failing file. The fetcher may be nil if the
+// config must not include files.
+func Resolve(ctx context.Context, fetcher Fetcher, name string, data []byte) (*Config, error) {
+	doc := new(yaml.Node)
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &Config{Data: data}, nil
+	}
+	if !needsResolve(doc.Content[0]) {
+		return &Config{Data: data, origins: origins(doc.Content[0])}, nil
+	}
+
+	r := &resolver{
+		ctx:     ctx,
+		fetcher: fetcher,
+	}
+	root, err := expandAliases(doc.Content[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	root, err = r.resolve(root, []string{name}, Source{})
+	if err != nil {
+		return nil, err
+	}
+	if err := extendSteps(root); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	return &Config{Data: buf.Bytes(), origins: origins(root)}, nil
+}
+
+type resolver struct {
+	ctx     context.Context
+	fetcher Fetcher
+}
+
+// resolve merges the includes of the config node into it. The chain
+// lists the names of the including files, the parent is the source of
+// the config.
+func (r *resolver) resolve(node *yaml.Node, chain []string, parent Source) (*yaml.Node, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: config is not a mapping", strings.Join(chain, " -> "))
+	}
+	value := mappingValue(node, "include")
+	if value == nil {
+		return node, nil
+	}
+	removeKey(node, "include")
+
+	var sources []Source
+	if err := value.Decode(&sources); err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.Join(chain, " -> "), err)
+	}
+
+	base := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, source := range sources {
+		// paths of included files of another repository are relative to
+		// that repository.
+		if source.Repo == "" && source.Template == "" {
+			source.Repo, source.Ref = parent.Repo, parent.Ref
+		}
+		included, err := r.include(source, chain)
+		if err != nil {
+			return nil, err
+		}
+		merge(base, included)
+	}
+	merge(base, node)
+	return base, nil
+}
+
+// include fetches and resolves the included source.
+func (r *resolver) include(source Source, chain []string) (*yaml.Node, error) {
+	chain = append(chain[:len(chain):len(chain)], source.String())
+	for _, name := range chain[:len(chain)-1] {
+		if name == source.String() {
+			return nil, fmt.Errorf("%s: include cycle", strings.Join(chain, " -> "))
+		}
+	}
+	if len(chain) > maxDepth {
+		return nil, fmt.Errorf("%s: includes are nested deeper than %d levels", strings.Join(chain, " -> "), maxDepth)
+	}
+
+	if r.fetcher == nil {
+		return nil, fmt.Errorf("%s: includes are not supported", strings.Join(chain, " -> "))
+	}
+	data, err := r.fetcher.Fetch(r.ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.Join(chain, " -> "), err)
+	}
+	doc := new(yaml.Node)
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.Join(chain, " -> "), err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+
+	node, err := expandAliases(doc.Content[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.Join(chain, " -> "), err)
+	}
+	node, err = r.resolve(node, chain, source)
+	if err != nil {
+		return nil, err
+	}
+	// steps of nested includes keep the longer chain.
+	for _, section := range sections {
+		steps := mappingValue(node, section)
+		if steps == nil || steps.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i < len(steps.Content); i += 2 {
+			if origin(steps.Content[i]) == "" {
+				steps.Content[i].HeadComment = originComment + strings.Join(chain, " -> ")
+			}
+		}
+	}
+	return node, nil
+}
+
+// origins returns the include chains of the steps of the resolved config.
+func origins(root *yaml.Node) map[string]string {
+	origins := map[string]string{}
+	for _, section := range sections {
+		steps := mappingValue(root, section)
+		if steps == nil || steps.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i < len(steps.Content); i += 2 {
+			if origin := origin(steps.Content[i]); origin != "" {
+				origins[steps.Content[i].Value] = origin
+			}
+		}
+	}
+	return origins
+}
+
+// origin returns the include chain recorded in the comment of the key.
+func origin(key *yaml.Node) string {
+	for _, line := range strings.Split(key.HeadComment, "\n") {
+		if strings.HasPrefix(line, originComment) {
+			return strings.TrimPrefix(line, originComment)
+		}
+	}
+	return ""
+}
+
+// needsResolve returns true if the config has includes, extended or
+// hidden steps.
+func needsResolve(root *yaml.Node) bool {
+	if root.Kind != yaml.MappingNode {
+		return false
+	}
+	if mappingValue(root, "include") != nil {
+		return true
+	}
+	for _, section := range sections {
+		steps := mappingValue(root, section)
+		if steps == nil || steps.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(steps.Content); i += 2 {
+			if strings.HasPrefix(steps.Content[i].Value, ".") || mappingValue(steps.Content[i+1], "extends") != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extendSteps merges the steps into the steps they extend and removes
+// the hidden steps.
+func extendSteps(root *yaml.Node) error {
+	for _, section := range sections {
+		steps := mappingValue(root, section)
+		if steps == nil || steps.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(steps.Content); i += 2 {
+			key := steps.Content[i]
+			step, base, err := extendStep(steps, key.Value, nil)
+			if err != nil {
+				return err
+			}
+			steps.Content[i+1] = step
+			// a step extending an included step reports its include chain.
+			if base != nil && origin(key) == "" && origin(base) != "" {
+				key.HeadComment = originComment + origin(base)
+			}
+		}
+		for i := 0; i+1 < len(steps.Content); {
+			if strings.HasPrefix(steps.Content[i].Value, ".") {
+				steps.Content = append(steps.Content[:i], steps.Content[i+2:]...)
+				continue
+			}
+			i += 2
+		}
+	}
+	return nil
+}
+
+// extendStep returns the named step merged into the step it extends and
+// the key of the extended step.
+func extendStep(steps *yaml.Node, name string, seen []string) (*yaml.Node, *yaml.Node, error) {
+	for _, s := range seen {
+		if s == name {
+			return nil, nil, fmt.Errorf("step %s extends itself", name)
+		}
+	}
+	step := mappingValue(steps, name)
+	if step == nil {
+		return nil, nil, fmt.Errorf("step %s extends unknown step %s", seen[len(seen)-1], name)
+	}
+	extends := mappingValue(step, "extends")
+	if step.Kind != yaml.MappingNode || extends == nil {
+		return step, nil, nil
+	}
+	if extends.Kind != yaml.ScalarNode {
+		return nil, nil, fmt.Errorf("step %s: extends requires a step name", name)
+	}
+
+	baseKey, _ := mappingEntry(steps, extends.Value)
+	base, _, err := extendStep(steps, extends.Value, append(seen, name))
+	if err != nil {
+		return nil, nil, err
+	}
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	merge(merged, base)
+	merge(merged, step)
+	removeKey(merged, "extends")
+	return merged, baseKey, nil
+}
+
+// merge merges the mapping src into the mapping dst. Nested mappings are
+// merged, all other values of src replace the values of dst. New keys
+// are appended.
+func merge(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		existing := mappingValue(dst, key.Value)
+		switch {
+		case existing == nil:
+			dst.Content = append(dst.Content, key, copyNode(value))
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			merge(existing, value)
+		default:
+			setValue(dst, key.Value, copyNode(value))
+		}
+	}
+}
+
+// expandAliases returns a copy of the node with the aliases replaced by
+// copies of the nodes they refer to and without anchors. The merged
+// sections of configs keep their order, an anchor of one file could
+// otherwise follow its aliases in the resolved config.
+func expandAliases(node *yaml.Node) (*yaml.Node, error) {
+	nodes := 0
+	return expandNode(node, 0, &nodes)
+}
+
+func expandNode(node *yaml.Node, depth int, nodes *int) (*yaml.Node, error) {
+	*nodes++
+	if *nodes > maxNodes || depth > maxNodeDepth {
+		return nil, fmt.Errorf("config exceeds the limits of expanded aliases")
+	}
+	if node.Kind == yaml.AliasNode {
+		return expandNode(node.Alias, depth+1, nodes)
+	}
+	c := *node
+	c.Anchor = ""
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		expanded, err := expandNode(child, depth+1, nodes)
+		if err != nil {
+			return nil, err
+		}
+		c.Content[i] = expanded
+	}
+	return &c, nil
+}
+
+// copyNode returns a deep copy of the node so merging never modifies
+// the mappings of included configs.
+func copyNode(node *yaml.Node) *yaml.Node {
+	c := *node
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	_, value := mappingEntry(node, key)
+	return value
+}
+
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func setValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+		}
+	}
+}
+
+func removeKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
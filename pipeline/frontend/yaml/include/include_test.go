@@ -0,0 +1,203 @@
+package include
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type mockFetcher map[string]string
+
+func (f mockFetcher) Fetch(_ context.Context, source Source) ([]byte, error) {
+	data, ok := f[source.String()]
+	if !ok {
+		return nil, fmt.Errorf("file not found")
+	}
+	return []byte(data), nil
+}
+
+func TestResolve(t *testing.T) {
+	fetcher := mockFetcher{
+		".woodpecker/common.yml": `
+pipeline:
+  lint:
+    image: golangci/golangci-lint
+    commands: [ golangci-lint run ]
+  test:
+    image: golang:1.16
+    commands: [ go test ./... ]
+`,
+		"org/templates:go.yml@v1": `
+include:
+  - steps.yml
+pipeline:
+  .go-build:
+    image: golang:1.16
+    environment: [ CGO_ENABLED=0 ]
+`,
+		"org/templates:steps.yml@v1": `
+services:
+  database:
+    image: postgres
+`,
+	}
+	config, err := Resolve(context.Background(), fetcher, ".woodpecker.yml", []byte(`
+include:
+  - .woodpecker/common.yml
+  - repo: org/templates
+    path: go.yml
+    ref: v1
+pipeline:
+  test:
+    image: golang:1.17
+  build:
+    extends: .go-build
+    commands: [ go build ]
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	got := map[string]interface{}{}
+	assert.NoError(t, yaml.Unmarshal(config.Data, &got))
+	want := map[string]interface{}{
+		"pipeline": map[string]interface{}{
+			"lint": map[string]interface{}{
+				"image":    "golangci/golangci-lint",
+				"commands": []interface{}{"golangci-lint run"},
+			},
+			"test": map[string]interface{}{
+				"image":    "golang:1.17",
+				"commands": []interface{}{"go test ./..."},
+			},
+			"build": map[string]interface{}{
+				"image":       "golang:1.16",
+				"environment": []interface{}{"CGO_ENABLED=0"},
+				"commands":    []interface{}{"go build"},
+			},
+		},
+		"services": map[string]interface{}{
+			"database": map[string]interface{}{
+				"image": "postgres",
+			},
+		},
+	}
+	assert.Equal(t, want, got)
+
+	// included steps come first.
+	assert.Less(t, strings.Index(string(config.Data), "lint:"), strings.Index(string(config.Data), "build:"))
+
+	assert.Equal(t, ".woodpecker.yml -> .woodpecker/common.yml", config.Origin("lint"))
+	assert.Equal(t, ".woodpecker.yml -> org/templates:go.yml@v1 -> org/templates:steps.yml@v1", config.Origin("database"))
+	assert.Equal(t, ".woodpecker.yml -> org/templates:go.yml@v1", config.Origin("build"))
+	assert.Equal(t, "", config.Origin("unknown"))
+
+	// resolving the resolved config keeps the origins.
+	resolved, err := Resolve(context.Background(), nil, ".woodpecker.yml", config.Data)
+	if assert.NoError(t, err) {
+		assert.Equal(t, config.Data, resolved.Data)
+		assert.Equal(t, config.Origin("database"), resolved.Origin("database"))
+	}
+}
+
+func TestResolveUnchanged(t *testing.T) {
+	data := []byte("pipeline:\n  test:\n    image: golang # comment\n")
+	config, err := Resolve(context.Background(), mockFetcher{}, ".woodpecker.yml", data)
+	assert.NoError(t, err)
+	assert.Equal(t, data, config.Data)
+	assert.Equal(t, "", config.Origin("test"))
+}
+
+func TestResolveTemplate(t *testing.T) {
+	fetcher := mockFetcher{
+		"template go": "pipeline:\n  test:\n    image: golang\n",
+	}
+	config, err := Resolve(context.Background(), fetcher, ".woodpecker.yml", []byte("include:\n  - template: go\n"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "pipeline:\n  # included from .woodpecker.yml -> template go\n  test:\n    image: golang\n", string(config.Data))
+	}
+}
+
+func TestResolveAnchors(t *testing.T) {
+	fetcher := mockFetcher{
+		"common.yml": "pipeline:\n  lint:\n    image: &lint golangci/golangci-lint\n",
+	}
+	config, err := Resolve(context.Background(), fetcher, ".woodpecker.yml", []byte(`
+include: [ common.yml ]
+x-img: &img golang
+pipeline:
+  test:
+    image: *img
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	got := map[string]interface{}{}
+	assert.NoError(t, yaml.Unmarshal(config.Data, &got))
+	assert.Equal(t, map[string]interface{}{
+		"lint": map[string]interface{}{"image": "golangci/golangci-lint"},
+		"test": map[string]interface{}{"image": "golang"},
+	}, got["pipeline"])
+	assert.NotContains(t, string(config.Data), "*img")
+}
+
+func TestResolveErr(t *testing.T) {
+	testdata := []struct {
+		fetcher mockFetcher
+		data    string
+		err     string
+	}{
+		{
+			fetcher: mockFetcher{},
+			data:    "include: [ missing.yml ]",
+			err:     ".woodpecker.yml -> missing.yml: file not found",
+		},
+		{
+			fetcher: mockFetcher{
+				"a.yml": "include: [ b.yml ]",
+				"b.yml": "include: [ a.yml ]",
+			},
+			data: "include: [ a.yml ]",
+			err:  ".woodpecker.yml -> a.yml -> b.yml -> a.yml: include cycle",
+		},
+		{
+			fetcher: nil,
+			data:    "include: [ a.yml ]",
+			err:     ".woodpecker.yml -> a.yml: includes are not supported",
+		},
+		{
+			fetcher: mockFetcher{},
+			data:    "include: [ { repo: org/templates } ]",
+			err:     ".woodpecker.yml: include requires a path or a template",
+		},
+		{
+			fetcher: mockFetcher{},
+			data:    "pipeline:\n  test:\n    extends: .missing\n",
+			err:     ".woodpecker.yml: step test extends unknown step .missing",
+		},
+		{
+			fetcher: mockFetcher{},
+			data:    "pipeline:\n  a:\n    extends: b\n  b:\n    extends: a\n",
+			err:     ".woodpecker.yml: step a extends itself",
+		},
+		{
+			fetcher: mockFetcher{},
+			data:    "include: []\na: &a [x, x, x, x, x, x, x, x]\nb: &b [*a, *a, *a, *a, *a, *a, *a, *a]\nc: &c [*b, *b, *b, *b, *b, *b, *b, *b]\nd: &d [*c, *c, *c, *c, *c, *c, *c, *c]\ne: &e [*d, *d, *d, *d, *d, *d, *d, *d]\nf: &f [*e, *e, *e, *e, *e, *e, *e, *e]\ng: [*f, *f, *f, *f, *f, *f, *f, *f]\n",
+			err:     ".woodpecker.yml: config exceeds the limits of expanded aliases",
+		},
+	}
+
+	for _, test := range testdata {
+		var fetcher Fetcher
+		if test.fetcher != nil {
+			fetcher = test.fetcher
+		}
+		_, err := Resolve(context.Background(), fetcher, ".woodpecker.yml", []byte(test.data))
+		assert.EqualError(t, err, test.err, test.data)
+	}
+}
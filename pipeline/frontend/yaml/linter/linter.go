@@ -12,6 +12,22 @@ const (
 	blockServices
 )
 
+// An Error reports an invalid step.
+type Error struct {
+	Step string
+	Err  error
+}
+
+// Error returns the error message in string format.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
 // A Linter lints a pipeline configuration.
 type Linter struct {
 	trusted bool
@@ -45,24 +61,31 @@ func (l *Linter) Lint(c *yaml.Config) error {
 
 func (l *Linter) lint(containers []*yaml.Container, block uint8) error {
 	for _, container := range containers {
-		if err := l.lintImage(container); err != nil {
-			return err
-		}
-		if l.trusted == false {
-			if err := l.lintTrusted(container); err != nil {
-				return err
+		if err := l.lintContainer(container, block); err != nil {
+			return &Error{
+				Step: container.Name,
+				Err:  err,
 			}
 		}
-		if block != blockServices && !container.Detached {
-			if err := l.lintEntrypoint(container); err != nil {
-				return err
-			}
+	}
+	return nil
+}
+
+func (l *Linter) lintContainer(container *yaml.Container, block uint8) error {
+	if err := l.lintImage(container); err != nil {
+		return err
+	}
+	if l.trusted == false {
+		if err := l.lintTrusted(container); err != nil {
+			return err
 		}
-		if err := l.lintCommands(container); err != nil {
+	}
+	if block != blockServices && !container.Detached {
+		if err := l.lintEntrypoint(container); err != nil {
 			return err
 		}
 	}
-	return nil
+	return l.lintCommands(container)
 }
 
 func (l *Linter) lintImage(c *yaml.Container) error {
@@ -1,6 +1,8 @@
 package matrix
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -9,8 +11,28 @@ import (
 const (
 	limitTags = 10
 	limitAxis = 25
+
+	// maxPermutations is the maximum number of permutations of the tags
+	// and of included axis, independent of the limits. Excluded axis are
+	// calculated as well, they are not counted by the axis limit.
+	maxPermutations = 10000
 )
 
+// DefaultLimits are the limits of the build matrix if none are configured.
+var DefaultLimits = Limits{
+	Tags: limitTags,
+	Axis: limitAxis,
+}
+
+// Limits restricts the size of the build matrix. A zero limit means
+// unlimited.
+type Limits struct {
+	// Tags is the maximum number of tags, e.g. go_version.
+	Tags int
+	// Axis is the maximum number of calculated axis.
+	Axis int
+}
+
 // Matrix represents the build matrix.
 type Matrix map[string][]string
 
@@ -24,93 +46,161 @@ func (a Axis) String() string {
 	for k, v := range a {
 		envs = append(envs, k+"="+v)
 	}
+	sort.Strings(envs)
 	return strings.Join(envs, " ")
 }
 
-// Parse parses the Yaml matrix definition.
+// matches returns true if the axis contains all entries of other.
+func (a Axis) matches(other Axis) bool {
+	for k, v := range other {
+		if a[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse parses the Yaml matrix definition using the default limits.
 func Parse(data []byte) ([]Axis, error) {
+	return ParseWithLimits(data, DefaultLimits)
+}
 
-	axis, err := parseList(data)
-	if err == nil && len(axis) != 0 {
-		return axis, nil
+// ParseString parses the Yaml string matrix definition.
+func ParseString(data string) ([]Axis, error) {
+	return Parse([]byte(data))
+}
+
+// ParseWithLimits parses the Yaml matrix definition. The permutations of
+// the tags without the excluded axis and the included axis are returned.
+// An error is returned if the matrix exceeds the limits.
+func ParseWithLimits(data []byte, limits Limits) ([]Axis, error) {
+	matrix, include, exclude, err := parse(data)
+	if err != nil {
+		return nil, err
 	}
 
-	matrix, err := parse(data)
+	tags := map[string]bool{}
+	for tag := range matrix {
+		tags[tag] = true
+	}
+	for _, axis := range include {
+		for tag := range axis {
+			tags[tag] = true
+		}
+	}
+	if limits.Tags > 0 && len(tags) > limits.Tags {
+		return nil, fmt.Errorf("matrix has %d tags, exceeding the limit of %d tags", len(tags), limits.Tags)
+	}
+
+	axisList, err := calc(matrix, exclude, limits.Axis)
 	if err != nil {
 		return nil, err
 	}
+	for _, axis := range include {
+		if !contains(axisList, axis) {
+			axisList = append(axisList, axis)
+		}
+	}
+	if limits.Axis > 0 && len(axisList) > limits.Axis {
+		return nil, fmt.Errorf("matrix exceeds the limit of %d axis", limits.Axis)
+	}
 
-	if len(matrix) == 0 {
+	if axisList == nil {
 		return []Axis{}, nil
 	}
-
-	return calc(matrix), nil
+	return axisList, nil
 }
 
-// ParseString parses the Yaml string matrix definition.
-func ParseString(data string) ([]Axis, error) {
-	return Parse([]byte(data))
-}
+// calc returns all permutations of the matrix ordered by tag name, except
+// the excluded ones. An error is returned if there are more than limit.
+func calc(matrix Matrix, exclude []Axis, limit int) ([]Axis, error) {
+	if len(matrix) == 0 {
+		return nil, nil
+	}
 
-func calc(matrix Matrix) []Axis {
 	// calculate number of permutations and extract the list of tags
 	// (ie go_version, redis_version, etc)
-	var perm int
 	var tags []string
+	perm := 1
 	for k, v := range matrix {
-		perm *= len(v)
-		if perm == 0 {
-			perm = len(v)
+		if len(v) > maxPermutations/perm {
+			return nil, fmt.Errorf("matrix exceeds the limit of %d permutations", maxPermutations)
 		}
+		perm *= len(v)
 		tags = append(tags, k)
 	}
+	sort.Strings(tags)
 
 	// structure to hold the transformed result set
 	var axisList []Axis
 
 	// for each axis calculate the unique set of values that should be used.
 	for p := 0; p < perm; p++ {
-		axis := map[string]string{}
+		axis := Axis{}
 		decr := perm
-		for i, tag := range tags {
+		for _, tag := range tags {
 			elems := matrix[tag]
 			decr = decr / len(elems)
 			elem := p / decr % len(elems)
 			axis[tag] = elems[elem]
-
-			// enforce a maximum number of tags in the build matrix.
-			if i > limitTags {
-				break
-			}
+		}
+		if excluded(axis, exclude) {
+			continue
 		}
 
-		// append to the list of axis.
+		// stop early instead of calculating huge matrices.
+		if limit > 0 && len(axisList) == limit {
+			return nil, fmt.Errorf("matrix exceeds the limit of %d axis", limit)
+		}
 		axisList = append(axisList, axis)
+	}
+
+	return axisList, nil
+}
 
-		// enforce a maximum number of axis that should be calculated.
-		if p > limitAxis {
-			break
+// excluded returns true if the axis matches one of the excluded axis.
+func excluded(axis Axis, exclude []Axis) bool {
+	for _, e := range exclude {
+		if axis.matches(e) {
+			return true
 		}
 	}
-
-	return axisList
+	return false
 }
 
-func parse(raw []byte) (Matrix, error) {
-	data := struct {
-		Matrix map[string][]string
-	}{}
-	err := yaml.Unmarshal(raw, &data)
-	return data.Matrix, err
+// contains returns true if the list contains an axis equal to axis.
+func contains(axisList []Axis, axis Axis) bool {
+	for _, a := range axisList {
+		if len(a) == len(axis) && a.matches(axis) {
+			return true
+		}
+	}
+	return false
 }
 
-func parseList(raw []byte) ([]Axis, error) {
+func parse(raw []byte) (Matrix, []Axis, []Axis, error) {
 	data := struct {
 		Matrix struct {
 			Include []Axis
+			Exclude []Axis
+			Tags    Matrix `yaml:",inline"`
 		}
 	}{}
-
-	err := yaml.Unmarshal(raw, &data)
-	return data.Matrix.Include, err
+	if err := yaml.Unmarshal(raw, &data); err != nil {
+		return nil, nil, nil, err
+	}
+	for tag, elems := range data.Matrix.Tags {
+		if len(elems) == 0 {
+			return nil, nil, nil, fmt.Errorf("matrix tag %s has no values", tag)
+		}
+	}
+	for _, axis := range data.Matrix.Exclude {
+		if len(axis) == 0 {
+			return nil, nil, nil, fmt.Errorf("matrix exclude has an empty entry")
+		}
+	}
+	if len(data.Matrix.Include) > maxPermutations {
+		return nil, nil, nil, fmt.Errorf("matrix exceeds the limit of %d included axis", maxPermutations)
+	}
+	return data.Matrix.Tags, data.Matrix.Include, data.Matrix.Exclude, nil
 }
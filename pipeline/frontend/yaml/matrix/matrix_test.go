@@ -40,6 +40,49 @@ func TestMatrix(t *testing.T) {
 			g.Assert(axis[0]["python_version"]).Equal("3.4")
 			g.Assert(axis[1]["python_version"]).Equal("3.4")
 		})
+
+		g.It("Should combine permutations with include and exclude", func() {
+			axis, err := ParseString(fakeMatrixIncludeExclude)
+			g.Assert(err).IsNil()
+			g.Assert(len(axis)).Equal(4)
+			g.Assert(axis[0].String()).Equal("go_version=1.16 os=linux")
+			g.Assert(axis[1].String()).Equal("go_version=1.17 os=linux")
+			g.Assert(axis[2].String()).Equal("go_version=1.17 os=windows")
+			g.Assert(axis[3].String()).Equal("experimental=true go_version=1.18 os=linux")
+		})
+
+		g.It("Should fail if the matrix exceeds the axis limit", func() {
+			_, err := ParseWithLimits([]byte(fakeMatrix), Limits{Axis: 20})
+			g.Assert(err != nil).IsTrue()
+			g.Assert(err.Error()).Equal("matrix exceeds the limit of 20 axis")
+
+			axis, err := ParseWithLimits([]byte(fakeMatrix), Limits{})
+			g.Assert(err).IsNil()
+			g.Assert(len(axis)).Equal(24)
+		})
+
+		g.It("Should fail if the matrix exceeds the tag limit", func() {
+			_, err := ParseWithLimits([]byte(fakeMatrix), Limits{Tags: 3})
+			g.Assert(err != nil).IsTrue()
+			g.Assert(err.Error()).Equal("matrix has 4 tags, exceeding the limit of 3 tags")
+		})
+
+		g.It("Should fail if a tag has no values", func() {
+			_, err := ParseString("matrix:\n  go_version: []\n")
+			g.Assert(err != nil).IsTrue()
+		})
+
+		g.It("Should fail if the permutations exceed the hard limit", func() {
+			_, err := ParseWithLimits([]byte(fakeMatrixHuge), Limits{})
+			g.Assert(err != nil).IsTrue()
+			g.Assert(err.Error()).Equal("matrix exceeds the limit of 10000 permutations")
+		})
+
+		g.It("Should fail if an exclude entry is empty", func() {
+			_, err := ParseString("matrix:\n  go_version: [ 1.16 ]\n  exclude:\n    - {}\n")
+			g.Assert(err != nil).IsTrue()
+			g.Assert(err.Error()).Equal("matrix exclude has an empty entry")
+		})
 	})
 }
 
@@ -68,3 +111,33 @@ matrix:
     - go_version: 1.6
       python_version: 3.4
 `
+
+var fakeMatrixIncludeExclude = `
+matrix:
+  go_version:
+    - 1.16
+    - 1.17
+  os:
+    - linux
+    - windows
+  exclude:
+    - go_version: 1.16
+      os: windows
+  include:
+    - go_version: 1.17
+      os: linux
+    - go_version: 1.18
+      os: linux
+      experimental: true
+`
+
+var fakeMatrixHuge = `
+matrix:
+  a: [ 0, 1, 2, 3, 4, 5, 6, 7, 8, 9 ]
+  b: [ 0, 1, 2, 3, 4, 5, 6, 7, 8, 9 ]
+  c: [ 0, 1, 2, 3, 4, 5, 6, 7, 8, 9 ]
+  d: [ 0, 1, 2, 3, 4, 5, 6, 7, 8, 9 ]
+  e: [ 0, 1, 2, 3, 4, 5, 6, 7, 8, 9 ]
+  exclude:
+    - a: 0
+`
@@ -1,6 +1,11 @@
 package pipeline
 
 import (
+	"io"
+	"io/ioutil"
+	"net/textproto"
+	"sync"
+
 	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
 	"github.com/woodpecker-ci/woodpecker/pipeline/multipart"
 )
@@ -18,3 +23,102 @@ type LogFunc func(*backend.Step, multipart.Reader) error
 func (f LogFunc) Log(step *backend.Step, r multipart.Reader) error {
 	return f(step, r)
 }
+
+// stepLog is the log stream of a step. Output written before the step
+// is started, like the image pull progress, is streamed as the start
+// of the first part, followed by the step output once attached.
+type stepLog struct {
+	pr   *io.PipeReader
+	pw   *io.PipeWriter
+	once sync.Once
+	done chan struct{}
+	rc   io.ReadCloser
+	mr   multipart.Reader
+	head bool
+}
+
+func newStepLog() *stepLog {
+	pr, pw := io.Pipe()
+	return &stepLog{
+		pr:   pr,
+		pw:   pw,
+		done: make(chan struct{}),
+	}
+}
+
+// Write writes output before the step output.
+func (l *stepLog) Write(p []byte) (int, error) {
+	return l.pw.Write(p)
+}
+
+// attach appends the step output to the log.
+func (l *stepLog) attach(rc io.ReadCloser) {
+	l.once.Do(func() {
+		l.rc = rc
+		l.mr = multipart.New(rc)
+		l.pw.Close()
+		close(l.done)
+	})
+}
+
+// finish ends the log if no step output is attached.
+func (l *stepLog) finish() {
+	l.once.Do(func() {
+		l.pw.Close()
+		close(l.done)
+	})
+}
+
+// close releases the log after it has been read. Remaining output is
+// discarded so that writers do not block.
+func (l *stepLog) close() {
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, l.pr)
+		<-l.done
+		if l.rc != nil {
+			l.rc.Close()
+		}
+	}()
+}
+
+// NextPart implements the multipart.Reader interface.
+func (l *stepLog) NextPart() (multipart.Part, error) {
+	if !l.head {
+		l.head = true
+		return &stepLogPart{Reader: io.MultiReader(l.pr, &stepOutput{log: l})}, nil
+	}
+	<-l.done
+	if l.mr == nil {
+		return nil, io.EOF
+	}
+	return l.mr.NextPart()
+}
+
+// stepOutput reads the first part of the step output once attached.
+type stepOutput struct {
+	log  *stepLog
+	part multipart.Part
+}
+
+func (o *stepOutput) Read(p []byte) (int, error) {
+	if o.part == nil {
+		<-o.log.done
+		if o.log.mr == nil {
+			return 0, io.EOF
+		}
+		part, err := o.log.mr.NextPart()
+		if err != nil {
+			return 0, io.EOF
+		}
+		o.part = part
+	}
+	return o.part.Read(p)
+}
+
+type stepLogPart struct {
+	io.Reader
+}
+
+func (p *stepLogPart) Header() textproto.MIMEHeader { return textproto.MIMEHeader{} }
+func (p *stepLogPart) FileName() string             { return "" }
+func (p *stepLogPart) FormName() string             { return "" }
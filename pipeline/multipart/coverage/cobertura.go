@@ -0,0 +1,47 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"path"
+)
+
+type (
+	coberturaReport struct {
+		Sources []string         `xml:"sources>source"`
+		Classes []coberturaClass `xml:"packages>package>classes>class"`
+	}
+
+	coberturaClass struct {
+		Filename string          `xml:"filename,attr"`
+		Lines    []coberturaLine `xml:"lines>line"`
+	}
+
+	coberturaLine struct {
+		Number int `xml:"number,attr"`
+		Hits   int `xml:"hits,attr"`
+	}
+)
+
+// ParseCobertura parses a Cobertura XML coverage report. The file names
+// are relative to the first source directory of the report.
+func ParseCobertura(data []byte) (*Report, error) {
+	cobertura := new(coberturaReport)
+	if err := xml.Unmarshal(data, cobertura); err != nil {
+		return nil, err
+	}
+
+	lines := map[string]map[int]bool{}
+	for _, class := range cobertura.Classes {
+		name := class.Filename
+		if len(cobertura.Sources) != 0 && !path.IsAbs(name) {
+			name = path.Join(cobertura.Sources[0], name)
+		}
+		if lines[name] == nil {
+			lines[name] = map[int]bool{}
+		}
+		for _, line := range class.Lines {
+			lines[name][line.Number] = lines[name][line.Number] || line.Hits > 0
+		}
+	}
+	return newReport(lines), nil
+}
@@ -1,16 +1,26 @@
 package coverage
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"mime/multipart"
 	"net/textproto"
+	"path"
+	"sort"
 	"strconv"
 )
 
 // MimeType used by coverage reports.
 const MimeType = "application/json+coverage"
 
+// Mime types of the coverage formats converted to coverage reports.
+const (
+	MimeCobertura = "application/xml+cobertura"
+	MimeLCOV      = "text/plain+lcov"
+	MimeGoCover   = "text/plain+gocover"
+)
+
 type (
 	// Report represents a coverage report.
 	Report struct {
@@ -40,6 +50,108 @@ type (
 	}
 )
 
+// Detect returns the mime type of the coverage report with the mime type,
+// file name and content, or an empty string if it is no coverage report.
+// Files uploaded with generic mime types are detected by their name and
+// content.
+func Detect(mime, name string, data []byte) string {
+	switch mime {
+	case MimeType, MimeCobertura, MimeLCOV, MimeGoCover:
+		return mime
+	}
+
+	head := bytes.TrimSpace(data)
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("<")) && bytes.Contains(head, []byte("<coverage")):
+		return MimeCobertura
+	case bytes.HasPrefix(head, []byte("mode: ")):
+		return MimeGoCover
+	case bytes.HasPrefix(head, []byte("TN:")) || bytes.HasPrefix(head, []byte("SF:")):
+		return MimeLCOV
+	case path.Ext(name) == ".json" && bytes.Contains(head, []byte(`"metrics"`)):
+		return MimeType
+	}
+	return ""
+}
+
+// Parse parses the coverage report of the mime type.
+func Parse(mime string, data []byte) (*Report, error) {
+	switch mime {
+	case MimeType:
+		report := new(Report)
+		if err := json.Unmarshal(data, report); err != nil {
+			return nil, err
+		}
+		if report.Metrics.TotalLines == 0 && len(report.Files) != 0 {
+			report.compute()
+		}
+		return report, nil
+	case MimeCobertura:
+		return ParseCobertura(data)
+	case MimeLCOV:
+		return ParseLCOV(data)
+	case MimeGoCover:
+		return ParseGoCover(data)
+	}
+	return nil, fmt.Errorf("unsupported coverage report type %q", mime)
+}
+
+// newReport returns the report of the covered lines of the files.
+func newReport(lines map[string]map[int]bool) *Report {
+	names := make([]string, 0, len(lines))
+	for name := range lines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := new(Report)
+	for _, name := range names {
+		file := File{Name: name, TotalLines: len(lines[name])}
+		for _, covered := range lines[name] {
+			if covered {
+				file.CoveredLines++
+			}
+		}
+		report.Files = append(report.Files, file)
+	}
+	report.compute()
+	return report
+}
+
+// compute computes the metrics of the report and the files from the
+// covered lines of the files.
+func (r *Report) compute() {
+	r.Metrics = Metrics{}
+	for i := range r.Files {
+		file := &r.Files[i]
+		if file.TotalLines == 0 {
+			for _, hits := range file.Coverage {
+				if hits == nil {
+					continue
+				}
+				file.TotalLines++
+				if *hits > 0 {
+					file.CoveredLines++
+				}
+			}
+		}
+		file.Covered = percent(file.CoveredLines, file.TotalLines)
+		r.Metrics.CoveredLines += file.CoveredLines
+		r.Metrics.TotalLines += file.TotalLines
+	}
+	r.Metrics.Covered = percent(r.Metrics.CoveredLines, r.Metrics.TotalLines)
+}
+
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) * 100 / float64(total)
+}
+
 // WriteTo writes the report to multipart.Writer w.
 func (r *Report) WriteTo(w *multipart.Writer) error {
 	header := textproto.MIMEHeader{}
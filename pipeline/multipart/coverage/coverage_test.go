@@ -0,0 +1,137 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	testdata := []struct {
+		mime string
+		name string
+		data string
+		want string
+	}{
+		{MimeType, "coverage.json", "", MimeType},
+		{"", "coverage.xml", `<?xml version="1.0" ?><coverage line-rate="0.5">`, MimeCobertura},
+		{"text/plain", "lcov.info", "TN:\nSF:src/index.js\n", MimeLCOV},
+		{"", "coverage.out", "mode: set\n", MimeGoCover},
+		{"application/json", "coverage.json", `{"metrics":{"covered_percent":50}}`, MimeType},
+		{"application/xml", "report.xml", "<testsuites>", ""},
+	}
+	for _, test := range testdata {
+		assert.Equal(t, test.want, Detect(test.mime, test.name, []byte(test.data)), test.data)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	one, zero := 1, 0
+	report, err := Parse(MimeType, []byte(`{"files":[{"filename":"main.go","coverage":[null,1,0,1]}],"metrics":{}}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []*int{nil, &one, &zero, &one}, report.Files[0].Coverage)
+		assert.Equal(t, Metrics{Covered: 200.0 / 3, CoveredLines: 2, TotalLines: 3}, report.Metrics)
+	}
+
+	report, err = Parse(MimeType, []byte(`{"metrics":{"covered_percent":96,"covered_lines":96,"total_lines":100}}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 96, report.Metrics.CoveredLines)
+	}
+}
+
+func TestParseCobertura(t *testing.T) {
+	report, err := Parse(MimeCobertura, []byte(`<?xml version="1.0" ?>
+<coverage line-rate="0.6">
+  <sources><source>/src</source></sources>
+  <packages>
+    <package name="app">
+      <classes>
+        <class name="app.py" filename="app/app.py">
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="0"/>
+          </lines>
+        </class>
+        <class name="App" filename="app/app.py">
+          <lines>
+            <line number="2" hits="3"/>
+            <line number="3" hits="0"/>
+          </lines>
+        </class>
+        <class name="util.py" filename="app/util.py">
+          <lines>
+            <line number="1" hits="0"/>
+            <line number="2" hits="0"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []File{
+		{Name: "/src/app/app.py", CoveredLines: 2, TotalLines: 3, Covered: 200.0 / 3},
+		{Name: "/src/app/util.py", CoveredLines: 0, TotalLines: 2},
+	}, report.Files)
+	assert.Equal(t, Metrics{Covered: 40, CoveredLines: 2, TotalLines: 5}, report.Metrics)
+}
+
+func TestParseLCOV(t *testing.T) {
+	report, err := Parse(MimeLCOV, []byte(`TN:
+SF:src/index.js
+FN:1,main
+DA:1,1
+DA:2,0
+DA:3,5
+LF:3
+LH:2
+end_of_record
+SF:src/util.js
+DA:1,0
+end_of_record
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []File{
+		{Name: "src/index.js", CoveredLines: 2, TotalLines: 3, Covered: 200.0 / 3},
+		{Name: "src/util.js", CoveredLines: 0, TotalLines: 1},
+	}, report.Files)
+
+	_, err = ParseLCOV([]byte("DA:1,1\n"))
+	assert.Error(t, err)
+}
+
+func TestParseGoCover(t *testing.T) {
+	report, err := Parse(MimeGoCover, []byte(`mode: set
+github.com/octocat/hello-world/main.go:5.13,7.2 1 1
+github.com/octocat/hello-world/main.go:7.2,9.3 1 0
+github.com/octocat/hello-world/util.go:3.20,4.2 1 0
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []File{
+		{Name: "github.com/octocat/hello-world/main.go", CoveredLines: 3, TotalLines: 5, Covered: 60},
+		{Name: "github.com/octocat/hello-world/util.go", CoveredLines: 0, TotalLines: 2},
+	}, report.Files)
+	assert.Equal(t, 3, report.Metrics.CoveredLines)
+
+	_, err = ParseGoCover([]byte("mode: set\nmain.go 1 1\n"))
+	assert.Error(t, err)
+}
+
+func TestParseGoCoverLimits(t *testing.T) {
+	for _, block := range []string{"9.1,5.2", "0.1,5.2", "1.1,2000000.2"} {
+		_, err := ParseGoCover([]byte("mode: set\nmain.go:" + block + " 1 1\n"))
+		assert.Error(t, err, block)
+	}
+
+	// the lines of all blocks are limited, also if they overlap
+	profile := "mode: set\n" + strings.Repeat("main.go:1.1,1000000.2 1 1\n", 6)
+	_, err := ParseGoCover([]byte(profile))
+	assert.EqualError(t, err, "profile exceeds the limit of 5000000 lines")
+}
@@ -0,0 +1,71 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Limits of the lines of go coverage profiles, a block spans the lines
+// between its start and end line and is converted to an entry per line.
+const (
+	// maxFileLines is the maximum line number of a file.
+	maxFileLines = 1000000
+	// maxReportLines is the maximum number of lines of all blocks.
+	maxReportLines = 5000000
+)
+
+// ParseGoCover parses a coverage profile of go test. The statement blocks
+// are converted to the lines they span, a line is covered if one of its
+// blocks is covered.
+func ParseGoCover(data []byte) (*Report, error) {
+	lines := map[string]map[int]bool{}
+	entries := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		// name.go:line.column,line.column statements count
+		sep := strings.LastIndex(line, ":")
+		if sep < 0 {
+			return nil, fmt.Errorf("invalid profile line: %s", line)
+		}
+		fields := strings.Fields(line[sep+1:])
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid profile line: %s", line)
+		}
+		var start, end int
+		if _, err := fmt.Sscanf(fields[0], "%d.%d,%d.", &start, new(int), &end); err != nil {
+			return nil, fmt.Errorf("invalid profile block: %s", line)
+		}
+		if start < 1 || end < start || end > maxFileLines {
+			return nil, fmt.Errorf("invalid profile block lines: %s", line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile count: %s", line)
+		}
+
+		name := line[:sep]
+		if lines[name] == nil {
+			lines[name] = map[int]bool{}
+		}
+		entries += end - start + 1
+		if entries > maxReportLines {
+			return nil, fmt.Errorf("profile exceeds the limit of %d lines", maxReportLines)
+		}
+		for i := start; i <= end; i++ {
+			lines[name][i] = lines[name][i] || count > 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return newReport(lines), nil
+}
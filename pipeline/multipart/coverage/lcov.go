@@ -0,0 +1,48 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseLCOV parses a LCOV tracefile.
+func ParseLCOV(data []byte) (*Report, error) {
+	lines := map[string]map[int]bool{}
+	var file map[int]bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			name := strings.TrimPrefix(line, "SF:")
+			if lines[name] == nil {
+				lines[name] = map[int]bool{}
+			}
+			file = lines[name]
+		case strings.HasPrefix(line, "DA:"):
+			if file == nil {
+				return nil, fmt.Errorf("line data without source file: %s", line)
+			}
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("invalid line data: %s", line)
+			}
+			number, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid line data: %s", line)
+			}
+			hits, _ := strconv.ParseFloat(fields[1], 64)
+			file[number] = file[number] || hits > 0
+		case line == "end_of_record":
+			file = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return newReport(lines), nil
+}
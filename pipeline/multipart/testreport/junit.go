@@ -0,0 +1,84 @@
+package testreport
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+type (
+	junitSuites struct {
+		Suites []junitSuite `xml:"testsuite"`
+		junitSuite
+	}
+
+	junitSuite struct {
+		Name   string       `xml:"name,attr"`
+		Suites []junitSuite `xml:"testsuite"`
+		Cases  []junitCase  `xml:"testcase"`
+	}
+
+	junitCase struct {
+		Name      string        `xml:"name,attr"`
+		Classname string        `xml:"classname,attr"`
+		Time      string        `xml:"time,attr"`
+		Failure   *junitMessage `xml:"failure"`
+		Error     *junitMessage `xml:"error"`
+		Skipped   *junitMessage `xml:"skipped"`
+	}
+
+	junitMessage struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// ParseJUnit parses a JUnit XML report. The root element is either a
+// testsuites element or a single testsuite.
+func ParseJUnit(data []byte) (*Report, error) {
+	root := new(junitSuites)
+	if err := xml.Unmarshal(data, root); err != nil {
+		return nil, err
+	}
+
+	report := new(Report)
+	var walk func(suite junitSuite)
+	walk = func(suite junitSuite) {
+		for _, c := range suite.Cases {
+			test := &Test{
+				Suite:  suite.Name,
+				Name:   c.Name,
+				Status: StatusPassed,
+			}
+			if test.Suite == "" {
+				test.Suite = c.Classname
+			}
+			test.Duration, _ = strconv.ParseFloat(c.Time, 64)
+			for _, m := range []*junitMessage{c.Failure, c.Error} {
+				if m != nil && test.Status != StatusFailed {
+					test.Status = StatusFailed
+					test.Message = m.message()
+				}
+			}
+			if c.Skipped != nil && test.Status == StatusPassed {
+				test.Status = StatusSkipped
+				test.Message = c.Skipped.message()
+			}
+			report.Tests = append(report.Tests, test)
+		}
+		for _, child := range suite.Suites {
+			walk(child)
+		}
+	}
+	walk(root.junitSuite)
+	for _, suite := range root.Suites {
+		walk(suite)
+	}
+	return report, nil
+}
+
+func (m *junitMessage) message() string {
+	if m.Text != "" {
+		return truncate(m.Text)
+	}
+	return truncate(m.Message)
+}
@@ -0,0 +1,96 @@
+package testreport
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Mime types used by test reports.
+const (
+	MimeJUnit = "application/xml+junit"
+	MimeTAP   = "text/plain+tap"
+)
+
+// Test result states.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+type (
+	// Report represents the results of a test report.
+	Report struct {
+		Tests []*Test
+	}
+
+	// Test represents the result of a single test.
+	Test struct {
+		Suite    string
+		Name     string
+		Status   string
+		Duration float64 // seconds
+		Message  string
+	}
+)
+
+// Counts returns the number of passed, failed and skipped tests.
+func (r *Report) Counts() (passed, failed, skipped int) {
+	for _, test := range r.Tests {
+		switch test.Status {
+		case StatusPassed:
+			passed++
+		case StatusFailed:
+			failed++
+		case StatusSkipped:
+			skipped++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// Detect returns the mime type of the test report with the mime type, file
+// name and content, or an empty string if it is no test report. Files
+// uploaded with generic mime types are detected by their name and content.
+func Detect(mime, name string, data []byte) string {
+	switch mime {
+	case MimeJUnit, MimeTAP:
+		return mime
+	}
+
+	head := bytes.TrimSpace(data)
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("<")) && (bytes.Contains(head, []byte("<testsuite")) || bytes.Contains(head, []byte("<testcase"))):
+		return MimeJUnit
+	case bytes.HasPrefix(head, []byte("TAP version")):
+		return MimeTAP
+	case path.Ext(name) == ".tap" && (bytes.HasPrefix(head, []byte("1..")) || bytes.HasPrefix(head, []byte("ok")) || bytes.HasPrefix(head, []byte("not ok"))):
+		return MimeTAP
+	}
+	return ""
+}
+
+// Parse parses the test report of the mime type.
+func Parse(mime string, data []byte) (*Report, error) {
+	switch mime {
+	case MimeJUnit:
+		return ParseJUnit(data)
+	case MimeTAP:
+		return ParseTAP(data)
+	}
+	return nil, fmt.Errorf("unsupported test report type %q", mime)
+}
+
+// truncate limits the message of a test result to a reasonable size.
+func truncate(message string) string {
+	message = strings.TrimSpace(message)
+	if len(message) > 2048 {
+		message = message[:2048]
+	}
+	return message
+}
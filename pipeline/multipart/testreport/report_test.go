@@ -0,0 +1,102 @@
+package testreport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	testdata := []struct {
+		mime string
+		name string
+		data string
+		want string
+	}{
+		{MimeJUnit, "report.xml", "", MimeJUnit},
+		{"application/xml", "report.xml", `<?xml version="1.0"?><testsuites><testsuite name="a">`, MimeJUnit},
+		{"", "report.xml", "<testsuite name=\"a\">", MimeJUnit},
+		{"text/plain", "report.txt", "TAP version 13\n1..2\n", MimeTAP},
+		{"", "report.tap", "1..2\nok 1\n", MimeTAP},
+		{"", "report.txt", "1..2\nok 1\n", ""},
+		{"application/json+coverage", "coverage.json", `{"metrics":{}}`, ""},
+		{"application/xml", "pom.xml", "<project>", ""},
+	}
+	for _, test := range testdata {
+		assert.Equal(t, test.want, Detect(test.mime, test.name, []byte(test.data)), test.data)
+	}
+}
+
+func TestParseJUnit(t *testing.T) {
+	report, err := Parse(MimeJUnit, []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="server/api" tests="3">
+    <testcase classname="server/api" name="TestBadge" time="0.010"/>
+    <testcase classname="server/api" name="TestStream" time="1.5">
+      <failure message="timeout">stream did not finish</failure>
+    </testcase>
+    <testcase classname="server/api" name="TestSkip">
+      <skipped message="requires docker"/>
+    </testcase>
+  </testsuite>
+  <testsuite name="server/badges">
+    <testsuite name="nested">
+      <testcase name="TestNested">
+        <error message="panic"/>
+      </testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []*Test{
+		{Suite: "server/api", Name: "TestBadge", Status: StatusPassed, Duration: 0.01},
+		{Suite: "server/api", Name: "TestStream", Status: StatusFailed, Duration: 1.5, Message: "stream did not finish"},
+		{Suite: "server/api", Name: "TestSkip", Status: StatusSkipped, Message: "requires docker"},
+		{Suite: "nested", Name: "TestNested", Status: StatusFailed, Message: "panic"},
+	}, report.Tests)
+
+	passed, failed, skipped := report.Counts()
+	assert.Equal(t, []int{1, 2, 1}, []int{passed, failed, skipped})
+}
+
+func TestParseJUnitSuite(t *testing.T) {
+	report, err := ParseJUnit([]byte(`<testsuite name="pytest"><testcase classname="tests.test_app" name="test_index"/></testsuite>`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []*Test{{Suite: "pytest", Name: "test_index", Status: StatusPassed}}, report.Tests)
+	}
+
+	_, err = ParseJUnit([]byte(`<testsuite`))
+	assert.Error(t, err)
+}
+
+func TestParseTAP(t *testing.T) {
+	report, err := Parse(MimeTAP, []byte(`TAP version 13
+1..5
+ok 1 - parses the config
+not ok 2 - lints the config
+  ---
+  message: 'unknown key'
+  severity: fail
+  ...
+ok 3 # SKIP no network
+# Subtest: nested
+    ok 1 - ignored
+ok 4 - nested
+not ok 5 - later # TODO not implemented
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []*Test{
+		{Name: "parses the config", Status: StatusPassed},
+		{Name: "lints the config", Status: StatusFailed, Message: "message: 'unknown key'\nseverity: fail"},
+		{Name: "test 3", Status: StatusSkipped, Message: "no network"},
+		{Name: "nested", Status: StatusPassed},
+		{Name: "later", Status: StatusSkipped, Message: "not implemented"},
+	}, report.Tests)
+
+	_, err = ParseTAP([]byte("1..2\nBail out! database unavailable\n"))
+	assert.EqualError(t, err, "test run bailed out: database unavailable")
+}
@@ -0,0 +1,69 @@
+package testreport
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var tapLine = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(.*))?$`)
+
+// ParseTAP parses a report of the Test Anything Protocol. Indented
+// subtests are ignored, their results are summarized by the parent test.
+// Tests with a SKIP or TODO directive are reported as skipped.
+func ParseTAP(data []byte) (*Report, error) {
+	report := new(Report)
+	var last *Test
+	var diagnostics []string
+	inDiagnostics := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// the yaml diagnostics of the previous test
+		if inDiagnostics {
+			if strings.TrimSpace(line) == "..." {
+				last.Message = truncate(strings.Join(diagnostics, "\n"))
+				inDiagnostics, diagnostics = false, nil
+			} else {
+				diagnostics = append(diagnostics, strings.TrimPrefix(line, "  "))
+			}
+			continue
+		}
+		if last != nil && strings.TrimSpace(line) == "---" && strings.HasPrefix(line, " ") {
+			inDiagnostics = true
+			continue
+		}
+
+		match := tapLine.FindStringSubmatch(line)
+		if match == nil {
+			if strings.HasPrefix(line, "Bail out!") {
+				return nil, fmt.Errorf("test run bailed out: %s", strings.TrimSpace(strings.TrimPrefix(line, "Bail out!")))
+			}
+			continue
+		}
+
+		test := &Test{
+			Name:   match[3],
+			Status: StatusPassed,
+		}
+		if match[1] == "not ok" {
+			test.Status = StatusFailed
+		}
+		if test.Name == "" {
+			test.Name = "test " + match[2]
+		}
+		directive := strings.ToUpper(match[4])
+		if strings.HasPrefix(directive, "SKIP") || strings.HasPrefix(directive, "TODO") {
+			test.Status = StatusSkipped
+			test.Message = strings.TrimSpace(match[4][4:])
+		}
+		report.Tests = append(report.Tests, test)
+		last = test
+	}
+	return report, scanner.Err()
+}
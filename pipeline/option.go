@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
+	"github.com/woodpecker-ci/woodpecker/pipeline/cache"
 )
 
 // Option configures a runtime option.
@@ -36,3 +37,11 @@ func WithContext(ctx context.Context) Option {
 		r.ctx = ctx
 	}
 }
+
+// WithCache returns an option configured with the cache restored and
+// saved by cache steps.
+func WithCache(cache *cache.Cache) Option {
+	return func(r *Runtime) {
+		r.cache = cache
+	}
+}
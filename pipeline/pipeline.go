@@ -2,12 +2,15 @@ package pipeline
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
-	"github.com/woodpecker-ci/woodpecker/pipeline/multipart"
+	"github.com/woodpecker-ci/woodpecker/pipeline/cache"
 )
 
 type (
@@ -38,6 +41,7 @@ type Runtime struct {
 	ctx    context.Context
 	tracer Tracer
 	logger Logger
+	cache  *cache.Cache
 }
 
 // New returns a new runtime using the specified runtime
@@ -124,24 +128,54 @@ func (r *Runtime) exec(proc *backend.Step) error {
 		}
 	}
 
+	var logs *stepLog
+	if r.logger != nil {
+		logs = newStepLog()
+		defer logs.finish()
+
+		go func() {
+			r.logger.Log(proc, logs)
+			logs.close()
+		}()
+	}
+
+	pullStarted, pullFinished, err := r.pull(proc, logs)
+	if err != nil {
+		return err
+	}
+
 	if err := r.engine.Exec(r.ctx, proc); err != nil {
 		return err
 	}
 
-	if r.logger != nil {
+	// cache steps write the cache report instead of the step output.
+	if logs != nil && proc.Cache == nil {
 		rc, err := r.engine.Tail(r.ctx, proc)
 		if err != nil {
 			return err
 		}
-
-		go func() {
-			r.logger.Log(proc, multipart.New(rc))
-			rc.Close()
-		}()
+		logs.attach(rc)
 	}
 
+	// detached steps with a healthcheck block the next stage until they
+	// report healthy.
 	if proc.Detached {
-		return nil
+		// detached steps are not waited for, report the pull timing while
+		// the step is running.
+		if r.tracer != nil && pullStarted != 0 {
+			state := new(State)
+			state.Pipeline.Time = r.started
+			state.Pipeline.Error = r.err
+			state.Pipeline.Step = proc
+			state.Process = &backend.State{
+				PullStarted:  pullStarted,
+				PullFinished: pullFinished,
+			}
+			if err := r.tracer.Trace(state); err != nil && err != ErrSkip {
+				return err
+			}
+		}
+		return r.waitHealthy(proc)
 	}
 
 	wait, err := r.engine.Wait(r.ctx, proc)
@@ -149,6 +183,13 @@ func (r *Runtime) exec(proc *backend.Step) error {
 		return err
 	}
 
+	wait.PullStarted = pullStarted
+	wait.PullFinished = pullFinished
+
+	if proc.Cache != nil && wait.ExitCode == 0 {
+		r.execCache(proc, logs)
+	}
+
 	if r.tracer != nil {
 		state := new(State)
 		state.Pipeline.Time = r.started
@@ -173,3 +214,69 @@ func (r *Runtime) exec(proc *backend.Step) error {
 	}
 	return nil
 }
+
+// pull pulls the image of the step in a separate phase if supported by
+// the engine, streaming the progress to the step log. It returns the
+// time the pull started and finished, zero if the image was not pulled.
+func (r *Runtime) pull(proc *backend.Step, logs *stepLog) (int64, int64, error) {
+	puller, ok := r.engine.(backend.Puller)
+	if !ok {
+		return 0, 0, nil
+	}
+
+	var out io.Writer = ioutil.Discard
+	if logs != nil {
+		out = logs
+	}
+
+	started := time.Now().Unix()
+	pulled, err := puller.Pull(r.ctx, proc, out)
+	if !pulled {
+		return 0, 0, err
+	}
+	return started, time.Now().Unix(), err
+}
+
+// waitHealthy waits for the healthcheck of the detached step if the
+// engine supports it.
+func (r *Runtime) waitHealthy(proc *backend.Step) error {
+	checker, ok := r.engine.(backend.HealthChecker)
+	if proc.Healthcheck == nil || !ok {
+		return nil
+	}
+	if err := checker.WaitHealthy(r.ctx, proc); err != nil {
+		if r.ctx.Err() != nil {
+			return ErrCancel
+		}
+		return &HealthError{
+			Name: proc.Name,
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+// execCache restores or saves the cache of the step in its workspace
+// and reports it to the step log. Cache errors do not fail the step.
+func (r *Runtime) execCache(proc *backend.Step, logs *stepLog) {
+	var out io.Writer = ioutil.Discard
+	if logs != nil {
+		out = logs
+	}
+
+	copier, ok := r.engine.(backend.Copier)
+	if !ok || r.cache == nil {
+		fmt.Fprintln(out, "Cache is not enabled on this agent")
+		return
+	}
+
+	var err error
+	if proc.Cache.Save {
+		err = r.cache.Save(r.ctx, copier, proc, out)
+	} else {
+		err = r.cache.Restore(r.ctx, copier, proc, out)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "Cache error: %s\n", err)
+	}
+}
@@ -114,6 +114,8 @@ func (c *client) Init(ctx context.Context, id string, state State) (err error) {
 	req.State.Exited = state.Exited
 	req.State.Finished = state.Finished
 	req.State.Started = state.Started
+	req.State.PullStarted = state.PullStarted
+	req.State.PullFinished = state.PullFinished
 	req.State.Name = state.Proc
 	for {
 		_, err = c.client.Init(ctx, req)
@@ -148,6 +150,8 @@ func (c *client) Done(ctx context.Context, id string, state State) (err error) {
 	req.State.Exited = state.Exited
 	req.State.Finished = state.Finished
 	req.State.Started = state.Started
+	req.State.PullStarted = state.PullStarted
+	req.State.PullFinished = state.PullFinished
 	req.State.Name = state.Proc
 	for {
 		_, err = c.client.Done(ctx, req)
@@ -209,6 +213,8 @@ func (c *client) Update(ctx context.Context, id string, state State) (err error)
 	req.State.Exited = state.Exited
 	req.State.Finished = state.Finished
 	req.State.Started = state.Started
+	req.State.PullStarted = state.PullStarted
+	req.State.PullFinished = state.PullFinished
 	req.State.Name = state.Proc
 	for {
 		_, err = c.client.Update(ctx, req)
@@ -299,3 +305,32 @@ func (c *client) Log(ctx context.Context, id string, line *Line) (err error) {
 	}
 	return nil
 }
+
+// Active returns the subset of the pipelines that are still pending
+// or running.
+func (c *client) Active(ctx context.Context, ids []string) (active []string, err error) {
+	req := new(proto.ActiveRequest)
+	req.Ids = ids
+	var res *proto.ActiveReply
+	for {
+		res, err = c.client.Active(ctx, req)
+		if err == nil {
+			break
+		} else {
+			log.Err(err).Msgf("grpc error: active(): code: %v: %s", status.Code(err), err)
+		}
+		switch status.Code(err) {
+		case
+			codes.Aborted,
+			codes.DataLoss,
+			codes.DeadlineExceeded,
+			codes.Internal,
+			codes.Unavailable:
+			// non-fatal errors
+		default:
+			return nil, err
+		}
+		<-time.After(backoff)
+	}
+	return res.GetIds(), nil
+}
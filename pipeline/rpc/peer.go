@@ -18,12 +18,14 @@ type (
 
 	// State defines the pipeline state.
 	State struct {
-		Proc     string `json:"proc"`
-		Exited   bool   `json:"exited"`
-		ExitCode int    `json:"exit_code"`
-		Started  int64  `json:"started"`
-		Finished int64  `json:"finished"`
-		Error    string `json:"error"`
+		Proc         string `json:"proc"`
+		Exited       bool   `json:"exited"`
+		ExitCode     int    `json:"exit_code"`
+		Started      int64  `json:"started"`
+		Finished     int64  `json:"finished"`
+		PullStarted  int64  `json:"pull_started"`
+		PullFinished int64  `json:"pull_finished"`
+		Error        string `json:"error"`
 	}
 
 	// Pipeline defines the pipeline execution details.
@@ -73,4 +75,8 @@ type Peer interface {
 
 	// Log writes the pipeline log entry.
 	Log(c context.Context, id string, line *Line) error
+
+	// Active returns the subset of the pipelines that are still pending
+	// or running.
+	Active(c context.Context, ids []string) ([]string, error)
 }
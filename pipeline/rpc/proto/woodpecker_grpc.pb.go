@@ -30,6 +30,7 @@ type WoodpeckerClient interface {
 	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Empty, error)
 	Upload(ctx context.Context, in *UploadRequest, opts ...grpc.CallOption) (*Empty, error)
 	Log(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (*Empty, error)
+	Active(ctx context.Context, in *ActiveRequest, opts ...grpc.CallOption) (*ActiveReply, error)
 }
 
 type woodpeckerClient struct {
@@ -112,6 +113,15 @@ func (c *woodpeckerClient) Log(ctx context.Context, in *LogRequest, opts ...grpc
 	return out, nil
 }
 
+func (c *woodpeckerClient) Active(ctx context.Context, in *ActiveRequest, opts ...grpc.CallOption) (*ActiveReply, error) {
+	out := new(ActiveReply)
+	err := c.cc.Invoke(ctx, "/proto.Woodpecker/Active", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WoodpeckerServer is the server API for Woodpecker service.
 // All implementations must embed UnimplementedWoodpeckerServer
 // for forward compatibility
@@ -124,6 +134,7 @@ type WoodpeckerServer interface {
 	Update(context.Context, *UpdateRequest) (*Empty, error)
 	Upload(context.Context, *UploadRequest) (*Empty, error)
 	Log(context.Context, *LogRequest) (*Empty, error)
+	Active(context.Context, *ActiveRequest) (*ActiveReply, error)
 	mustEmbedUnimplementedWoodpeckerServer()
 }
 
@@ -155,6 +166,9 @@ func (UnimplementedWoodpeckerServer) Upload(context.Context, *UploadRequest) (*E
 func (UnimplementedWoodpeckerServer) Log(context.Context, *LogRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Log not implemented")
 }
+func (UnimplementedWoodpeckerServer) Active(context.Context, *ActiveRequest) (*ActiveReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Active not implemented")
+}
 func (UnimplementedWoodpeckerServer) mustEmbedUnimplementedWoodpeckerServer() {}
 
 // UnsafeWoodpeckerServer may be embedded to opt out of forward compatibility for this service.
@@ -312,6 +326,24 @@ func _Woodpecker_Log_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Woodpecker_Active_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WoodpeckerServer).Active(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Woodpecker/Active",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WoodpeckerServer).Active(ctx, req.(*ActiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Woodpecker_ServiceDesc is the grpc.ServiceDesc for Woodpecker service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -351,6 +383,10 @@ var Woodpecker_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Log",
 			Handler:    _Woodpecker_Log_Handler,
 		},
+		{
+			MethodName: "Active",
+			Handler:    _Woodpecker_Active_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "woodpecker.proto",
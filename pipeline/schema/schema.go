@@ -13,12 +13,24 @@ import (
 var schemaDefinition []byte
 
 func Lint(file string) (error, []gojsonschema.ResultError) {
-	schemaLoader := gojsonschema.NewBytesLoader(schemaDefinition)
 	j, err := yml.LoadYmlFileAsJson(file)
 	if err != nil {
 		return fmt.Errorf("Failed to load yml file %w", err), nil
 	}
+	return lint(j)
+}
 
+// LintBytes validates the yaml config data like Lint.
+func LintBytes(data []byte) (error, []gojsonschema.ResultError) {
+	j, err := yml.ToJson(data)
+	if err != nil {
+		return fmt.Errorf("Failed to load yml %w", err), nil
+	}
+	return lint(j)
+}
+
+func lint(j []byte) (error, []gojsonschema.ResultError) {
+	schemaLoader := gojsonschema.NewBytesLoader(schemaDefinition)
 	documentLoader := gojsonschema.NewBytesLoader(j)
 	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
 	if err != nil {
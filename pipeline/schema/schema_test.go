@@ -29,18 +29,38 @@ func TestSchema(t *testing.T) {
 			name:     "Branches exclude & include",
 			testFile: ".woodpecker/test-branches-exclude-include.yml",
 		},
+		{
+			name:     "Cache",
+			testFile: ".woodpecker/test-cache.yml",
+		},
 		{
 			name:     "Clone",
 			testFile: ".woodpecker/test-clone.yml",
 		},
+		{
+			name:     "Clone settings",
+			testFile: ".woodpecker/test-clone-settings.yml",
+		},
 		{
 			name:     "Clone skip",
 			testFile: ".woodpecker/test-clone-skip.yml",
 		},
+		{
+			name:     "Healthcheck",
+			testFile: ".woodpecker/test-healthcheck.yml",
+		},
+		{
+			name:     "Include",
+			testFile: ".woodpecker/test-include.yml",
+		},
 		{
 			name:     "Matrix",
 			testFile: ".woodpecker/test-matrix.yml",
 		},
+		{
+			name:     "Matrix include & exclude",
+			testFile: ".woodpecker/test-matrix-include-exclude.yml",
+		},
 		{
 			name:     "Multi Pipeline",
 			testFile: ".woodpecker/test-multi.yml",
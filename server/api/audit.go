@@ -0,0 +1,118 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/router/middleware/session"
+	"github.com/woodpecker-ci/woodpecker/server/store"
+)
+
+// GetAuditEvents gets the audit events matching the query filters
+// and writes them to the response in json format.
+func GetAuditEvents(c *gin.Context) {
+	filter, err := auditFilterFromQuery(c, 100)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Error parsing filter. %s", err)
+		return
+	}
+	events, err := store.FromContext(c).AuditList(filter)
+	if err != nil {
+		c.String(500, "Error getting audit events. %s", err)
+		return
+	}
+	c.JSON(200, events)
+}
+
+// ExportAuditEvents writes the audit events matching the query
+// filters to the response as json lines.
+func ExportAuditEvents(c *gin.Context) {
+	filter, err := auditFilterFromQuery(c, 0)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Error parsing filter. %s", err)
+		return
+	}
+	events, err := store.FromContext(c).AuditList(filter)
+	if err != nil {
+		c.String(500, "Error getting audit events. %s", err)
+		return
+	}
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(200)
+	enc := json.NewEncoder(c.Writer)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			log.Error().Err(err).Msg("could not write audit event")
+			return
+		}
+	}
+}
+
+func auditFilterFromQuery(c *gin.Context, limit int) (*model.AuditFilter, error) {
+	filter := &model.AuditFilter{
+		Actor:  c.Query("actor"),
+		Action: c.Query("action"),
+		Repo:   c.Query("repo"),
+		Limit:  limit,
+	}
+	for name, val := range map[string]*int64{
+		"before": &filter.Before,
+		"after":  &filter.After,
+	} {
+		if s := c.Query(name); s != "" {
+			i, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			*val = i
+		}
+	}
+	if s := c.Query("limit"); s != "" {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		filter.Limit = i
+	}
+	return filter, nil
+}
+
+// recordAudit appends an event for the current user to the audit log.
+// Failing to record the event is logged but does not fail the request.
+func recordAudit(c *gin.Context, action string, repo *model.Repo, target string, data map[string]string) {
+	event := &model.AuditEvent{
+		Created: time.Now().Unix(),
+		Action:  action,
+		Target:  target,
+		Data:    data,
+	}
+	if user := session.User(c); user != nil {
+		event.Actor = user.Login
+	}
+	if repo != nil {
+		event.Repo = repo.FullName
+	}
+	if err := store.FromContext(c).AuditCreate(event); err != nil {
+		log.Error().Err(err).Msgf("could not record audit event %s", action)
+	}
+}
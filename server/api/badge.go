@@ -18,25 +18,28 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/woodpecker-ci/woodpecker/server"
+	"github.com/woodpecker-ci/woodpecker/server/badges"
 	"github.com/woodpecker-ci/woodpecker/server/model"
 	"github.com/woodpecker-ci/woodpecker/server/store"
 )
 
-var (
-	badgeSuccess = `<svg xmlns="http://www.w3.org/2000/svg" width="91" height="20"><linearGradient id="a" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient><rect rx="3" width="91" height="20" fill="#555"/><rect rx="3" x="37" width="54" height="20" fill="#4c1"/><path fill="#4c1" d="M37 0h4v20h-4z"/><rect rx="3" width="91" height="20" fill="url(#a)"/><g fill="#fff" text-anchor="middle" font-family="DejaVu Sans,Verdana,Geneva,sans-serif" font-size="11"><text x="19.5" y="15" fill="#010101" fill-opacity=".3">build</text><text x="19.5" y="14">build</text><text x="63" y="15" fill="#010101" fill-opacity=".3">success</text><text x="63" y="14">success</text></g></svg>`
-	badgeFailure = `<svg xmlns="http://www.w3.org/2000/svg" width="83" height="20"><linearGradient id="a" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient><rect rx="3" width="83" height="20" fill="#555"/><rect rx="3" x="37" width="46" height="20" fill="#e05d44"/><path fill="#e05d44" d="M37 0h4v20h-4z"/><rect rx="3" width="83" height="20" fill="url(#a)"/><g fill="#fff" text-anchor="middle" font-family="DejaVu Sans,Verdana,Geneva,sans-serif" font-size="11"><text x="19.5" y="15" fill="#010101" fill-opacity=".3">build</text><text x="19.5" y="14">build</text><text x="59" y="15" fill="#010101" fill-opacity=".3">failure</text><text x="59" y="14">failure</text></g></svg>`
-	badgeStarted = `<svg xmlns="http://www.w3.org/2000/svg" width="87" height="20"><linearGradient id="a" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient><rect rx="3" width="87" height="20" fill="#555"/><rect rx="3" x="37" width="50" height="20" fill="#dfb317"/><path fill="#dfb317" d="M37 0h4v20h-4z"/><rect rx="3" width="87" height="20" fill="url(#a)"/><g fill="#fff" text-anchor="middle" font-family="DejaVu Sans,Verdana,Geneva,sans-serif" font-size="11"><text x="19.5" y="15" fill="#010101" fill-opacity=".3">build</text><text x="19.5" y="14">build</text><text x="61" y="15" fill="#010101" fill-opacity=".3">started</text><text x="61" y="14">started</text></g></svg>`
-	badgeError   = `<svg xmlns="http://www.w3.org/2000/svg" width="76" height="20"><linearGradient id="a" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient><rect rx="3" width="76" height="20" fill="#555"/><rect rx="3" x="37" width="39" height="20" fill="#9f9f9f"/><path fill="#9f9f9f" d="M37 0h4v20h-4z"/><rect rx="3" width="76" height="20" fill="url(#a)"/><g fill="#fff" text-anchor="middle" font-family="DejaVu Sans,Verdana,Geneva,sans-serif" font-size="11"><text x="19.5" y="15" fill="#010101" fill-opacity=".3">build</text><text x="19.5" y="14">build</text><text x="55.5" y="15" fill="#010101" fill-opacity=".3">error</text><text x="55.5" y="14">error</text></g></svg>`
-	badgeNone    = `<svg xmlns="http://www.w3.org/2000/svg" width="75" height="20"><linearGradient id="a" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient><rect rx="3" width="75" height="20" fill="#555"/><rect rx="3" x="37" width="38" height="20" fill="#9f9f9f"/><path fill="#9f9f9f" d="M37 0h4v20h-4z"/><rect rx="3" width="75" height="20" fill="url(#a)"/><g fill="#fff" text-anchor="middle" font-family="DejaVu Sans,Verdana,Geneva,sans-serif" font-size="11"><text x="19.5" y="15" fill="#010101" fill-opacity=".3">build</text><text x="19.5" y="14">build</text><text x="55" y="15" fill="#010101" fill-opacity=".3">none</text><text x="55" y="14">none</text></g></svg>`
-)
-
+// GetBadge serves the status, duration or coverage badge of the latest
+// build, or of its workflows with the name, platform and matrix values
+// given as query parameters. The badges are served as SVG image or as
+// response of the shields.io endpoint badge in JSON.
 func GetBadge(c *gin.Context) {
 	store_ := store.FromContext(c)
 	repo, err := store_.GetRepoName(c.Param("owner") + "/" + c.Param("name"))
@@ -45,37 +48,194 @@ func GetBadge(c *gin.Context) {
 		return
 	}
 
-	// an SVG response is always served, even when error, so
-	// we can go ahead and set the content type appropriately.
-	c.Writer.Header().Set("Content-Type", "image/svg+xml")
+	name := path.Base(c.Request.URL.Path)
+	kind, format := strings.TrimSuffix(name, path.Ext(name)), path.Ext(name)
 
-	// if no commit was found then display
-	// the 'none' badge, instead of throwing
-	// an error response
+	label := c.Query("label")
+	if len(label) == 0 {
+		label = kind
+		if kind == "status" {
+			label = c.DefaultQuery("workflow", "build")
+		}
+	}
+
+	// status badges do not include pull request results by default
+	// as they do not represent the state of the branch.
+	event := c.DefaultQuery("event", model.EventPush)
 	branch := c.Query("branch")
-	if len(branch) == 0 {
+	if len(branch) == 0 && event == model.EventPush {
 		branch = repo.Branch
 	}
 
-	build, err := store_.GetBuildLast(repo, branch)
+	// if no build was found then display
+	// the 'none' badge, instead of throwing
+	// an error response
+	badge := badges.None(label)
+	build, err := store_.GetBuildLastEvent(repo, branch, event)
 	if err != nil {
-		log.Warn().Err(err).Msg("")
-		c.String(200, badgeNone)
+		log.Debug().Err(err).Msgf("badge: no build of %s", repo.FullName)
+	} else if badge, err = buildBadge(c, store_, kind, label, build); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
 
-	switch build.Status {
-	case model.StatusSuccess:
-		c.String(200, badgeSuccess)
-	case model.StatusFailure:
-		c.String(200, badgeFailure)
-	case model.StatusError, model.StatusKilled:
-		c.String(200, badgeError)
-	case model.StatusPending, model.StatusRunning:
-		c.String(200, badgeStarted)
-	default:
-		c.String(200, badgeNone)
+	var data []byte
+	if format == ".json" {
+		c.Header("Content-Type", "application/json")
+		data, _ = json.Marshal(badge.Shields())
+	} else {
+		c.Header("Content-Type", "image/svg+xml")
+		data = badge.SVG()
+	}
+
+	// badges must be revalidated on every request, the etag avoids
+	// sending unchanged badges again.
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(data))
+	c.Header("Cache-Control", "no-cache, max-age=0, must-revalidate")
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, c.Writer.Header().Get("Content-Type"), data)
+}
+
+// buildBadge returns the badge of the kind for the build, or for the
+// workflows of the build selected by the query parameters.
+func buildBadge(c *gin.Context, store_ store.Store, kind, label string, build *model.Build) (*badges.Badge, error) {
+	procs, err := store_.ProcList(build)
+	if err != nil {
+		return nil, err
+	}
+	workflows := badgeWorkflows(c, procs)
+	filtered := len(workflows) != 0 || c.Query("workflow") != "" || c.Query("platform") != "" || len(matrixQuery(c)) != 0
+	if filtered && len(workflows) == 0 {
+		return badges.None(label), nil
+	}
+
+	switch kind {
+	case "duration":
+		started, finished := build.Started, build.Finished
+		if filtered {
+			started, finished = workflowsDuration(workflows)
+		}
+		if started == 0 || finished == 0 {
+			return badges.Status(label, build.Status), nil
+		}
+		return badges.Duration(label, time.Duration(finished-started)*time.Second), nil
+
+	case "coverage":
+		files, err := store_.CoverageList(build)
+		if err != nil {
+			return nil, err
+		}
+		coverage := buildCoverage(procs, workflows, files, filtered)
+		if coverage == nil {
+			return badges.None(label), nil
+		}
+		return badges.Coverage(label, coverage.Covered), nil
+	}
+
+	if filtered {
+		return badges.Status(label, workflowsStatus(workflows)), nil
+	}
+	return badges.Status(label, build.Status), nil
+}
+
+// badgeWorkflows returns the workflows with the name, platform and matrix
+// values of the query parameters.
+func badgeWorkflows(c *gin.Context, procs []*model.Proc) []*model.Proc {
+	name, platform, matrix := c.Query("workflow"), c.Query("platform"), matrixQuery(c)
+	if name == "" && platform == "" && len(matrix) == 0 {
+		return nil
+	}
+
+	var workflows []*model.Proc
+	for _, proc := range procs {
+		if proc.PPID != 0 || (name != "" && proc.Name != name) || (platform != "" && proc.Platform != platform) {
+			continue
+		}
+		matches := true
+		for k, v := range matrix {
+			if proc.Environ[k] != v {
+				matches = false
+			}
+		}
+		if matches {
+			workflows = append(workflows, proc)
+		}
+	}
+	return workflows
+}
+
+// matrixQuery returns the matrix values of the matrix.<name>=<value> query
+// parameters.
+func matrixQuery(c *gin.Context) map[string]string {
+	matrix := map[string]string{}
+	for k, v := range c.Request.URL.Query() {
+		if strings.HasPrefix(k, "matrix.") && len(v) != 0 {
+			matrix[strings.TrimPrefix(k, "matrix.")] = v[0]
+		}
+	}
+	return matrix
+}
+
+// workflowsStatus returns the status of the workflows like the build
+// status is derived from them.
+func workflowsStatus(workflows []*model.Proc) string {
+	status := model.StatusSuccess
+	for _, workflow := range workflows {
+		switch {
+		case workflow.Running():
+			return model.StatusRunning
+		case workflow.State == model.StatusError || workflow.State == model.StatusKilled:
+			status = model.StatusError
+		case workflow.State == model.StatusFailure && status != model.StatusError:
+			status = model.StatusFailure
+		}
+	}
+	return status
+}
+
+// workflowsDuration returns the start and end time of the workflows, the
+// end time is zero until all workflows finished.
+func workflowsDuration(workflows []*model.Proc) (started, finished int64) {
+	for _, workflow := range workflows {
+		if workflow.Running() {
+			return 0, 0
+		}
+		if started == 0 || (workflow.Started != 0 && workflow.Started < started) {
+			started = workflow.Started
+		}
+		if workflow.Stopped > finished {
+			finished = workflow.Stopped
+		}
+	}
+	return started, finished
+}
+
+// buildCoverage returns the coverage of the build, or of the steps of the
+// workflows if filtered.
+func buildCoverage(procs, workflows []*model.Proc, files []*model.CoverageFile, filtered bool) *model.Coverage {
+	if !filtered {
+		return model.NewCoverage(files)
+	}
+
+	steps := map[int]bool{}
+	for _, proc := range procs {
+		for _, workflow := range workflows {
+			if proc.PPID == workflow.PID {
+				steps[proc.PID] = true
+			}
+		}
+	}
+	var selected []*model.CoverageFile
+	for _, file := range files {
+		if steps[file.PID] {
+			selected = append(selected, file)
+		}
 	}
+	return model.NewCoverage(selected)
 }
 
 func GetCC(c *gin.Context) {
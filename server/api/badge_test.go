@@ -0,0 +1,89 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+var badgeProcs = []*model.Proc{
+	{PID: 1, Name: "test", Platform: "linux/amd64", State: model.StatusSuccess, Started: 10, Stopped: 40, Environ: map[string]string{"GO": "1.16"}},
+	{PID: 2, PPID: 1, Name: "test", State: model.StatusSuccess},
+	{PID: 3, Name: "test", Platform: "linux/arm64", State: model.StatusFailure, Started: 20, Stopped: 50, Environ: map[string]string{"GO": "1.17"}},
+	{PID: 4, PPID: 3, Name: "test", State: model.StatusFailure},
+	{PID: 5, Name: "lint", Platform: "linux/amd64", State: model.StatusRunning, Started: 5},
+}
+
+func badgeContext(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/badges/octocat/hello-world/status.svg?"+query, nil)
+	return c
+}
+
+func TestBadgeWorkflows(t *testing.T) {
+	pids := func(query string) []int {
+		var pids []int
+		for _, proc := range badgeWorkflows(badgeContext(query), badgeProcs) {
+			pids = append(pids, proc.PID)
+		}
+		return pids
+	}
+	assert.Nil(t, pids(""))
+	assert.Equal(t, []int{1, 3}, pids("workflow=test"))
+	assert.Equal(t, []int{1, 5}, pids("platform=linux/amd64"))
+	assert.Equal(t, []int{3}, pids("workflow=test&matrix.GO=1.17"))
+	assert.Nil(t, pids("workflow=build"))
+}
+
+func TestWorkflowsStatus(t *testing.T) {
+	assert.Equal(t, model.StatusSuccess, workflowsStatus(badgeProcs[:1]))
+	assert.Equal(t, model.StatusFailure, workflowsStatus([]*model.Proc{badgeProcs[0], badgeProcs[2]}))
+	assert.Equal(t, model.StatusRunning, workflowsStatus([]*model.Proc{badgeProcs[2], badgeProcs[4]}))
+}
+
+func TestWorkflowsDuration(t *testing.T) {
+	started, finished := workflowsDuration([]*model.Proc{badgeProcs[0], badgeProcs[2]})
+	assert.Equal(t, int64(10), started)
+	assert.Equal(t, int64(50), finished)
+
+	started, finished = workflowsDuration(badgeProcs[4:])
+	assert.Zero(t, started)
+	assert.Zero(t, finished)
+}
+
+func TestBuildCoverage(t *testing.T) {
+	files := []*model.CoverageFile{
+		{PID: 2, Name: "main.go", CoveredLines: 30, TotalLines: 40},
+		{PID: 4, Name: "util.go", CoveredLines: 10, TotalLines: 60},
+	}
+
+	coverage := buildCoverage(badgeProcs, nil, files, false)
+	if assert.NotNil(t, coverage) {
+		assert.Equal(t, 40.0, coverage.Covered)
+	}
+
+	coverage = buildCoverage(badgeProcs, badgeProcs[:1], files, true)
+	if assert.NotNil(t, coverage) {
+		assert.Equal(t, 75.0, coverage.Covered)
+	}
+
+	assert.Nil(t, buildCoverage(badgeProcs, badgeProcs[4:], files, true))
+}
@@ -75,12 +75,42 @@ func GetBuild(c *gin.Context) {
 	}
 	files, _ := store_.FileList(build)
 	procs, _ := store_.ProcList(build)
+	tests, _ := store_.TestList(build)
+	approvals, _ := store_.ApprovalList(build)
 	build.Procs = model.Tree(procs)
 	build.Files = files
+	build.Tests = model.NewTestSummary(tests)
+	build.Approvals = approvals
 
 	c.JSON(http.StatusOK, build)
 }
 
+// GetBuildConfig returns the pipeline configs the build was created from.
+// The configs contain the resolved includes, only pushers can read them.
+func GetBuildConfig(c *gin.Context) {
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+	num, err := strconv.ParseInt(c.Param("number"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	build, err := store_.GetBuildNumber(repo, num)
+	if err != nil {
+		c.AbortWithError(http.StatusNotFound, err)
+		return
+	}
+
+	configs, err := server.Config.Storage.Config.ConfigsForBuild(build.ID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error getting configs for build. %s", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, configs)
+}
+
 func GetBuildLast(c *gin.Context) {
 	store_ := store.FromContext(c)
 	repo := session.Repo(c)
@@ -259,10 +289,71 @@ func PostApproval(c *gin.Context) {
 		return
 	}
 	if build.Status != model.StatusBlocked {
-		c.String(500, "cannot decline a build with status %s", build.Status)
+		c.String(500, "cannot approve a build with status %s", build.Status)
+		return
+	}
+	if ok, err := shared.CanApprove(c, remote_, repo, user); err != nil {
+		c.String(500, "failed to get the teams of %s. %s", user.Login, err)
+		return
+	} else if !ok {
+		c.String(403, "only members of the approval organizations can approve builds")
 		return
 	}
+	if build.Sender == user.Login {
+		protected, err := shared.DeploymentBlocked(store_, repo, build)
+		if err != nil {
+			c.String(403, "Error deploying to %s. %s", build.Deploy, err)
+			return
+		} else if protected {
+			c.String(403, "protected deployments cannot be approved by their sender")
+			return
+		}
+	}
 
+	// record the approval, the build starts once it has the required
+	// number of approvals
+	approvals, err := store_.ApprovalList(build)
+	if err != nil {
+		c.AbortWithError(500, err)
+		return
+	}
+	for _, approval := range approvals {
+		if approval.Login == user.Login {
+			c.String(409, "build is already approved by %s", user.Login)
+			return
+		}
+	}
+	approval := &model.Approval{
+		BuildID: build.ID,
+		Login:   user.Login,
+		Created: time.Now().Unix(),
+	}
+	if err := store_.ApprovalCreate(approval); err != nil {
+		c.AbortWithError(500, err)
+		return
+	}
+	recordAudit(c, model.AuditBuildApprove, repo, strconv.FormatInt(build.Number, 10), nil)
+
+	// count the approvals again, concurrent approvals are inserted between
+	// the list and the insert above. The build starts once it has the
+	// required number of approvals, only the first of the concurrent
+	// approvals updating the blocked build starts it.
+	approvals, err = store_.ApprovalList(build)
+	if err != nil {
+		c.AbortWithError(500, err)
+		return
+	}
+	build.Approvals = approvals
+	position := 0
+	for i, other := range approvals {
+		if other.ID == approval.ID {
+			position = i + 1
+		}
+	}
+	if position < repo.RequiredApprovals() {
+		c.JSON(200, build)
+		return
+	}
 	// fetch the build file from the database
 	configs, err := server.Config.Storage.Config.ConfigsForBuild(build.ID)
 	if err != nil {
@@ -277,10 +368,28 @@ func PostApproval(c *gin.Context) {
 		return
 	}
 
-	if build, err = shared.UpdateToStatusPending(store_, *build, user.Login); err != nil {
+	approved, started, err := shared.ApproveBlockedBuild(store_, *build, user.Login)
+	if err != nil {
 		c.String(500, "error updating build. %s", err)
 		return
 	}
+	if !started {
+		c.JSON(200, build)
+		return
+	}
+	build = approved
+
+	// restricted builds are blocked with unresolved includes of other
+	// repositories and templates, they are resolved once approved
+	configs, err = resolveApprovedIncludes(c, repo, build, configs)
+	if err != nil {
+		log.Error().Msgf("failure to resolve includes of build config for %s. %s", repo.FullName, err)
+		if _, err := shared.UpdateToStatusError(store_, *build, err); err != nil {
+			log.Error().Msgf("Error setting error status of build for %s#%d. %s", repo.FullName, build.Number, err)
+		}
+		c.String(400, "failure to resolve includes. %s", err)
+		return
+	}
 
 	c.JSON(200, build)
 
@@ -309,15 +418,16 @@ func PostApproval(c *gin.Context) {
 	}
 
 	b := shared.ProcBuilder{
-		Repo:  repo,
-		Curr:  build,
-		Last:  last,
-		Netrc: netrc,
-		Secs:  secs,
-		Regs:  regs,
-		Link:  server.Config.Server.Host,
-		Yamls: yamls,
-		Envs:  envs,
+		Repo:     repo,
+		Curr:     build,
+		Last:     last,
+		Netrc:    netrc,
+		Secs:     secs,
+		Regs:     regs,
+		Link:     server.Config.Server.Host,
+		Pipeline: server.Config.Pipeline,
+		Yamls:    yamls,
+		Envs:     envs,
 	}
 	buildItems, err := b.Build()
 	if err != nil {
@@ -370,11 +480,19 @@ func PostDecline(c *gin.Context) {
 		c.String(500, "cannot decline a build with status %s", build.Status)
 		return
 	}
+	if ok, err := shared.CanApprove(c, remote_, repo, user); err != nil {
+		c.String(500, "failed to get the teams of %s. %s", user.Login, err)
+		return
+	} else if !ok {
+		c.String(403, "only members of the approval organizations can decline builds")
+		return
+	}
 
 	if _, err = shared.UpdateToStatusDeclined(store_, *build, user.Login); err != nil {
 		c.String(500, "error updating build. %s", err)
 		return
 	}
+	recordAudit(c, model.AuditBuildDecline, repo, strconv.FormatInt(build.Number, 10), nil)
 
 	uri := fmt.Sprintf("%s/%s/%d", server.Config.Server.Host, repo.FullName, build.Number)
 	err = remote_.Status(c, user, repo, build, uri, nil)
@@ -396,7 +514,6 @@ func GetBuildQueue(c *gin.Context) {
 
 // PostBuild restarts a build
 func PostBuild(c *gin.Context) {
-	remote_ := remote.FromContext(c)
 	store_ := store.FromContext(c)
 	repo := session.Repo(c)
 
@@ -406,10 +523,43 @@ func PostBuild(c *gin.Context) {
 		return
 	}
 
-	user, err := store_.GetUser(repo.UserID)
+	build, err := store_.GetBuildNumber(repo, num)
 	if err != nil {
-		log.Error().Msgf("failure to find repo owner %s. %s", repo.FullName, err)
-		c.AbortWithError(500, err)
+		log.Error().Msgf("failure to get build %d. %s", num, err)
+		c.AbortWithError(404, err)
+		return
+	}
+
+	// Read query string parameters into buildParams, exclude reserved params
+	var buildParams = map[string]string{}
+	for key, val := range c.Request.URL.Query() {
+		switch key {
+		case "fork", "event", "deploy_to":
+		default:
+			// We only accept string literals, because build parameters will be
+			// injected as environment variables
+			buildParams[key] = val[0]
+		}
+	}
+
+	restartBuild(c, repo, build, c.DefaultQuery("event", build.Event), c.DefaultQuery("deploy_to", build.Deploy), buildParams)
+}
+
+// PostPromote promotes a successful build to the target environment. The
+// promotion is a deployment of the commit and configs of the build which
+// only runs the workflows and steps of the target environment.
+func PostPromote(c *gin.Context) {
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+
+	num, err := strconv.ParseInt(c.Param("number"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	target := c.Query("target")
+	if target == "" {
+		c.String(400, "missing the target environment of the promotion")
 		return
 	}
 
@@ -419,6 +569,39 @@ func PostBuild(c *gin.Context) {
 		c.AbortWithError(404, err)
 		return
 	}
+	if build.Status != model.StatusSuccess {
+		c.String(400, "cannot promote a build with status %s", build.Status)
+		return
+	}
+
+	// Read query string parameters into buildParams, exclude reserved params
+	var buildParams = map[string]string{}
+	for key, val := range c.Request.URL.Query() {
+		switch key {
+		case "target", "fork", "event", "deploy_to":
+		default:
+			buildParams[key] = val[0]
+		}
+	}
+
+	build.Promoted = true
+	restartBuild(c, repo, build, model.EventDeploy, target, buildParams)
+}
+
+// restartBuild creates a new build of the event and deployment target from
+// the configs of the build and queues it. Deployments to protected
+// environments are blocked until they are approved.
+func restartBuild(c *gin.Context, repo *model.Repo, build *model.Build, event, deployTo string, buildParams map[string]string) {
+	remote_ := remote.FromContext(c)
+	store_ := store.FromContext(c)
+	num := build.Number
+
+	user, err := store_.GetUser(repo.UserID)
+	if err != nil {
+		log.Error().Msgf("failure to find repo owner %s. %s", repo.FullName, err)
+		c.AbortWithError(500, err)
+		return
+	}
 
 	switch build.Status {
 	case model.StatusDeclined,
@@ -427,6 +610,13 @@ func PostBuild(c *gin.Context) {
 		return
 	}
 
+	// the branch of pull requests is their base branch, deploying them
+	// would run the code of the pull request with the rules of the branch
+	if event == model.EventDeploy && build.Event == model.EventPull {
+		c.String(403, "cannot deploy the pull request build %d", num)
+		return
+	}
+
 	// if the remote has a refresh token, the current access token
 	// may be stale. Therefore, we should refresh prior to dispatching
 	// the job.
@@ -445,6 +635,11 @@ func PostBuild(c *gin.Context) {
 		return
 	}
 
+	var yamls []*remote.FileMeta
+	for _, y := range configs {
+		yamls = append(yamls, &remote.FileMeta{Data: y.Data, Name: y.Name})
+	}
+
 	netrc, err := remote_.Netrc(user, repo)
 	if err != nil {
 		log.Error().Msgf("failure to generate netrc for %s. %s", repo.FullName, err)
@@ -460,15 +655,53 @@ func PostBuild(c *gin.Context) {
 	build.Finished = 0
 	build.Enqueued = time.Now().UTC().Unix()
 	build.Error = ""
-	build.Deploy = c.DefaultQuery("deploy_to", build.Deploy)
+	build.Reviewer = ""
+	build.Reviewed = 0
+	build.Deploy = deployTo
+	// the user restarting the build is its sender, protected deployments
+	// must be approved by another user
+	build.Sender = session.User(c).Login
 
-	event := c.DefaultQuery("event", build.Event)
 	if event == model.EventPush ||
 		event == model.EventPull ||
 		event == model.EventTag ||
 		event == model.EventDeploy {
 		build.Event = event
 	}
+	// only deployments are promotions, restarts of promotions stay promotions
+	if build.Event != model.EventDeploy {
+		build.Promoted = false
+	}
+
+	blocked, err := shared.DeploymentBlocked(store_, repo, build)
+	if err != nil {
+		c.String(403, "Error deploying to %s. %s", build.Deploy, err)
+		return
+	}
+	if blocked {
+		// parameters are not stored with the build and would be lost
+		// until the deployment is approved
+		if len(buildParams) != 0 {
+			c.String(400, "cannot pass parameters to deployments to %s, they require approval", build.Deploy)
+			return
+		}
+		build.Status = model.StatusBlocked
+	}
+
+	// the config extension may generate other configs for the restart,
+	// they are evaluated before the build is created
+	yamls, extended := shared.ExtendConfigs(c, server.Config.Services.ConfigExtension, repo, build, yamls)
+	if extended {
+		err = shared.EvalConfigs(repo, build, shared.DefaultSystem, server.Config.Server.Host, yamls)
+		if err == nil {
+			err = shared.ResolveIncludes(c, shared.NewConfigResolver(remote_, store_, user, repo, build, shared.IsFork(repo, build)), yamls)
+		}
+		if err != nil {
+			log.Error().Msgf("failure to evaluate build config for %s. %s", repo.FullName, err)
+			c.AbortWithError(400, err)
+			return
+		}
+	}
 
 	err = store_.CreateBuild(build)
 	if err != nil {
@@ -476,23 +709,28 @@ func PostBuild(c *gin.Context) {
 		return
 	}
 
-	err = persistBuildConfigs(configs, build.ID)
+	if extended {
+		for _, y := range yamls {
+			if _, err = findOrPersistPipelineConfig(repo, build, y); err != nil {
+				break
+			}
+		}
+	} else {
+		err = persistBuildConfigs(configs, build.ID)
+	}
 	if err != nil {
 		log.Error().Msgf("failure to persist build config for %s. %s", repo.FullName, err)
+		if _, err := shared.UpdateToStatusError(store_, *build, err); err != nil {
+			log.Error().Msgf("Error setting error status of build for %s#%d. %s", repo.FullName, build.Number, err)
+		}
 		c.AbortWithError(500, err)
 		return
 	}
 
-	// Read query string parameters into buildParams, exclude reserved params
-	var buildParams = map[string]string{}
-	for key, val := range c.Request.URL.Query() {
-		switch key {
-		case "fork", "event", "deploy_to":
-		default:
-			// We only accept string literals, because build parameters will be
-			// injected as environment variables
-			buildParams[key] = val[0]
-		}
+	// deployments to protected environments start once they are approved
+	if build.Status == model.StatusBlocked {
+		c.JSON(202, build)
+		return
 	}
 
 	// get the previous build so that we can send
@@ -513,21 +751,17 @@ func PostBuild(c *gin.Context) {
 		}
 	}
 
-	var yamls []*remote.FileMeta
-	for _, y := range configs {
-		yamls = append(yamls, &remote.FileMeta{Data: y.Data, Name: y.Name})
-	}
-
 	b := shared.ProcBuilder{
-		Repo:  repo,
-		Curr:  build,
-		Last:  last,
-		Netrc: netrc,
-		Secs:  secs,
-		Regs:  regs,
-		Link:  server.Config.Server.Host,
-		Yamls: yamls,
-		Envs:  buildParams,
+		Repo:     repo,
+		Curr:     build,
+		Last:     last,
+		Netrc:    netrc,
+		Secs:     secs,
+		Regs:     regs,
+		Link:     server.Config.Server.Host,
+		Pipeline: server.Config.Pipeline,
+		Yamls:    yamls,
+		Envs:     buildParams,
 	}
 	buildItems, err := b.Build()
 	if err != nil {
@@ -556,6 +790,166 @@ func PostBuild(c *gin.Context) {
 	queueBuild(build, repo, buildItems)
 }
 
+// PostBuildRestart restarts the failed or the selected workflows of a
+// finished build, and the workflows depending on them, within the build.
+func PostBuildRestart(c *gin.Context) {
+	remote_ := remote.FromContext(c)
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+
+	num, err := strconv.ParseInt(c.Param("number"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := store_.GetUser(repo.UserID)
+	if err != nil {
+		log.Error().Msgf("failure to find repo owner %s. %s", repo.FullName, err)
+		c.AbortWithError(500, err)
+		return
+	}
+
+	build, err := store_.GetBuildNumber(repo, num)
+	if err != nil {
+		log.Error().Msgf("failure to get build %d. %s", num, err)
+		c.AbortWithError(404, err)
+		return
+	}
+
+	switch build.Status {
+	case model.StatusPending,
+		model.StatusRunning,
+		model.StatusDeclined,
+		model.StatusBlocked:
+		c.String(400, "cannot restart the workflows of a build with status %s", build.Status)
+		return
+	}
+
+	procs, err := store_.ProcList(build)
+	if err != nil {
+		c.AbortWithError(404, err)
+		return
+	}
+
+	var pids []int
+	if c.Query("failed") == "true" {
+		pids = shared.FailedWorkflows(procs)
+	}
+	for _, pid := range c.QueryArray("pid") {
+		n, err := strconv.Atoi(pid)
+		if err != nil {
+			c.String(400, "Error parsing workflow pid %q. %s", pid, err)
+			return
+		}
+		pids = append(pids, n)
+	}
+	if len(pids) == 0 {
+		c.String(400, "no workflows to restart")
+		return
+	}
+
+	// if the remote has a refresh token, the current access token
+	// may be stale. Therefore, we should refresh prior to dispatching
+	// the job.
+	if refresher, ok := remote_.(remote.Refresher); ok {
+		ok, _ := refresher.Refresh(c, user)
+		if ok {
+			store_.UpdateUser(user)
+		}
+	}
+
+	configs, err := server.Config.Storage.Config.ConfigsForBuild(build.ID)
+	if err != nil {
+		log.Error().Msgf("failure to get build config for %s. %s", repo.FullName, err)
+		c.AbortWithError(404, err)
+		return
+	}
+
+	netrc, err := remote_.Netrc(user, repo)
+	if err != nil {
+		log.Error().Msgf("failure to generate netrc for %s. %s", repo.FullName, err)
+		c.AbortWithError(500, err)
+		return
+	}
+
+	last, _ := store_.GetBuildLastBefore(repo, build.Branch, build.ID)
+	secs, err := server.Config.Services.Secrets.SecretListBuild(repo, build)
+	if err != nil {
+		log.Debug().Msgf("Error getting secrets for %s#%d. %s", repo.FullName, build.Number, err)
+	}
+	regs, err := server.Config.Services.Registries.RegistryList(repo)
+	if err != nil {
+		log.Debug().Msgf("Error getting registry credentials for %s#%d. %s", repo.FullName, build.Number, err)
+	}
+	envs := map[string]string{}
+	if server.Config.Services.Environ != nil {
+		globals, _ := server.Config.Services.Environ.EnvironList(repo)
+		for _, global := range globals {
+			envs[global.Name] = global.Value
+		}
+	}
+
+	var yamls []*remote.FileMeta
+	for _, y := range configs {
+		yamls = append(yamls, &remote.FileMeta{Data: y.Data, Name: y.Name})
+	}
+
+	b := shared.ProcBuilder{
+		Repo:     repo,
+		Curr:     build,
+		Last:     last,
+		Netrc:    netrc,
+		Secs:     secs,
+		Regs:     regs,
+		Link:     server.Config.Server.Host,
+		Pipeline: server.Config.Pipeline,
+		Yamls:    yamls,
+		Envs:     envs,
+	}
+	buildItems, err := b.Build()
+	if err != nil {
+		log.Error().Msgf("cannot restart workflows of %s#%d: %s", repo.FullName, build.Number, err)
+		c.String(500, "Error building the workflows. %s", err)
+		return
+	}
+
+	reset, err := shared.RestartWorkflows(buildItems, procs, pids)
+	if err != nil {
+		c.String(400, "Error restarting the workflows. %s", err)
+		return
+	}
+	for _, proc := range reset {
+		if err := store_.ProcUpdate(proc); err != nil {
+			c.String(500, "Error updating proc %d. %s", proc.PID, err)
+			return
+		}
+		// the logs and test results of the previous run are replaced by the
+		// new run.
+		if proc.PPID != 0 {
+			if err := store_.LogSave(proc, bytes.NewReader(nil)); err != nil {
+				log.Error().Msgf("failure to clear logs of proc %d of %s#%d. %s", proc.PID, repo.FullName, build.Number, err)
+			}
+			if err := store_.TestDelete(proc); err != nil {
+				log.Error().Msgf("failure to clear test results of proc %d of %s#%d. %s", proc.PID, repo.FullName, build.Number, err)
+			}
+		}
+	}
+
+	build.Status = model.StatusPending
+	build.Finished = 0
+	build.Error = ""
+	if err := store_.UpdateBuild(build); err != nil {
+		c.String(500, "Error updating build. %s", err)
+		return
+	}
+	build.Procs = procs
+	c.JSON(202, build)
+
+	publishToTopic(c, build, repo, model.Enqueued)
+	queueBuild(build, repo, buildItems)
+}
+
 func DeleteBuildLogs(c *gin.Context) {
 	store_ := store.FromContext(c)
 
@@ -597,6 +991,44 @@ func DeleteBuildLogs(c *gin.Context) {
 	c.String(204, "")
 }
 
+// resolveApprovedIncludes resolves the includes of the configs of the
+// approved build and replaces the stored configs which changed. Configs
+// without includes are returned unchanged.
+func resolveApprovedIncludes(c *gin.Context, repo *model.Repo, build *model.Build, configs []*model.Config) ([]*model.Config, error) {
+	remote_ := remote.FromContext(c)
+	store_ := store.FromContext(c)
+
+	owner, err := store_.GetUser(repo.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var yamls []*remote.FileMeta
+	for _, y := range configs {
+		yamls = append(yamls, &remote.FileMeta{Data: y.Data, Name: y.Name})
+	}
+	if err := shared.ResolveIncludes(c, shared.NewConfigResolver(remote_, store_, owner, repo, build, false), yamls); err != nil {
+		return nil, err
+	}
+
+	resolved := make([]*model.Config, 0, len(configs))
+	for i, y := range yamls {
+		if bytes.Equal(y.Data, configs[i].Data) {
+			resolved = append(resolved, configs[i])
+			continue
+		}
+		conf, err := findOrPersistPipelineConfig(repo, build, y)
+		if err != nil {
+			return nil, err
+		}
+		err = server.Config.Storage.Config.BuildConfigDelete(&model.BuildConfig{ConfigID: configs[i].ID, BuildID: build.ID})
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, conf)
+	}
+	return resolved, nil
+}
+
 func persistBuildConfigs(configs []*model.Config, buildID int64) error {
 	for _, conf := range configs {
 		buildConfig := &model.BuildConfig{
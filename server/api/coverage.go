@@ -0,0 +1,80 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/router/middleware/session"
+	"github.com/woodpecker-ci/woodpecker/server/store"
+)
+
+// GetBuildCoverage returns the coverage of the build compared to the last
+// push build of the branch before it, for pull requests this is the last
+// build of the target branch. The changed query parameter limits the files
+// to the files changed by the build.
+func GetBuildCoverage(c *gin.Context) {
+	store_ := store.FromContext(c)
+	num, err := strconv.ParseInt(c.Param("number"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	repo := session.Repo(c)
+	build, err := store_.GetBuildNumber(repo, num)
+	if err != nil {
+		c.AbortWithError(http.StatusNotFound, err)
+		return
+	}
+
+	files, err := store_.CoverageList(build)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	coverage := model.NewCoverage(files)
+	if coverage == nil {
+		c.String(http.StatusNotFound, "Error getting coverage. The build has no coverage reports")
+		return
+	}
+
+	if base, err := store_.GetBuildLastEventBefore(repo, build.Branch, model.EventPush, build.Number); err == nil {
+		baseFiles, err := store_.CoverageList(base)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		if baseCoverage := model.NewCoverage(baseFiles); baseCoverage != nil {
+			coverage.Compare(base.Number, baseCoverage)
+		}
+	}
+	coverage.Diff(build.ChangedFiles)
+
+	if changed, _ := strconv.ParseBool(c.Query("changed")); changed {
+		files := []*model.CoverageChange{}
+		for _, file := range coverage.Files {
+			if file.Changed {
+				files = append(files, file)
+			}
+		}
+		coverage.Files = files
+	}
+	c.JSON(http.StatusOK, coverage)
+}
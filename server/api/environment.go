@@ -0,0 +1,197 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/router/middleware/session"
+	"github.com/woodpecker-ci/woodpecker/server/store"
+)
+
+// GetEnvironmentList returns the deployment environments of the repository
+// with their latest successful deployment.
+func GetEnvironmentList(c *gin.Context) {
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+
+	envs, err := store_.EnvironmentList(repo)
+	if err != nil {
+		c.String(500, "Error getting environment list. %s", err)
+		return
+	}
+	for _, env := range envs {
+		env.Deployed, _ = store_.GetDeploymentLast(repo, env.Name)
+	}
+	c.JSON(200, envs)
+}
+
+// GetEnvironment returns the deployment environment with its latest
+// successful deployment.
+func GetEnvironment(c *gin.Context) {
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+	name := c.Param("environment")
+
+	env, err := store_.EnvironmentFind(repo, name)
+	if err != nil {
+		c.String(404, "Error getting environment %q. %s", name, err)
+		return
+	}
+	env.Deployed, _ = store_.GetDeploymentLast(repo, env.Name)
+	c.JSON(200, env)
+}
+
+// PostEnvironment creates a deployment environment of the repository.
+func PostEnvironment(c *gin.Context) {
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+
+	in := new(model.Environment)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing environment. %s", err)
+		return
+	}
+	env := &model.Environment{
+		RepoID:          repo.ID,
+		Name:            in.Name,
+		Branches:        in.Branches,
+		RequireApproval: in.RequireApproval,
+	}
+	if err := env.Validate(); err != nil {
+		c.String(400, "Error inserting environment. %s", err)
+		return
+	}
+	if err := store_.EnvironmentCreate(env); err != nil {
+		c.String(500, "Error inserting environment %q. %s", in.Name, err)
+		return
+	}
+	recordAudit(c, model.AuditEnvCreate, repo, env.Name, environmentRules(env))
+	c.JSON(200, env)
+}
+
+// PatchEnvironment updates the protection rules of the deployment
+// environment.
+func PatchEnvironment(c *gin.Context) {
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+	name := c.Param("environment")
+
+	in := new(model.EnvironmentPatch)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing environment. %s", err)
+		return
+	}
+
+	env, err := store_.EnvironmentFind(repo, name)
+	if err != nil {
+		c.String(404, "Error getting environment %q. %s", name, err)
+		return
+	}
+	if in.Branches != nil {
+		env.Branches = *in.Branches
+	}
+	if in.RequireApproval != nil {
+		env.RequireApproval = *in.RequireApproval
+	}
+
+	if err := env.Validate(); err != nil {
+		c.String(400, "Error updating environment. %s", err)
+		return
+	}
+	if err := store_.EnvironmentUpdate(env); err != nil {
+		c.String(500, "Error updating environment %q. %s", name, err)
+		return
+	}
+	recordAudit(c, model.AuditEnvUpdate, repo, env.Name, environmentRules(env))
+	c.JSON(200, env)
+}
+
+// DeleteEnvironment deletes the deployment environment, deployments to it
+// are no longer protected.
+func DeleteEnvironment(c *gin.Context) {
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+	name := c.Param("environment")
+
+	env, err := store_.EnvironmentFind(repo, name)
+	if err != nil {
+		c.String(404, "Error getting environment %q. %s", name, err)
+		return
+	}
+	if err := store_.EnvironmentDelete(env); err != nil {
+		c.String(500, "Error deleting environment %q. %s", name, err)
+		return
+	}
+	recordAudit(c, model.AuditEnvDelete, repo, name, nil)
+	c.String(204, "")
+}
+
+// GetDeployments returns the deployment history of the environment. The
+// environment does not have to be defined, every deployment target has a
+// history.
+func GetDeployments(c *gin.Context) {
+	repo := session.Repo(c)
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.String(400, "Error parsing page %q", c.Query("page"))
+		return
+	}
+
+	builds, err := store.FromContext(c).GetDeploymentList(repo, c.Param("environment"), page)
+	if err != nil {
+		c.AbortWithError(500, err)
+		return
+	}
+	c.JSON(200, builds)
+}
+
+// PostRollback deploys the commit of a previous successful deployment to
+// the environment again.
+func PostRollback(c *gin.Context) {
+	repo := session.Repo(c)
+	name := c.Param("environment")
+
+	num, err := strconv.ParseInt(c.Param("number"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	build, err := store.FromContext(c).GetBuildNumber(repo, num)
+	if err != nil {
+		c.AbortWithError(404, err)
+		return
+	}
+	if build.Event != model.EventDeploy || build.Deploy != name || build.Status != model.StatusSuccess {
+		c.String(400, "cannot roll back to build %d, it is not a successful deployment to %s", num, name)
+		return
+	}
+
+	restartBuild(c, repo, build, model.EventDeploy, name, map[string]string{})
+}
+
+// environmentRules returns the protection rules of the environment for
+// the audit log.
+func environmentRules(env *model.Environment) map[string]string {
+	return map[string]string{
+		"branches":         strings.Join(env.Branches, ","),
+		"require_approval": strconv.FormatBool(env.RequireApproval),
+	}
+}
@@ -174,6 +174,33 @@ func PostHook(c *gin.Context) {
 		c.AbortWithError(404, err)
 		return
 	}
+	remoteYamlConfigs, _ = shared.ExtendConfigs(c, server.Config.Services.ConfigExtension, repo, build, remoteYamlConfigs)
+
+	if err := shared.EvalConfigs(repo, build, shared.DefaultSystem, server.Config.Server.Host, remoteYamlConfigs); err != nil {
+		log.Error().Msgf("failure to evaluate starlark config from hook for %s. %s", repo.FullName, err)
+		c.AbortWithError(400, err)
+		return
+	}
+
+	// builds awaiting approval and pull requests from forks can only
+	// include files of the repository itself
+	gated := shared.BuildGated(c, remote_, store_, repo, build, user)
+	restricted := gated || shared.IsFork(repo, build)
+
+	// resolve the includes, the resolved configs are persisted below.
+	// Restricted builds including other repositories or templates are
+	// blocked, their includes are resolved once the build is approved.
+	unresolved := false
+	configResolver := shared.NewConfigResolver(remote_, store_, user, repo, build, restricted)
+	if err := shared.ResolveIncludes(c, configResolver, remoteYamlConfigs); err != nil {
+		if !restricted {
+			log.Error().Msgf("failure to resolve includes of yaml from hook for %s. %s", repo.FullName, err)
+			c.AbortWithError(400, err)
+			return
+		}
+		log.Debug().Msgf("blocking build of %s until its includes are approved. %s", repo.FullName, err)
+		unresolved = true
+	}
 
 	filtered, err := branchFiltered(build, remoteYamlConfigs)
 	if err != nil {
@@ -195,7 +222,17 @@ func PostHook(c *gin.Context) {
 	build.Verified = true
 	build.Status = model.StatusPending
 
-	if repo.IsGated && build.Sender != user.Login {
+	if gated || unresolved {
+		build.Status = model.StatusBlocked
+	}
+
+	blocked, err := shared.DeploymentBlocked(store_, repo, build)
+	if err != nil {
+		log.Error().Msgf("failure to deploy %s to %s. %s", repo.FullName, build.Deploy, err)
+		c.String(403, "Error deploying to %s. %s", build.Deploy, err)
+		return
+	}
+	if blocked {
 		build.Status = model.StatusBlocked
 	}
 
@@ -250,15 +287,16 @@ func PostHook(c *gin.Context) {
 	last, _ := store_.GetBuildLastBefore(repo, build.Branch, build.ID)
 
 	b := shared.ProcBuilder{
-		Repo:  repo,
-		Curr:  build,
-		Last:  last,
-		Netrc: netrc,
-		Secs:  secs,
-		Regs:  regs,
-		Envs:  envs,
-		Link:  server.Config.Server.Host,
-		Yamls: remoteYamlConfigs,
+		Repo:     repo,
+		Curr:     build,
+		Last:     last,
+		Netrc:    netrc,
+		Secs:     secs,
+		Regs:     regs,
+		Envs:     envs,
+		Link:     server.Config.Server.Host,
+		Pipeline: server.Config.Pipeline,
+		Yamls:    remoteYamlConfigs,
 	}
 	buildItems, err := b.Build()
 	if err != nil {
@@ -313,14 +351,15 @@ func branchFiltered(build *model.Build, remoteYamlConfigs []*remote.FileMeta) (b
 
 func zeroSteps(build *model.Build, remoteYamlConfigs []*remote.FileMeta) bool {
 	b := shared.ProcBuilder{
-		Repo:  &model.Repo{},
-		Curr:  build,
-		Last:  &model.Build{},
-		Netrc: &model.Netrc{},
-		Secs:  []*model.Secret{},
-		Regs:  []*model.Registry{},
-		Link:  "",
-		Yamls: remoteYamlConfigs,
+		Repo:     &model.Repo{},
+		Curr:     build,
+		Last:     &model.Build{},
+		Netrc:    &model.Netrc{},
+		Secs:     []*model.Secret{},
+		Regs:     []*model.Registry{},
+		Link:     "",
+		Yamls:    remoteYamlConfigs,
+		Pipeline: server.Config.Pipeline,
 	}
 
 	buildItems, err := b.Build()
@@ -366,7 +405,7 @@ func findOrPersistPipelineConfig(repo *model.Repo, build *model.Build, remoteYam
 	return conf, nil
 }
 
-// publishes message to UI clients
+// publishes message to UI clients and webhooks
 func publishToTopic(c *gin.Context, build *model.Build, repo *model.Repo, event model.EventType) {
 	message := pubsub.Message{
 		Labels: map[string]string{
@@ -377,17 +416,19 @@ func publishToTopic(c *gin.Context, build *model.Build, repo *model.Repo, event
 	buildCopy := *build
 	buildCopy.Procs = model.Tree(buildCopy.Procs)
 	message.Data, _ = json.Marshal(model.Event{
-		Type:  model.Enqueued,
+		Type:  event,
 		Repo:  *repo,
 		Build: buildCopy,
 	})
 	server.Config.Services.Pubsub.Publish(c, "topic/events", message)
 }
 
+// queueBuild queues the pending build items. Items which are not pending,
+// like skipped items or finished items of restarted builds, are not queued.
 func queueBuild(build *model.Build, repo *model.Repo, buildItems []*shared.BuildItem) {
 	var tasks []*queue.Task
 	for _, item := range buildItems {
-		if item.Proc.State == model.StatusSkipped {
+		if item.Proc.State != model.StatusPending {
 			continue
 		}
 		task := new(queue.Task)
@@ -400,7 +441,7 @@ func queueBuild(build *model.Build, repo *model.Repo, buildItems []*shared.Build
 		task.Labels["repo"] = repo.FullName
 		task.Dependencies = taskIds(item.DependsOn, buildItems)
 		task.RunOn = item.RunsOn
-		task.DepStatus = make(map[string]string)
+		task.DepStatus = depStatus(item.DependsOn, buildItems)
 
 		task.Data, _ = json.Marshal(rpc.Pipeline{
 			ID:      fmt.Sprint(item.Proc.ID),
@@ -425,6 +466,20 @@ func taskIds(dependsOn []string, buildItems []*shared.BuildItem) (taskIds []stri
 	return
 }
 
+// depStatus returns the status of the finished dependencies, which are
+// not queued again when workflows of a build are restarted.
+func depStatus(dependsOn []string, buildItems []*shared.BuildItem) map[string]string {
+	status := make(map[string]string)
+	for _, dep := range dependsOn {
+		for _, buildItem := range buildItems {
+			if buildItem.Proc.Name == dep && !buildItem.Proc.Running() && buildItem.Proc.State != model.StatusSkipped {
+				status[fmt.Sprint(buildItem.Proc.ID)] = buildItem.Proc.State
+			}
+		}
+	}
+	return status
+}
+
 func shasum(raw []byte) string {
 	sum := sha256.Sum256(raw)
 	return fmt.Sprintf("%x", sum)
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/securecookie"
@@ -113,25 +114,52 @@ func PatchRepo(c *gin.Context) {
 		return
 	}
 
+	changes := map[string]string{}
 	if in.AllowPull != nil {
 		repo.AllowPull = *in.AllowPull
+		changes["allow_pr"] = strconv.FormatBool(repo.AllowPull)
 	}
 	if in.IsGated != nil {
 		repo.IsGated = *in.IsGated
+		changes["gated"] = strconv.FormatBool(repo.IsGated)
+	}
+	if in.ApprovalCount != nil {
+		if *in.ApprovalCount < 0 {
+			c.String(400, "Invalid approval count")
+			return
+		}
+		repo.ApprovalCount = *in.ApprovalCount
+		changes["approval_count"] = strconv.Itoa(repo.ApprovalCount)
+	}
+	if in.ApprovalOrgs != nil {
+		repo.ApprovalOrgs = *in.ApprovalOrgs
+		changes["approval_orgs"] = strings.Join(repo.ApprovalOrgs, ",")
+	}
+	if in.ApproveMembers != nil {
+		repo.ApproveMembers = *in.ApproveMembers
+		changes["approve_members"] = strconv.FormatBool(repo.ApproveMembers)
+	}
+	if in.GateForks != nil {
+		repo.GateForks = *in.GateForks
+		changes["gate_forks"] = strconv.FormatBool(repo.GateForks)
 	}
 	if in.IsTrusted != nil {
 		repo.IsTrusted = *in.IsTrusted
+		changes["trusted"] = strconv.FormatBool(repo.IsTrusted)
 	}
 	if in.Timeout != nil {
 		repo.Timeout = *in.Timeout
+		changes["timeout"] = strconv.FormatInt(repo.Timeout, 10)
 	}
 	if in.Config != nil {
 		repo.Config = *in.Config
+		changes["config_file"] = repo.Config
 	}
 	if in.Visibility != nil {
 		switch *in.Visibility {
 		case model.VisibilityInternal, model.VisibilityPrivate, model.VisibilityPublic:
 			repo.Visibility = *in.Visibility
+			changes["visibility"] = repo.Visibility
 		default:
 			c.String(400, "Invalid visibility type")
 			return
@@ -139,6 +167,7 @@ func PatchRepo(c *gin.Context) {
 	}
 	if in.BuildCounter != nil {
 		repo.Counter = *in.BuildCounter
+		changes["build_counter"] = strconv.FormatInt(repo.Counter, 10)
 	}
 
 	err := store_.UpdateRepo(repo)
@@ -146,6 +175,7 @@ func PatchRepo(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	recordAudit(c, model.AuditRepoUpdate, repo, "", changes)
 
 	c.JSON(http.StatusOK, repo)
 }
@@ -161,6 +191,7 @@ func ChownRepo(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	recordAudit(c, model.AuditRepoChown, repo, user.Login, nil)
 	c.JSON(http.StatusOK, repo)
 }
 
@@ -295,6 +326,7 @@ func MoveRepo(c *gin.Context) {
 		return
 	}
 
+	previous := repo.FullName
 	repo.Name = from.Name
 	repo.Owner = from.Owner
 	repo.FullName = from.FullName
@@ -311,6 +343,9 @@ func MoveRepo(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, errStore)
 		return
 	}
+	recordAudit(c, model.AuditRepoMove, repo, repo.FullName, map[string]string{
+		"from": previous,
+	})
 
 	// creates the jwt token used to verify the repository
 	t := token.New(token.HookToken, repo.FullName)
@@ -337,3 +372,21 @@ func MoveRepo(c *gin.Context) {
 	}
 	c.Writer.WriteHeader(http.StatusOK)
 }
+
+// GetRepoHook writes the hook link of the repository to the response
+// in json format. It is used to configure the hook manually for remotes
+// that cannot register hooks themselves.
+func GetRepoHook(c *gin.Context) {
+	repo := session.Repo(c)
+
+	t := token.New(token.HookToken, repo.FullName)
+	sig, err := t.Sign(repo.Hash)
+	if err != nil {
+		c.String(500, err.Error())
+		return
+	}
+
+	c.JSON(200, map[string]string{
+		"url": fmt.Sprintf("%s/hook?access_token=%s", server.Config.Server.Host, sig),
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/router/middleware/session"
+	"github.com/woodpecker-ci/woodpecker/server/store"
+)
+
+// GetOrgRoles gets the roles granted for all repositories
+// of an organization and writes them to the response in json format.
+func GetOrgRoles(c *gin.Context) {
+	roles, err := store.FromContext(c).RoleList(c.Param("owner"))
+	if err != nil {
+		c.String(500, "Error getting role list. %s", err)
+		return
+	}
+	c.JSON(200, roles)
+}
+
+// PostOrgRole grants a role for all repositories of an organization.
+func PostOrgRole(c *gin.Context) {
+	in := new(model.Role)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing role. %s", err)
+		return
+	}
+	role := &model.Role{
+		Owner: c.Param("owner"),
+		Login: in.Login,
+		Name:  in.Name,
+	}
+	createRole(c, role)
+}
+
+// DeleteOrgRole revokes a role granted for an organization.
+func DeleteOrgRole(c *gin.Context) {
+	roles, err := store.FromContext(c).RoleList(c.Param("owner"))
+	if err != nil {
+		c.String(500, "Error getting role list. %s", err)
+		return
+	}
+	deleteRole(c, roles)
+}
+
+// GetRepoRoles gets the roles granted for a repository
+// and writes them to the response in json format.
+func GetRepoRoles(c *gin.Context) {
+	roles, err := store.FromContext(c).RoleListRepo(session.Repo(c))
+	if err != nil {
+		c.String(500, "Error getting role list. %s", err)
+		return
+	}
+	c.JSON(200, roles)
+}
+
+// PostRepoRole grants a role for a single repository.
+func PostRepoRole(c *gin.Context) {
+	repo := session.Repo(c)
+
+	in := new(model.Role)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing role. %s", err)
+		return
+	}
+	role := &model.Role{
+		Owner:  repo.Owner,
+		RepoID: repo.ID,
+		Login:  in.Login,
+		Name:   in.Name,
+	}
+	createRole(c, role)
+}
+
+// DeleteRepoRole revokes a role granted for a repository.
+func DeleteRepoRole(c *gin.Context) {
+	roles, err := store.FromContext(c).RoleListRepo(session.Repo(c))
+	if err != nil {
+		c.String(500, "Error getting role list. %s", err)
+		return
+	}
+	deleteRole(c, roles)
+}
+
+func createRole(c *gin.Context, role *model.Role) {
+	if err := role.Validate(); err != nil {
+		c.String(400, "Error inserting role. %s", err)
+		return
+	}
+	if err := store.FromContext(c).RoleCreate(role); err != nil {
+		c.String(500, "Error inserting role %q for %q. %s", role.Name, role.Login, err)
+		return
+	}
+	recordAudit(c, model.AuditRoleCreate, session.Repo(c), role.Login, map[string]string{
+		"owner": role.Owner,
+		"role":  role.Name,
+	})
+	c.JSON(200, role)
+}
+
+func deleteRole(c *gin.Context, roles []*model.Role) {
+	var (
+		login = c.Param("login")
+		name  = c.Param("role")
+	)
+	for _, role := range roles {
+		if role.Login != login || role.Name != name {
+			continue
+		}
+		if err := store.FromContext(c).RoleDelete(role); err != nil {
+			c.String(500, "Error deleting role %q for %q. %s", name, login, err)
+			return
+		}
+		recordAudit(c, model.AuditRoleDelete, session.Repo(c), role.Login, map[string]string{
+			"owner": role.Owner,
+			"role":  role.Name,
+		})
+		c.String(204, "")
+		return
+	}
+	c.String(404, "Cannot find role %q for %q", name, login)
+}
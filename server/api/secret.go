@@ -16,6 +16,7 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -54,6 +55,8 @@ func PostSecret(c *gin.Context) {
 		Value:  in.Value,
 		Events: in.Events,
 		Images: in.Images,
+
+		Environments: in.Environments,
 	}
 	if err := secret.Validate(); err != nil {
 		c.String(400, "Error inserting secret. %s", err)
@@ -63,6 +66,7 @@ func PostSecret(c *gin.Context) {
 		c.String(500, "Error inserting secret %q. %s", in.Name, err)
 		return
 	}
+	recordAudit(c, model.AuditSecretCreate, repo, secret.Name, nil)
 	c.JSON(200, secret.Copy())
 }
 
@@ -94,6 +98,9 @@ func PatchSecret(c *gin.Context) {
 	if len(in.Images) != 0 {
 		secret.Images = in.Images
 	}
+	if len(in.Environments) != 0 {
+		secret.Environments = in.Environments
+	}
 
 	if err := secret.Validate(); err != nil {
 		c.String(400, "Error updating secret. %s", err)
@@ -103,6 +110,9 @@ func PatchSecret(c *gin.Context) {
 		c.String(500, "Error updating secret %q. %s", in.Name, err)
 		return
 	}
+	recordAudit(c, model.AuditSecretUpdate, repo, secret.Name, map[string]string{
+		"value_changed": strconv.FormatBool(in.Value != ""),
+	})
 	c.JSON(200, secret.Copy())
 }
 
@@ -133,5 +143,6 @@ func DeleteSecret(c *gin.Context) {
 		c.String(500, "Error deleting secret %q. %s", name, err)
 		return
 	}
+	recordAudit(c, model.AuditSecretDelete, repo, name, nil)
 	c.String(204, "")
 }
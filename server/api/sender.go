@@ -0,0 +1,97 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/router/middleware/session"
+	"github.com/woodpecker-ci/woodpecker/server/store"
+)
+
+// GetSenderList returns the senders on the allow and block lists of the
+// repository.
+func GetSenderList(c *gin.Context) {
+	senders, err := store.FromContext(c).SenderList(session.Repo(c))
+	if err != nil {
+		c.String(500, "Error getting sender list. %s", err)
+		return
+	}
+	c.JSON(200, senders)
+}
+
+// PostSender adds the sender to the allow or block list of the repository,
+// or updates the lists of the sender.
+func PostSender(c *gin.Context) {
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+
+	in := new(model.Sender)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing request. %s", err)
+		return
+	}
+	if in.Login == "" || in.Allow == in.Block {
+		c.String(400, "Error inserting sender. A sender login is either allowed or blocked")
+		return
+	}
+
+	sender, err := store_.SenderFind(repo, in.Login)
+	if err == nil {
+		sender.Allow, sender.Block = in.Allow, in.Block
+		err = store_.SenderUpdate(sender)
+	} else {
+		sender = &model.Sender{
+			RepoID: repo.ID,
+			Login:  in.Login,
+			Allow:  in.Allow,
+			Block:  in.Block,
+		}
+		err = store_.SenderCreate(sender)
+	}
+	if err != nil {
+		c.String(500, "Error inserting sender %q. %s", in.Login, err)
+		return
+	}
+	recordAudit(c, model.AuditSenderUpdate, repo, sender.Login, map[string]string{
+		"allow": strconv.FormatBool(sender.Allow),
+		"block": strconv.FormatBool(sender.Block),
+	})
+	c.JSON(200, sender)
+}
+
+// DeleteSender removes the sender from the allow and block lists of the
+// repository.
+func DeleteSender(c *gin.Context) {
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+	login := c.Param("login")
+
+	sender, err := store_.SenderFind(repo, login)
+	if err != nil {
+		c.String(404, "Error getting sender %q. %s", login, err)
+		return
+	}
+	if err := store_.SenderDelete(sender); err != nil {
+		c.String(500, "Error deleting sender %q. %s", login, err)
+		return
+	}
+	recordAudit(c, model.AuditSenderDelete, repo, login, nil)
+	c.String(204, "")
+}
@@ -16,15 +16,20 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 
+	"github.com/woodpecker-ci/woodpecker/pipeline/rpc"
 	"github.com/woodpecker-ci/woodpecker/server"
 	"github.com/woodpecker-ci/woodpecker/server/logging"
 	"github.com/woodpecker-ci/woodpecker/server/model"
@@ -116,6 +121,12 @@ func EventStreamSSE(c *gin.Context) {
 	}
 }
 
+// LogStreamSSE streams the logs of a step, of all steps of a workflow or,
+// without a pid, of all steps of the build. The logs of finished workflows
+// are replayed from the store, the logs of running workflows are tailed.
+// The data of the events are the lines tagged with the pid of their step,
+// the ids are cursors resuming the stream after the line when passed as
+// Last-Event-ID.
 func LogStreamSSE(c *gin.Context) {
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
@@ -136,10 +147,9 @@ func LogStreamSSE(c *gin.Context) {
 	repo := session.Repo(c)
 	store_ := store.FromContext(c)
 
-	// // parse the build number and job sequence number from
-	// // the repquest parameter.
+	// parse the build number and proc sequence number from
+	// the request parameter.
 	buildn, _ := strconv.ParseInt(c.Param("build"), 10, 64)
-	jobn, _ := strconv.Atoi(c.Param("number"))
 
 	build, err := store_.GetBuildNumber(repo, buildn)
 	if err != nil {
@@ -147,19 +157,25 @@ func LogStreamSSE(c *gin.Context) {
 		io.WriteString(rw, "event: error\ndata: build not found\n\n")
 		return
 	}
-	proc, err := store_.ProcFind(build, jobn)
+	procs, err := store_.ProcList(build)
 	if err != nil {
-		log.Debug().Msgf("stream cannot get proc number: %v", err)
+		log.Debug().Msgf("stream cannot get procs: %v", err)
 		io.WriteString(rw, "event: error\ndata: process not found\n\n")
 		return
 	}
-	if proc.State != model.StatusRunning {
+	streams := logStreams(procs, c.Param("number"))
+	if len(streams) == 0 {
 		log.Debug().Msg("stream not found.")
-		io.WriteString(rw, "event: error\ndata: stream not found\n\n")
+		io.WriteString(rw, "event: error\ndata: process not found\n\n")
 		return
 	}
 
-	logc := make(chan []byte, 10)
+	cursor := parseLogCursor(c.Request.Header.Get("Last-Event-ID"))
+	if len(cursor.next) != 0 {
+		log.Debug().Msgf("log stream: reconnect: last-event-id: %s", cursor)
+	}
+
+	logc := make(chan *logEvent, 10)
 	ctx, cancel := context.WithCancel(
 		context.Background(),
 	)
@@ -168,41 +184,32 @@ func LogStreamSSE(c *gin.Context) {
 
 	defer func() {
 		cancel()
-		close(logc)
 		log.Debug().Msgf("log stream: connection closed")
 	}()
 
+	var wg sync.WaitGroup
+	for _, stream := range streams {
+		wg.Add(1)
+		go func(stream *logStream) {
+			defer wg.Done()
+			stream.run(ctx, store_, logc)
+		}(stream)
+	}
+	done := make(chan struct{})
 	go func() {
-		// TODO remove global variable
-		server.Config.Services.Logs.Tail(ctx, fmt.Sprint(proc.ID), func(entries ...*logging.Entry) {
-			defer func() {
-				recover() // fix #2480
-			}()
-			for _, entry := range entries {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-					logc <- entry.Data
-				}
-			}
-		})
-
-		io.WriteString(rw, "event: error\ndata: eof\n\n")
-
-		cancel()
+		wg.Wait()
+		close(done)
 	}()
 
-	id := 1
-	last, _ := strconv.Atoi(
-		c.Request.Header.Get("Last-Event-ID"),
-	)
-	if last != 0 {
-		log.Debug().Msgf("log stream: reconnect: last-event-id: %d", last)
+	write := func(event *logEvent) {
+		buf, _ := json.Marshal(event)
+		io.WriteString(rw, "id: "+cursor.String())
+		io.WriteString(rw, "\n")
+		io.WriteString(rw, "data: ")
+		rw.Write(buf)
+		io.WriteString(rw, "\n\n")
 	}
 
-	// retry: 10000\n
-
 	for {
 		select {
 		// after 1 hour of idle (no response) end the stream.
@@ -212,23 +219,217 @@ func LogStreamSSE(c *gin.Context) {
 			return
 		case <-rw.CloseNotify():
 			return
-		case <-ctx.Done():
-			return
 		case <-time.After(time.Second * 30):
 			io.WriteString(rw, ": ping\n\n")
 			flusher.Flush()
-		case buf, ok := <-logc:
-			if ok {
-				if id > last {
-					io.WriteString(rw, "id: "+strconv.Itoa(id))
-					io.WriteString(rw, "\n")
-					io.WriteString(rw, "data: ")
-					rw.Write(buf)
-					io.WriteString(rw, "\n\n")
-					flusher.Flush()
+		case event := <-logc:
+			for _, event := range cursor.push(event) {
+				write(event)
+			}
+			flusher.Flush()
+		case <-done:
+			// the streams are finished, drain the lines sent meanwhile.
+			for {
+				select {
+				case event := <-logc:
+					for _, event := range cursor.push(event) {
+						write(event)
+					}
+					continue
+				default:
+				}
+				break
+			}
+			for _, event := range cursor.flush() {
+				write(event)
+			}
+			io.WriteString(rw, "event: error\ndata: eof\n\n")
+			flusher.Flush()
+			return
+		}
+	}
+}
+
+// logEvent is a line of the logs of a step.
+type logEvent struct {
+	*rpc.Line
+	PID int `json:"pid"`
+}
+
+// logStream streams the logs of steps of a workflow.
+type logStream struct {
+	workflow *model.Proc
+	steps    []*model.Proc
+}
+
+// logStreams returns the streams of the step or workflow with the pid, or
+// of all workflows if the pid is empty.
+func logStreams(procs []*model.Proc, pid string) []*logStream {
+	var streams []*logStream
+	for _, workflow := range procs {
+		if workflow.PPID != 0 {
+			continue
+		}
+		stream := &logStream{workflow: workflow}
+		for _, step := range procs {
+			if step.PPID != workflow.PID {
+				continue
+			}
+			if pid == "" || pid == fmt.Sprint(workflow.PID) || pid == fmt.Sprint(step.PID) {
+				stream.steps = append(stream.steps, step)
+			}
+		}
+		if len(stream.steps) != 0 {
+			streams = append(streams, stream)
+		}
+	}
+	return streams
+}
+
+// run sends the lines of the steps. The in-memory logs of pending and
+// running workflows are tailed, afterwards the lines are replayed from
+// the store so lines still in flight when the workflow finished are not
+// lost. The lines may be sent out of order and more than once.
+func (s *logStream) run(ctx context.Context, store_ store.Store, logc chan<- *logEvent) {
+	steps := map[string]int{}
+	for _, step := range s.steps {
+		steps[step.Name] = step.PID
+	}
+	send := func(ctx context.Context, event *logEvent) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case logc <- event:
+			return true
+		}
+	}
+
+	if s.workflow.Running() {
+		// TODO remove global variable
+		tailCtx, cancel := context.WithCancel(ctx)
+		server.Config.Services.Logs.Tail(tailCtx, fmt.Sprint(s.workflow.ID), func(entries ...*logging.Entry) {
+			for _, entry := range entries {
+				line := new(rpc.Line)
+				if err := json.Unmarshal(entry.Data, line); err != nil {
+					continue
+				}
+				if pid, ok := steps[line.Proc]; ok && !send(tailCtx, &logEvent{Line: line, PID: pid}) {
+					return
 				}
-				id++
 			}
+		})
+		cancel()
+	}
+
+	for _, step := range s.steps {
+		rc, err := store_.LogFind(step)
+		if err != nil {
+			continue
+		}
+		var lines []*rpc.Line
+		err = json.NewDecoder(rc).Decode(&lines)
+		rc.Close()
+		if err != nil {
+			continue
+		}
+		for _, line := range lines {
+			if !send(ctx, &logEvent{Line: line, PID: step.PID}) {
+				return
+			}
+		}
+	}
+}
+
+// logCursor is the position of a log stream. It holds the number of lines
+// sent of each step and the lines received ahead of their position.
+type logCursor struct {
+	next map[int]int
+	held map[int]map[int]*logEvent
+}
+
+// parseLogCursor parses a cursor in the format pid:lines,pid:lines. Invalid
+// cursors replay all lines.
+func parseLogCursor(str string) *logCursor {
+	cursor := &logCursor{
+		next: map[int]int{},
+		held: map[int]map[int]*logEvent{},
+	}
+	for _, part := range strings.Split(str, ",") {
+		parts := strings.SplitN(part, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+		lines, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		cursor.next[pid] = lines
+	}
+	return cursor
+}
+
+// String returns the cursor in the format parsed by parseLogCursor.
+func (c *logCursor) String() string {
+	pids := make([]int, 0, len(c.next))
+	for pid := range c.next {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	parts := make([]string, 0, len(pids))
+	for _, pid := range pids {
+		parts = append(parts, fmt.Sprintf("%d:%d", pid, c.next[pid]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// push returns the lines to send after receiving the line. Lines sent
+// already are dropped, lines ahead of their position are held back until
+// the lines before them are received.
+func (c *logCursor) push(event *logEvent) []*logEvent {
+	next := c.next[event.PID]
+	switch {
+	case event.Pos < next:
+		return nil
+	case event.Pos > next:
+		if c.held[event.PID] == nil {
+			c.held[event.PID] = map[int]*logEvent{}
+		}
+		c.held[event.PID][event.Pos] = event
+		return nil
+	}
+
+	events := []*logEvent{event}
+	c.next[event.PID] = next + 1
+	for {
+		held, ok := c.held[event.PID][c.next[event.PID]]
+		if !ok {
+			break
+		}
+		delete(c.held[event.PID], held.Pos)
+		events = append(events, held)
+		c.next[event.PID]++
+	}
+	return events
+}
+
+// flush returns the lines held back, their missing predecessors are lost.
+func (c *logCursor) flush() []*logEvent {
+	var events []*logEvent
+	for pid, held := range c.held {
+		positions := make([]int, 0, len(held))
+		for pos := range held {
+			positions = append(positions, pos)
+		}
+		sort.Ints(positions)
+		for _, pos := range positions {
+			events = append(events, held[pos])
+			c.next[pid] = pos + 1
 		}
+		delete(c.held, pid)
 	}
+	return events
 }
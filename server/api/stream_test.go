@@ -0,0 +1,86 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/rpc"
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func event(pid, pos int) *logEvent {
+	return &logEvent{Line: &rpc.Line{Pos: pos}, PID: pid}
+}
+
+func positions(events []*logEvent) []int {
+	pos := []int{}
+	for _, event := range events {
+		pos = append(pos, event.Pos)
+	}
+	return pos
+}
+
+func TestLogCursor(t *testing.T) {
+	cursor := parseLogCursor("3:2,invalid,4:x")
+	assert.Equal(t, "3:2", cursor.String())
+
+	// lines sent already are dropped
+	assert.Empty(t, cursor.push(event(3, 1)))
+	assert.Equal(t, []int{2}, positions(cursor.push(event(3, 2))))
+
+	// lines ahead are held back until their predecessors arrive
+	assert.Empty(t, cursor.push(event(2, 1)))
+	assert.Empty(t, cursor.push(event(2, 2)))
+	assert.Equal(t, []int{0, 1, 2}, positions(cursor.push(event(2, 0))))
+	assert.Equal(t, "2:3,3:3", cursor.String())
+
+	assert.Empty(t, cursor.push(event(3, 5)))
+	assert.Equal(t, []int{5}, positions(cursor.flush()))
+	assert.Equal(t, "2:3,3:6", cursor.String())
+
+	assert.Equal(t, "", parseLogCursor("").String())
+}
+
+func TestLogStreams(t *testing.T) {
+	procs := []*model.Proc{
+		{PID: 1, Name: "test"},
+		{PID: 2, Name: "deploy"},
+		{PID: 3, PPID: 1, Name: "clone"},
+		{PID: 4, PPID: 1, Name: "test"},
+		{PID: 5, PPID: 2, Name: "clone"},
+	}
+
+	streams := logStreams(procs, "")
+	if assert.Len(t, streams, 2) {
+		assert.Len(t, streams[0].steps, 2)
+		assert.Len(t, streams[1].steps, 1)
+	}
+
+	streams = logStreams(procs, "1")
+	if assert.Len(t, streams, 1) {
+		assert.Len(t, streams[0].steps, 2)
+	}
+
+	streams = logStreams(procs, "4")
+	if assert.Len(t, streams, 1) {
+		assert.Equal(t, 1, streams[0].workflow.PID)
+		assert.Equal(t, []*model.Proc{procs[3]}, streams[0].steps)
+	}
+
+	assert.Empty(t, logStreams(procs, "6"))
+}
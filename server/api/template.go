@@ -0,0 +1,124 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/store"
+)
+
+// GetTemplateList gets the pipeline config templates and writes them to
+// the response in json format.
+func GetTemplateList(c *gin.Context) {
+	list, err := store.FromContext(c).TemplateList()
+	if err != nil {
+		c.String(500, "Error getting template list. %s", err)
+		return
+	}
+	c.JSON(200, list)
+}
+
+// GetTemplate gets the template by name and writes it to the response
+// in json format.
+func GetTemplate(c *gin.Context) {
+	template, err := store.FromContext(c).TemplateFind(c.Param("template"))
+	if err != nil {
+		c.String(404, "Error getting template %q. %s", c.Param("template"), err)
+		return
+	}
+	c.JSON(200, template)
+}
+
+// PostTemplate persists the template to the database.
+func PostTemplate(c *gin.Context) {
+	in := new(model.Template)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing template. %s", err)
+		return
+	}
+	template := &model.Template{
+		Name: in.Name,
+		Data: in.Data,
+	}
+	if err := validateTemplate(template); err != nil {
+		c.String(400, "Error inserting template. %s", err)
+		return
+	}
+	if err := store.FromContext(c).TemplateCreate(template); err != nil {
+		c.String(500, "Error inserting template. %s", err)
+		return
+	}
+	c.JSON(200, template)
+}
+
+// PatchTemplate updates the template in the database.
+func PatchTemplate(c *gin.Context) {
+	in := new(struct {
+		Data *string `json:"data"`
+	})
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing template. %s", err)
+		return
+	}
+
+	store_ := store.FromContext(c)
+	template, err := store_.TemplateFind(c.Param("template"))
+	if err != nil {
+		c.String(404, "Error getting template %q. %s", c.Param("template"), err)
+		return
+	}
+	if in.Data != nil {
+		template.Data = *in.Data
+	}
+
+	if err := validateTemplate(template); err != nil {
+		c.String(400, "Error updating template. %s", err)
+		return
+	}
+	if err := store_.TemplateUpdate(template); err != nil {
+		c.String(500, "Error updating template. %s", err)
+		return
+	}
+	c.JSON(200, template)
+}
+
+// DeleteTemplate deletes the template from the database.
+func DeleteTemplate(c *gin.Context) {
+	store_ := store.FromContext(c)
+	template, err := store_.TemplateFind(c.Param("template"))
+	if err != nil {
+		c.String(404, "Error getting template %q. %s", c.Param("template"), err)
+		return
+	}
+	if err := store_.TemplateDelete(template); err != nil {
+		c.String(500, "Error deleting template. %s", err)
+		return
+	}
+	c.String(204, "")
+}
+
+// validateTemplate validates the name of the template and that the data
+// is a yaml mapping.
+func validateTemplate(template *model.Template) error {
+	if err := template.Validate(); err != nil {
+		return err
+	}
+	return yaml.Unmarshal([]byte(template.Data), new(map[string]interface{}))
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/router/middleware/session"
+	"github.com/woodpecker-ci/woodpecker/server/store"
+)
+
+// GetBuildTests returns the results of the tests of the build, optionally
+// filtered by the status query parameter.
+func GetBuildTests(c *gin.Context) {
+	store_ := store.FromContext(c)
+	num, err := strconv.ParseInt(c.Param("number"), 10, 64)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	repo := session.Repo(c)
+	build, err := store_.GetBuildNumber(repo, num)
+	if err != nil {
+		c.AbortWithError(http.StatusNotFound, err)
+		return
+	}
+
+	results, err := store_.TestList(build)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if status := c.Query("status"); status != "" {
+		filtered := []*model.TestResult{}
+		for _, result := range results {
+			if result.Status == status {
+				filtered = append(filtered, result)
+			}
+		}
+		results = filtered
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GetTestHistory returns the results of a test in the latest builds of
+// the repository, the test is selected by the suite and name query
+// parameters.
+func GetTestHistory(c *gin.Context) {
+	store_ := store.FromContext(c)
+	repo := session.Repo(c)
+
+	// names are stored truncated
+	suite, name := model.TruncateTestField(c.Query("suite")), model.TruncateTestField(c.Query("name"))
+	if name == "" {
+		c.String(http.StatusBadRequest, "Error parsing test. The name is required")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		c.String(http.StatusBadRequest, "Error parsing limit. %s", err)
+		return
+	}
+
+	results, err := store_.TestHistory(repo, suite, name, limit)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.NewTestHistory(suite, name, results))
+}
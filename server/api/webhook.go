@@ -0,0 +1,207 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/woodpecker-ci/woodpecker/server"
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/router/middleware/session"
+	"github.com/woodpecker-ci/woodpecker/server/store"
+)
+
+// GetWebhookList gets the webhooks of the repository, or the global
+// webhooks if used outside of a repository, and writes them to the
+// response in json format.
+func GetWebhookList(c *gin.Context) {
+	list, err := store.FromContext(c).WebhookList(webhookScope(c))
+	if err != nil {
+		c.String(500, "Error getting webhook list. %s", err)
+		return
+	}
+	// copy the webhook detail to remove the signing secret.
+	for i, hook := range list {
+		list[i] = hook.Copy()
+	}
+	c.JSON(200, list)
+}
+
+// GetWebhook gets the webhook and writes it to the response in json format.
+func GetWebhook(c *gin.Context) {
+	hook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+	c.JSON(200, hook.Copy())
+}
+
+// PostWebhook persists the webhook to the database.
+func PostWebhook(c *gin.Context) {
+	in := new(model.Webhook)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing webhook. %s", err)
+		return
+	}
+	hook := &model.Webhook{
+		RepoID: webhookScope(c),
+		URL:    in.URL,
+		Secret: in.Secret,
+		Events: in.Events,
+		Active: true,
+	}
+	if err := hook.Validate(); err != nil {
+		c.String(400, "Error inserting webhook. %s", err)
+		return
+	}
+	if err := server.Config.Services.Webhooks.ValidateURL(c, hook.URL); err != nil {
+		c.String(400, "Error inserting webhook. %s", err)
+		return
+	}
+	if err := store.FromContext(c).WebhookCreate(hook); err != nil {
+		c.String(500, "Error inserting webhook. %s", err)
+		return
+	}
+	c.JSON(200, hook.Copy())
+}
+
+// PatchWebhook updates the webhook in the database.
+func PatchWebhook(c *gin.Context) {
+	in := new(struct {
+		URL    *string  `json:"url"`
+		Secret *string  `json:"secret"`
+		Events []string `json:"events"`
+		Active *bool    `json:"active"`
+	})
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing webhook. %s", err)
+		return
+	}
+
+	hook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+	if in.URL != nil {
+		hook.URL = *in.URL
+	}
+	if in.Secret != nil {
+		hook.Secret = *in.Secret
+	}
+	if len(in.Events) != 0 {
+		hook.Events = in.Events
+	}
+	if in.Active != nil {
+		hook.Active = *in.Active
+	}
+
+	if err := hook.Validate(); err != nil {
+		c.String(400, "Error updating webhook. %s", err)
+		return
+	}
+	if err := server.Config.Services.Webhooks.ValidateURL(c, hook.URL); err != nil {
+		c.String(400, "Error updating webhook. %s", err)
+		return
+	}
+	if err := store.FromContext(c).WebhookUpdate(hook); err != nil {
+		c.String(500, "Error updating webhook. %s", err)
+		return
+	}
+	c.JSON(200, hook.Copy())
+}
+
+// DeleteWebhook deletes the webhook and its delivery log from the database.
+func DeleteWebhook(c *gin.Context) {
+	hook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+	if err := store.FromContext(c).WebhookDelete(hook); err != nil {
+		c.String(500, "Error deleting webhook. %s", err)
+		return
+	}
+	c.String(204, "")
+}
+
+// GetWebhookDeliveries gets the most recent deliveries of the webhook
+// and writes them to the response in json format.
+func GetWebhookDeliveries(c *gin.Context) {
+	hook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+	list, err := store.FromContext(c).WebhookDeliveryList(hook)
+	if err != nil {
+		c.String(500, "Error getting webhook deliveries. %s", err)
+		return
+	}
+	c.JSON(200, list)
+}
+
+// PostWebhookTest sends a test delivery with the latest build of the
+// repository to the webhook and writes the delivery to the response.
+func PostWebhookTest(c *gin.Context) {
+	hook, ok := findWebhook(c)
+	if !ok {
+		return
+	}
+
+	event := model.Event{Type: model.Finished}
+	if repo := session.Repo(c); repo != nil {
+		event.Repo = *repo
+		if build, err := store.FromContext(c).GetBuildLast(repo, repo.Branch); err == nil {
+			event.Build = *build
+		}
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		c.String(500, "Error encoding test event. %s", err)
+		return
+	}
+
+	delivery, _ := server.Config.Services.Webhooks.Deliver(c, hook, event.Type, payload)
+	if delivery == nil {
+		c.String(500, "Error recording webhook delivery.")
+		return
+	}
+	c.JSON(200, delivery)
+}
+
+// webhookScope returns the repository id webhooks are managed for,
+// 0 for global webhooks.
+func webhookScope(c *gin.Context) int64 {
+	if repo := session.Repo(c); repo != nil {
+		return repo.ID
+	}
+	return 0
+}
+
+func findWebhook(c *gin.Context) (*model.Webhook, bool) {
+	id, err := strconv.ParseInt(c.Param("webhook"), 10, 64)
+	if err != nil {
+		c.String(400, "Error parsing webhook id. %s", err)
+		return nil, false
+	}
+	hook, err := store.FromContext(c).WebhookFind(webhookScope(c), id)
+	if err != nil {
+		c.String(404, "Error getting webhook %d. %s", id, err)
+		return nil, false
+	}
+	return hook, true
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"github.com/woodpecker-ci/woodpecker/server/model"
 	"github.com/woodpecker-ci/woodpecker/server/store"
 	"github.com/woodpecker-ci/woodpecker/version"
 )
@@ -66,6 +67,9 @@ func SetLogLevel(c *gin.Context) {
 	}
 
 	log.Log().Msgf("log level set to %s", lvl.String())
+	recordAudit(c, model.AuditLogLevel, nil, lvl.String(), map[string]string{
+		"from": zerolog.GlobalLevel().String(),
+	})
 	zerolog.SetGlobalLevel(lvl)
 	c.JSON(200, logLevel)
 }
@@ -0,0 +1,165 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package badges renders status badges as SVG images and as responses
+// of the shields.io endpoint badge.
+package badges
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+// Badge colors.
+const (
+	ColorSuccess = "#4c1"
+	ColorFailure = "#e05d44"
+	ColorStarted = "#dfb317"
+	ColorNone    = "#9f9f9f"
+	ColorInfo    = "#007ec6"
+)
+
+// Badge is a badge with a label and a colored message.
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// Shields is the response of the shields.io endpoint badge.
+type Shields struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// Status returns the badge of the build or workflow status.
+func Status(label, status string) *Badge {
+	switch status {
+	case model.StatusSuccess:
+		return &Badge{label, "success", ColorSuccess}
+	case model.StatusFailure:
+		return &Badge{label, "failure", ColorFailure}
+	case model.StatusError, model.StatusKilled:
+		return &Badge{label, "error", ColorNone}
+	case model.StatusPending, model.StatusRunning:
+		return &Badge{label, "started", ColorStarted}
+	}
+	return None(label)
+}
+
+// Duration returns the badge of a build or workflow duration.
+func Duration(label string, duration time.Duration) *Badge {
+	duration = duration.Round(time.Second)
+	message := fmt.Sprintf("%ds", int(duration.Seconds())%60)
+	if duration >= time.Minute {
+		message = fmt.Sprintf("%dm %s", int(duration.Minutes())%60, message)
+	}
+	if duration >= time.Hour {
+		message = fmt.Sprintf("%dh %s", int(duration.Hours()), message)
+	}
+	return &Badge{label, message, ColorInfo}
+}
+
+// Coverage returns the badge of a coverage percentage.
+func Coverage(label string, percent float64) *Badge {
+	color := ColorFailure
+	switch {
+	case percent >= 90:
+		color = ColorSuccess
+	case percent >= 75:
+		color = "#97ca00"
+	case percent >= 60:
+		color = ColorStarted
+	case percent >= 40:
+		color = "#fe7d37"
+	}
+	message := fmt.Sprintf("%.1f%%", math.Floor(percent*10)/10)
+	return &Badge{label, strings.Replace(message, ".0%", "%", 1), color}
+}
+
+// None returns the badge shown if there is no build.
+func None(label string) *Badge {
+	return &Badge{label, "none", ColorNone}
+}
+
+// Shields returns the badge as response of the shields.io endpoint badge.
+func (b *Badge) Shields() *Shields {
+	return &Shields{
+		SchemaVersion: 1,
+		Label:         b.Label,
+		Message:       b.Message,
+		Color:         strings.TrimPrefix(b.Color, "#"),
+	}
+}
+
+// SVG renders the badge in the flat style of shields.io.
+func (b *Badge) SVG() []byte {
+	label, message := escape(b.Label), escape(b.Message)
+	lw, mw := textWidth(b.Label)+10, textWidth(b.Message)+10
+	w := lw + mw
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20">`, w)
+	buf.WriteString(`<linearGradient id="a" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&buf, `<rect rx="3" width="%d" height="20" fill="#555"/>`, w)
+	fmt.Fprintf(&buf, `<rect rx="3" x="%d" width="%d" height="20" fill="%s"/>`, lw, mw, b.Color)
+	fmt.Fprintf(&buf, `<path fill="%s" d="M%d 0h4v20h-4z"/>`, b.Color, lw)
+	fmt.Fprintf(&buf, `<rect rx="3" width="%d" height="20" fill="url(#a)"/>`, w)
+	buf.WriteString(`<g fill="#fff" text-anchor="middle" font-family="DejaVu Sans,Verdana,Geneva,sans-serif" font-size="11">`)
+	for _, text := range []struct {
+		x     float64
+		value string
+	}{
+		{float64(lw) / 2, label},
+		{float64(lw) + float64(mw)/2, message},
+	} {
+		fmt.Fprintf(&buf, `<text x="%g" y="15" fill="#010101" fill-opacity=".3">%s</text>`, text.x, text.value)
+		fmt.Fprintf(&buf, `<text x="%g" y="14">%s</text>`, text.x, text.value)
+	}
+	buf.WriteString(`</g></svg>`)
+	return buf.Bytes()
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// textWidth approximates the width of the text in pixels when rendered
+// with an 11px Verdana font.
+func textWidth(s string) int {
+	var width float64
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("fijlrt.,:;'!|() ", r):
+			width += 4
+		case strings.ContainsRune("mwMW%", r):
+			width += 10
+		case r >= 'A' && r <= 'Z':
+			width += 7.5
+		default:
+			width += 6.5
+		}
+	}
+	return int(math.Ceil(width))
+}
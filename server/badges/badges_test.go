@@ -0,0 +1,68 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package badges
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func TestStatus(t *testing.T) {
+	testdata := []struct {
+		status  string
+		message string
+		color   string
+	}{
+		{model.StatusSuccess, "success", ColorSuccess},
+		{model.StatusFailure, "failure", ColorFailure},
+		{model.StatusKilled, "error", ColorNone},
+		{model.StatusRunning, "started", ColorStarted},
+		{model.StatusBlocked, "none", ColorNone},
+	}
+	for _, test := range testdata {
+		assert.Equal(t, &Badge{"build", test.message, test.color}, Status("build", test.status), test.status)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	assert.Equal(t, "42s", Duration("duration", 42*time.Second).Message)
+	assert.Equal(t, "2m 0s", Duration("duration", 2*time.Minute).Message)
+	assert.Equal(t, "1h 2m 3s", Duration("duration", time.Hour+2*time.Minute+3*time.Second).Message)
+}
+
+func TestCoverage(t *testing.T) {
+	assert.Equal(t, &Badge{"coverage", "100%", ColorSuccess}, Coverage("coverage", 100))
+	assert.Equal(t, &Badge{"coverage", "87.5%", "#97ca00"}, Coverage("coverage", 87.56))
+	assert.Equal(t, &Badge{"coverage", "12%", ColorFailure}, Coverage("coverage", 12))
+}
+
+func TestShields(t *testing.T) {
+	data, err := json.Marshal(Status("build", model.StatusSuccess).Shields())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"schemaVersion":1,"label":"build","message":"success","color":"4c1"}`, string(data))
+}
+
+func TestSVG(t *testing.T) {
+	svg := (&Badge{"test & lint", "<none>", ColorNone}).SVG()
+	assert.NoError(t, xml.Unmarshal(svg, new(struct{})))
+	assert.Contains(t, string(svg), "test &amp; lint")
+	assert.Contains(t, string(svg), "&lt;none&gt;")
+}
@@ -20,10 +20,13 @@ package server
 import (
 	"time"
 
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/matrix"
 	"github.com/woodpecker-ci/woodpecker/server/logging"
 	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/plugins/config"
 	"github.com/woodpecker-ci/woodpecker/server/pubsub"
 	"github.com/woodpecker-ci/woodpecker/server/queue"
+	"github.com/woodpecker-ci/woodpecker/server/webhook"
 )
 
 var Config = struct {
@@ -35,6 +38,9 @@ var Config = struct {
 		Secrets    model.SecretService
 		Registries model.RegistryService
 		Environ    model.EnvironService
+		Webhooks   *webhook.Dispatcher
+		// ConfigExtension is optional and generates the pipeline configs
+		ConfigExtension config.Extension
 	}
 	Storage struct {
 		// Users  model.UserStore
@@ -62,10 +68,16 @@ var Config = struct {
 	Prometheus struct {
 		AuthToken string
 	}
-	Pipeline struct {
-		Limits     model.ResourceLimit
-		Volumes    []string
-		Networks   []string
-		Privileged []string
-	}
+	Pipeline PipelineConfig
 }{}
+
+// PipelineConfig configures the pipelines built by the server.
+type PipelineConfig struct {
+	Limits               model.ResourceLimit
+	Volumes              []string
+	Networks             []string
+	Privileged           []string
+	DefaultCloneImage    string
+	DefaultCloneSettings map[string]interface{}
+	MatrixLimits         matrix.Limits
+}
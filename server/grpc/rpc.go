@@ -31,6 +31,8 @@ import (
 	grpcMetadata "google.golang.org/grpc/metadata"
 
 	"github.com/woodpecker-ci/expr"
+	"github.com/woodpecker-ci/woodpecker/pipeline/multipart/coverage"
+	"github.com/woodpecker-ci/woodpecker/pipeline/multipart/testreport"
 	"github.com/woodpecker-ci/woodpecker/pipeline/rpc"
 	"github.com/woodpecker-ci/woodpecker/pipeline/rpc/proto"
 	"github.com/woodpecker-ci/woodpecker/server"
@@ -96,6 +98,26 @@ func (s *RPC) Extend(c context.Context, id string) error {
 	return s.queue.Extend(c, id)
 }
 
+// Active implements the rpc.Active function
+func (s *RPC) Active(c context.Context, ids []string) ([]string, error) {
+	info := s.queue.Info(c)
+
+	queued := map[string]bool{}
+	for _, tasks := range [][]*queue.Task{info.Pending, info.WaitingOnDeps, info.Running} {
+		for _, task := range tasks {
+			queued[task.ID] = true
+		}
+	}
+
+	var active []string
+	for _, id := range ids {
+		if queued[id] {
+			active = append(active, id)
+		}
+	}
+	return active, nil
+}
+
 // Update implements the rpc.Update function
 func (s *RPC) Update(c context.Context, id string, state rpc.State) error {
 	procID, err := strconv.ParseInt(id, 10, 64)
@@ -214,19 +236,109 @@ func (s *RPC) Upload(c context.Context, id string, file *rpc.File) error {
 		report.Failed, _ = strconv.Atoi(d)
 	}
 
-	if d, ok := file.Meta["X-Coverage-Lines"]; ok {
+	if d, ok := file.Meta["X-Covered-Lines"]; ok {
 		report.Passed, _ = strconv.Atoi(d)
 	}
-	if d, ok := file.Meta["X-Coverage-Total"]; ok {
+	if d, ok := file.Meta["X-Total-Lines"]; ok {
 		if total, _ := strconv.Atoi(d); total != 0 {
 			report.Failed = total - report.Passed
 		}
 	}
 
-	return server.Config.Storage.Files.FileCreate(
+	// coverage reports are parsed into the coverage of the source files
+	var cover *coverage.Report
+	if mime := coverage.Detect(file.Mime, file.Name, file.Data); mime != "" {
+		if cover, err = coverage.Parse(mime, file.Data); err != nil {
+			log.Error().Err(err).Msgf("error: cannot parse coverage report %s of proc_id %d", file.Name, proc.ID)
+		} else {
+			report.Mime = mime
+			report.Passed = cover.Metrics.CoveredLines
+			report.Failed = cover.Metrics.TotalLines - cover.Metrics.CoveredLines
+		}
+	}
+
+	// test reports are parsed into the results of the single tests
+	var tests *testreport.Report
+	if mime := testreport.Detect(file.Mime, file.Name, file.Data); mime != "" && cover == nil {
+		if tests, err = testreport.Parse(mime, file.Data); err != nil {
+			log.Error().Err(err).Msgf("error: cannot parse test report %s of proc_id %d", file.Name, proc.ID)
+		} else {
+			report.Mime = mime
+			report.Passed, report.Failed, report.Skipped = tests.Counts()
+		}
+	}
+
+	if err := server.Config.Storage.Files.FileCreate(
 		report,
 		bytes.NewBuffer(file.Data),
-	)
+	); err != nil {
+		return err
+	}
+
+	if cover != nil {
+		return s.store.CoverageCreate(coverageFiles(proc, report, cover))
+	}
+	if tests != nil {
+		return s.store.TestCreate(testResults(build, proc, report, tests))
+	}
+	return nil
+}
+
+// coverageFiles returns the coverage of the source files of the report
+// uploaded by the proc. Reports without source files are stored with the
+// name of the report.
+func coverageFiles(proc *model.Proc, file *model.File, report *coverage.Report) []*model.CoverageFile {
+	if len(report.Files) == 0 {
+		return []*model.CoverageFile{{
+			BuildID:      proc.BuildID,
+			ProcID:       proc.ID,
+			PID:          proc.PID,
+			Name:         file.Name,
+			CoveredLines: report.Metrics.CoveredLines,
+			TotalLines:   report.Metrics.TotalLines,
+		}}
+	}
+
+	files := make([]*model.CoverageFile, 0, len(report.Files))
+	for _, file := range report.Files {
+		files = append(files, &model.CoverageFile{
+			BuildID:      proc.BuildID,
+			ProcID:       proc.ID,
+			PID:          proc.PID,
+			Name:         file.Name,
+			CoveredLines: file.CoveredLines,
+			TotalLines:   file.TotalLines,
+		})
+	}
+	return files
+}
+
+// testResults returns the results of the tests of the report uploaded by
+// the proc.
+func testResults(build *model.Build, proc *model.Proc, file *model.File, report *testreport.Report) []*model.TestResult {
+	results := make([]*model.TestResult, 0, len(report.Tests))
+	for _, test := range report.Tests {
+		suite := test.Suite
+		if suite == "" {
+			suite = file.Name
+		}
+		results = append(results, &model.TestResult{
+			RepoID:      build.RepoID,
+			BuildID:     build.ID,
+			BuildNumber: build.Number,
+			Commit:      build.Commit,
+			ProcID:      proc.ID,
+			PID:         proc.PID,
+			File:        model.TruncateTestField(file.Name),
+			Suite:       model.TruncateTestField(suite),
+			Name:        model.TruncateTestField(test.Name),
+			Status:      test.Status,
+			Duration:    test.Duration,
+			Message:     test.Message,
+			Created:     file.Time,
+		})
+	}
+	return results
 }
 
 // Init implements the rpc.Init function
@@ -261,10 +373,12 @@ func (s *RPC) Init(c context.Context, id string, state rpc.State) error {
 		return err
 	}
 
+	var event model.EventType
 	if build.Status == model.StatusPending {
 		if build, err = shared.UpdateToStatusRunning(s.store, *build, state.Started); err != nil {
 			log.Error().Msgf("error: init: cannot update build_id %d state: %s", build.ID, err)
 		}
+		event = model.Started
 	}
 
 	defer func() {
@@ -276,6 +390,7 @@ func (s *RPC) Init(c context.Context, id string, state rpc.State) error {
 			},
 		}
 		message.Data, _ = json.Marshal(model.Event{
+			Type:  event,
 			Repo:  *repo,
 			Build: *build,
 		})
@@ -328,7 +443,9 @@ func (s *RPC) Done(c context.Context, id string, state rpc.State) error {
 	procs, _ := s.store.ProcList(build)
 	s.completeChildrenIfParentCompleted(procs, proc)
 
+	event := model.ProcFinished
 	if !isThereRunningStage(procs) {
+		event = model.Finished
 		if build, err = shared.UpdateStatusToDone(s.store, *build, buildStatus(procs), proc.Stopped); err != nil {
 			log.Error().Msgf("error: done: cannot update build_id %d final state: %s", build.ID, err)
 		}
@@ -346,7 +463,7 @@ func (s *RPC) Done(c context.Context, id string, state rpc.State) error {
 		log.Error().Msgf("error: done: cannot close build_id %d logger: %s", proc.ID, err)
 	}
 
-	s.notify(c, repo, build, procs)
+	s.notify(c, event, repo, build, proc, procs)
 
 	if build.Status == model.StatusSuccess || build.Status == model.StatusFailure {
 		s.buildCount.WithLabelValues(repo.FullName, build.Branch, build.Status, "total").Inc()
@@ -421,6 +538,7 @@ func (s *RPC) updateRemoteStatus(ctx context.Context, repo *model.Repo, build *m
 				s.store.UpdateUser(user)
 			}
 		}
+		s.summarizeTests(build, proc)
 		uri := fmt.Sprintf("%s/%s/%d", server.Config.Server.Host, repo.FullName, build.Number)
 		err = s.remote.Status(ctx, user, repo, build, uri, proc)
 		if err != nil {
@@ -429,7 +547,38 @@ func (s *RPC) updateRemoteStatus(ctx context.Context, repo *model.Repo, build *m
 	}
 }
 
-func (s *RPC) notify(c context.Context, repo *model.Repo, build *model.Build, procs []*model.Proc) {
+// summarizeTests summarizes the test results of the build, and of the
+// steps of the workflow if proc is not nil, for the commit status.
+func (s *RPC) summarizeTests(build *model.Build, proc *model.Proc) {
+	results, err := s.store.TestList(build)
+	if err != nil || len(results) == 0 {
+		return
+	}
+	build.Tests = model.NewTestSummary(results)
+	if proc == nil {
+		return
+	}
+
+	procs, err := s.store.ProcList(build)
+	if err != nil {
+		return
+	}
+	steps := map[int]bool{}
+	for _, p := range procs {
+		if p.PPID == proc.PID {
+			steps[p.PID] = true
+		}
+	}
+	var workflow []*model.TestResult
+	for _, result := range results {
+		if steps[result.PID] {
+			workflow = append(workflow, result)
+		}
+	}
+	proc.Tests = model.NewTestSummary(workflow)
+}
+
+func (s *RPC) notify(c context.Context, event model.EventType, repo *model.Repo, build *model.Build, proc *model.Proc, procs []*model.Proc) {
 	build.Procs = model.Tree(procs)
 	message := pubsub.Message{
 		Labels: map[string]string{
@@ -438,8 +587,10 @@ func (s *RPC) notify(c context.Context, repo *model.Repo, build *model.Build, pr
 		},
 	}
 	message.Data, _ = json.Marshal(model.Event{
+		Type:  event,
 		Repo:  *repo,
 		Build: *build,
+		Proc:  *proc,
 	})
 	s.pubsub.Publish(c, "topic/events", message)
 }
@@ -529,12 +680,14 @@ func (s *WoodpeckerServer) Next(c context.Context, req *proto.NextRequest) (*pro
 
 func (s *WoodpeckerServer) Init(c context.Context, req *proto.InitRequest) (*proto.Empty, error) {
 	state := rpc.State{
-		Error:    req.GetState().GetError(),
-		ExitCode: int(req.GetState().GetExitCode()),
-		Finished: req.GetState().GetFinished(),
-		Started:  req.GetState().GetStarted(),
-		Proc:     req.GetState().GetName(),
-		Exited:   req.GetState().GetExited(),
+		Error:        req.GetState().GetError(),
+		ExitCode:     int(req.GetState().GetExitCode()),
+		Finished:     req.GetState().GetFinished(),
+		Started:      req.GetState().GetStarted(),
+		PullStarted:  req.GetState().GetPullStarted(),
+		PullFinished: req.GetState().GetPullFinished(),
+		Proc:         req.GetState().GetName(),
+		Exited:       req.GetState().GetExited(),
 	}
 	res := new(proto.Empty)
 	err := s.peer.Init(c, req.GetId(), state)
@@ -543,12 +696,14 @@ func (s *WoodpeckerServer) Init(c context.Context, req *proto.InitRequest) (*pro
 
 func (s *WoodpeckerServer) Update(c context.Context, req *proto.UpdateRequest) (*proto.Empty, error) {
 	state := rpc.State{
-		Error:    req.GetState().GetError(),
-		ExitCode: int(req.GetState().GetExitCode()),
-		Finished: req.GetState().GetFinished(),
-		Started:  req.GetState().GetStarted(),
-		Proc:     req.GetState().GetName(),
-		Exited:   req.GetState().GetExited(),
+		Error:        req.GetState().GetError(),
+		ExitCode:     int(req.GetState().GetExitCode()),
+		Finished:     req.GetState().GetFinished(),
+		Started:      req.GetState().GetStarted(),
+		PullStarted:  req.GetState().GetPullStarted(),
+		PullFinished: req.GetState().GetPullFinished(),
+		Proc:         req.GetState().GetName(),
+		Exited:       req.GetState().GetExited(),
 	}
 	res := new(proto.Empty)
 	err := s.peer.Update(c, req.GetId(), state)
@@ -573,12 +728,14 @@ func (s *WoodpeckerServer) Upload(c context.Context, req *proto.UploadRequest) (
 
 func (s *WoodpeckerServer) Done(c context.Context, req *proto.DoneRequest) (*proto.Empty, error) {
 	state := rpc.State{
-		Error:    req.GetState().GetError(),
-		ExitCode: int(req.GetState().GetExitCode()),
-		Finished: req.GetState().GetFinished(),
-		Started:  req.GetState().GetStarted(),
-		Proc:     req.GetState().GetName(),
-		Exited:   req.GetState().GetExited(),
+		Error:        req.GetState().GetError(),
+		ExitCode:     int(req.GetState().GetExitCode()),
+		Finished:     req.GetState().GetFinished(),
+		Started:      req.GetState().GetStarted(),
+		PullStarted:  req.GetState().GetPullStarted(),
+		PullFinished: req.GetState().GetPullFinished(),
+		Proc:         req.GetState().GetName(),
+		Exited:       req.GetState().GetExited(),
 	}
 	res := new(proto.Empty)
 	err := s.peer.Done(c, req.GetId(), state)
@@ -608,3 +765,10 @@ func (s *WoodpeckerServer) Log(c context.Context, req *proto.LogRequest) (*proto
 	err := s.peer.Log(c, req.GetId(), line)
 	return res, err
 }
+
+func (s *WoodpeckerServer) Active(c context.Context, req *proto.ActiveRequest) (*proto.ActiveReply, error) {
+	res := new(proto.ActiveReply)
+	ids, err := s.peer.Active(c, req.GetIds())
+	res.Ids = ids
+	return res, err
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// ApprovalStore persists the approvals of gated builds to storage.
+type ApprovalStore interface {
+	ApprovalCreate(*Approval) error
+	ApprovalList(*Build) ([]*Approval, error)
+}
+
+// Approval records the approval of a gated build by a user.
+type Approval struct {
+	ID      int64  `json:"-"       xorm:"pk autoincr 'approval_id'"`
+	BuildID int64  `json:"-"       xorm:"UNIQUE(s) INDEX 'approval_build_id'"`
+	Login   string `json:"login"   xorm:"UNIQUE(s) 'approval_login'"`
+	Created int64  `json:"created" xorm:"approval_created"`
+}
+
+// TableName return database table name for xorm
+func (Approval) TableName() string {
+	return "approvals"
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// Security relevant actions recorded in the audit log.
+const (
+	AuditSecretCreate = "secret.create"
+	AuditSecretUpdate = "secret.update"
+	AuditSecretDelete = "secret.delete"
+	AuditRepoUpdate   = "repo.update"
+	AuditRepoChown    = "repo.chown"
+	AuditRepoMove     = "repo.move"
+	AuditBuildApprove = "build.approve"
+	AuditBuildDecline = "build.decline"
+	AuditSenderUpdate = "sender.update"
+	AuditSenderDelete = "sender.delete"
+	AuditEnvCreate    = "environment.create"
+	AuditEnvUpdate    = "environment.update"
+	AuditEnvDelete    = "environment.delete"
+	AuditRoleCreate   = "role.create"
+	AuditRoleDelete   = "role.delete"
+	AuditLogLevel     = "server.log_level"
+)
+
+// AuditStore persists audit events to storage. The audit log is
+// append-only, events can neither be updated nor deleted.
+type AuditStore interface {
+	AuditCreate(*AuditEvent) error
+	AuditList(*AuditFilter) ([]*AuditEvent, error)
+}
+
+// AuditEvent records who performed a security relevant action.
+type AuditEvent struct {
+	ID      int64             `json:"id"               xorm:"pk autoincr 'audit_id'"`
+	Created int64             `json:"created"          xorm:"INDEX 'audit_created'"`
+	Actor   string            `json:"actor"            xorm:"INDEX 'audit_actor'"`
+	Action  string            `json:"action"           xorm:"INDEX 'audit_action'"`
+	Repo    string            `json:"repo,omitempty"   xorm:"INDEX 'audit_repo'"`
+	Target  string            `json:"target,omitempty" xorm:"audit_target"`
+	Data    map[string]string `json:"data,omitempty"   xorm:"json 'audit_data'"`
+}
+
+// TableName return database table name for xorm
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditFilter defines the optional conditions to query the audit log.
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Repo   string
+	Before int64
+	After  int64
+	Limit  int
+}
@@ -22,6 +22,7 @@ type ConfigStore interface {
 	ConfigFindApproved(*Config) (bool, error)
 	ConfigCreate(*Config) error
 	BuildConfigCreate(*BuildConfig) error
+	BuildConfigDelete(*BuildConfig) error
 }
 
 // Config represents a pipeline configuration.
@@ -0,0 +1,150 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// CoverageStore persists the coverage of the coverage reports to storage.
+type CoverageStore interface {
+	CoverageCreate([]*CoverageFile) error
+	CoverageList(*Build) ([]*CoverageFile, error)
+}
+
+// CoverageFile represents the coverage of a source file of a coverage
+// report uploaded by a build step.
+type CoverageFile struct {
+	ID           int64  `json:"id"            xorm:"pk autoincr 'coverage_id'"`
+	BuildID      int64  `json:"-"             xorm:"INDEX 'coverage_build_id'"`
+	ProcID       int64  `json:"proc_id"       xorm:"coverage_proc_id"`
+	PID          int    `json:"pid"           xorm:"coverage_pid"`
+	Name         string `json:"name"          xorm:"coverage_name"`
+	CoveredLines int    `json:"covered_lines" xorm:"coverage_covered_lines"`
+	TotalLines   int    `json:"total_lines"   xorm:"coverage_total_lines"`
+}
+
+// TableName return database table name for xorm
+func (CoverageFile) TableName() string {
+	return "coverage_files"
+}
+
+// Coverage represents the coverage of a build, compared to the coverage
+// of a base build if available.
+type Coverage struct {
+	Covered      float64           `json:"covered"`
+	CoveredLines int               `json:"covered_lines"`
+	TotalLines   int               `json:"total_lines"`
+	Base         int64             `json:"base,omitempty"`
+	BaseCovered  float64           `json:"base_covered,omitempty"`
+	Change       float64           `json:"change"`
+	DiffCovered  *float64          `json:"diff_covered,omitempty"`
+	Files        []*CoverageChange `json:"files"`
+}
+
+// CoverageChange represents the coverage of a source file and its change
+// compared to the base build.
+type CoverageChange struct {
+	Name         string   `json:"name"`
+	Covered      float64  `json:"covered"`
+	CoveredLines int      `json:"covered_lines"`
+	TotalLines   int      `json:"total_lines"`
+	BaseCovered  *float64 `json:"base_covered,omitempty"`
+	Change       float64  `json:"change"`
+	Changed      bool     `json:"changed,omitempty"`
+}
+
+// NewCoverage returns the coverage of the files, or nil if there are none.
+// A file reported by multiple steps, e.g. by the workflows of a matrix, is
+// counted with its best coverage.
+func NewCoverage(files []*CoverageFile) *Coverage {
+	if len(files) == 0 {
+		return nil
+	}
+
+	byName := map[string]*CoverageChange{}
+	for _, file := range files {
+		if change, ok := byName[file.Name]; ok && change.CoveredLines >= file.CoveredLines {
+			continue
+		}
+		byName[file.Name] = &CoverageChange{
+			Name:         file.Name,
+			Covered:      coveragePercent(file.CoveredLines, file.TotalLines),
+			CoveredLines: file.CoveredLines,
+			TotalLines:   file.TotalLines,
+		}
+	}
+
+	coverage := new(Coverage)
+	for _, change := range byName {
+		coverage.Files = append(coverage.Files, change)
+		coverage.CoveredLines += change.CoveredLines
+		coverage.TotalLines += change.TotalLines
+	}
+	sort.Slice(coverage.Files, func(i, j int) bool {
+		return coverage.Files[i].Name < coverage.Files[j].Name
+	})
+	coverage.Covered = coveragePercent(coverage.CoveredLines, coverage.TotalLines)
+	return coverage
+}
+
+// Compare compares the coverage to the coverage of the base build. Files
+// missing in the base build have no base coverage.
+func (c *Coverage) Compare(number int64, base *Coverage) {
+	c.Base = number
+	c.BaseCovered = base.Covered
+	c.Change = c.Covered - base.Covered
+
+	baseFiles := map[string]*CoverageChange{}
+	for _, file := range base.Files {
+		baseFiles[file.Name] = file
+	}
+	for _, file := range c.Files {
+		if baseFile, ok := baseFiles[file.Name]; ok {
+			covered := baseFile.Covered
+			file.BaseCovered = &covered
+			file.Change = file.Covered - covered
+		}
+	}
+}
+
+// Diff marks the files changed by the build and computes the coverage of
+// the changed files. Changed paths are relative to the repository root and
+// match the files with the same path suffix.
+func (c *Coverage) Diff(changed []string) {
+	var covered, total int
+	for _, file := range c.Files {
+		for _, path := range changed {
+			if file.Name == path || strings.HasSuffix(file.Name, "/"+path) {
+				file.Changed = true
+				covered += file.CoveredLines
+				total += file.TotalLines
+				break
+			}
+		}
+	}
+	if total != 0 {
+		diff := coveragePercent(covered, total)
+		c.DiffCovered = &diff
+	}
+}
+
+func coveragePercent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) * 100 / float64(total)
+}
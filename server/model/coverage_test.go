@@ -0,0 +1,68 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestCoverage(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Coverage", func() {
+
+		g.It("should be nil without files", func() {
+			g.Assert(NewCoverage(nil) == nil).IsTrue()
+		})
+		g.It("should count files with the best coverage", func() {
+			coverage := NewCoverage([]*CoverageFile{
+				{PID: 2, Name: "util.go", CoveredLines: 1, TotalLines: 4},
+				{PID: 2, Name: "main.go", CoveredLines: 2, TotalLines: 4},
+				{PID: 4, Name: "main.go", CoveredLines: 3, TotalLines: 4},
+			})
+			g.Assert(coverage.CoveredLines).Equal(4)
+			g.Assert(coverage.TotalLines).Equal(8)
+			g.Assert(coverage.Covered).Equal(50.0)
+			g.Assert(coverage.Files[0].Name).Equal("main.go")
+			g.Assert(coverage.Files[0].Covered).Equal(75.0)
+		})
+		g.It("should compare to the base coverage", func() {
+			coverage := NewCoverage([]*CoverageFile{
+				{Name: "src/main.go", CoveredLines: 3, TotalLines: 4},
+				{Name: "src/new.go", CoveredLines: 1, TotalLines: 4},
+			})
+			coverage.Compare(41, NewCoverage([]*CoverageFile{
+				{Name: "src/main.go", CoveredLines: 2, TotalLines: 4},
+			}))
+			g.Assert(coverage.Base).Equal(int64(41))
+			g.Assert(coverage.Change).Equal(0.0)
+			g.Assert(coverage.Files[0].Change).Equal(25.0)
+			g.Assert(*coverage.Files[0].BaseCovered).Equal(50.0)
+			g.Assert(coverage.Files[1].BaseCovered == nil).IsTrue()
+		})
+		g.It("should compute the coverage of changed files", func() {
+			coverage := NewCoverage([]*CoverageFile{
+				{Name: "github.com/octocat/hello-world/main.go", CoveredLines: 3, TotalLines: 4},
+				{Name: "github.com/octocat/hello-world/util.go", CoveredLines: 0, TotalLines: 4},
+			})
+			coverage.Diff([]string{"main.go", "README.md"})
+			g.Assert(coverage.Files[0].Changed).IsTrue()
+			g.Assert(coverage.Files[1].Changed).IsFalse()
+			g.Assert(*coverage.DiffCovered).Equal(75.0)
+		})
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"path/filepath"
+)
+
+var (
+	errEnvironmentNameInvalid   = errors.New("Invalid Environment Name")
+	errEnvironmentBranchInvalid = errors.New("Invalid Environment Branch Pattern")
+)
+
+// EnvironmentStore persists the deployment environments of repositories
+// to storage.
+type EnvironmentStore interface {
+	EnvironmentFind(*Repo, string) (*Environment, error)
+	EnvironmentList(*Repo) ([]*Environment, error)
+	EnvironmentCreate(*Environment) error
+	EnvironmentUpdate(*Environment) error
+	EnvironmentDelete(*Environment) error
+}
+
+// Environment represents a named deployment environment of a repository
+// and the rules protecting deployments to it.
+type Environment struct {
+	ID              int64    `json:"id"               xorm:"pk autoincr 'environment_id'"`
+	RepoID          int64    `json:"-"                xorm:"UNIQUE(s) INDEX 'environment_repo_id'"`
+	Name            string   `json:"name"             xorm:"UNIQUE(s) 'environment_name'"`
+	Branches        []string `json:"branches"         xorm:"json 'environment_branches'"`
+	RequireApproval bool     `json:"require_approval" xorm:"environment_require_approval"`
+
+	// Deployed is the latest successful deployment to the environment.
+	Deployed *Build `json:"deployed,omitempty" xorm:"-"`
+}
+
+// EnvironmentPatch represents an environment patch object.
+type EnvironmentPatch struct {
+	Branches        *[]string `json:"branches,omitempty"`
+	RequireApproval *bool     `json:"require_approval,omitempty"`
+}
+
+// TableName return database table name for xorm
+func (Environment) TableName() string {
+	return "environments"
+}
+
+// MatchBranch returns true if the branch is allowed to deploy to the
+// environment. All branches are allowed if the list of branches is empty.
+func (e *Environment) MatchBranch(branch string) bool {
+	if len(e.Branches) == 0 {
+		return true
+	}
+	for _, pattern := range e.Branches {
+		if match, _ := filepath.Match(pattern, branch); match {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the required fields and formats.
+func (e *Environment) Validate() error {
+	if len(e.Name) == 0 {
+		return errEnvironmentNameInvalid
+	}
+	for _, pattern := range e.Branches {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return errEnvironmentBranchInvalid
+		}
+	}
+	return nil
+}
@@ -18,10 +18,11 @@ package model
 type EventType string
 
 const (
-	Enqueued  EventType = "enqueued"
-	Started   EventType = "started"
-	Finished  EventType = "finished"
-	Cancelled EventType = "cancelled"
+	Enqueued     EventType = "enqueued"
+	Started      EventType = "started"
+	Finished     EventType = "finished"
+	Cancelled    EventType = "cancelled"
+	ProcFinished EventType = "proc_finished" // a workflow of the build completed
 )
 
 // Event represents a build event.
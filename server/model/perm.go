@@ -25,13 +25,15 @@ type PermStore interface {
 
 // Perm defines a repository permission for an individual user.
 type Perm struct {
-	UserID int64  `json:"-"       xorm:"UNIQUE(s) INDEX NOT NULL 'perm_user_id'"`
-	RepoID int64  `json:"-"       xorm:"UNIQUE(s) INDEX NOT NULL 'perm_repo_id'"`
-	Repo   string `json:"-"       xorm:"-"` // TODO: better caching (use type *Repo)
-	Pull   bool   `json:"pull"    xorm:"perm_pull"`
-	Push   bool   `json:"push"    xorm:"perm_push"`
-	Admin  bool   `json:"admin"   xorm:"perm_admin"`
-	Synced int64  `json:"synced"  xorm:"perm_synced"`
+	UserID  int64  `json:"-"       xorm:"UNIQUE(s) INDEX NOT NULL 'perm_user_id'"`
+	RepoID  int64  `json:"-"       xorm:"UNIQUE(s) INDEX NOT NULL 'perm_repo_id'"`
+	Repo    string `json:"-"       xorm:"-"` // TODO: better caching (use type *Repo)
+	Pull    bool   `json:"pull"    xorm:"perm_pull"`
+	Push    bool   `json:"push"    xorm:"perm_push"`
+	Admin   bool   `json:"admin"   xorm:"perm_admin"`
+	Synced  int64  `json:"synced"  xorm:"perm_synced"`
+	Approve bool   `json:"approve" xorm:"-"` // granted by woodpecker managed roles
+	Audit   bool   `json:"audit"   xorm:"-"` // granted by woodpecker managed roles
 	// TODO: after xorm switch make followup pull that utilize created & updated
 	// Created int64  `json:"created" xorm:"created"`
 	// Updated int64  `json:"updated" xorm:"updated"`
@@ -41,3 +43,23 @@ type Perm struct {
 func (Perm) TableName() string {
 	return "perms"
 }
+
+// Grant extends the permission with the given roles.
+func (p *Perm) Grant(roles []*Role) {
+	for _, role := range roles {
+		switch role.Name {
+		case RoleAdmin:
+			p.Pull = true
+			p.Push = true
+			p.Admin = true
+			p.Approve = true
+			p.Audit = true
+		case RoleAuditor:
+			p.Pull = true
+			p.Audit = true
+		case RoleApprover:
+			p.Pull = true
+			p.Approve = true
+		}
+	}
+}
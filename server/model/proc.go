@@ -31,21 +31,24 @@ type ProcStore interface {
 // Proc represents a process in the build pipeline.
 // swagger:model proc
 type Proc struct {
-	ID       int64             `json:"id"                   xorm:"pk autoincr 'proc_id'"`
-	BuildID  int64             `json:"build_id"             xorm:"UNIQUE(s) INDEX 'proc_build_id'"`
-	PID      int               `json:"pid"                  xorm:"UNIQUE(s) 'proc_pid'"`
-	PPID     int               `json:"ppid"                 xorm:"proc_ppid"`
-	PGID     int               `json:"pgid"                 xorm:"proc_pgid"`
-	Name     string            `json:"name"                 xorm:"proc_name"`
-	State    string            `json:"state"                xorm:"proc_state"`
-	Error    string            `json:"error,omitempty"      xorm:"VARCHAR(500) proc_error"`
-	ExitCode int               `json:"exit_code"            xorm:"proc_exit_code"`
-	Started  int64             `json:"start_time,omitempty" xorm:"proc_started"`
-	Stopped  int64             `json:"end_time,omitempty"   xorm:"proc_stopped"`
-	Machine  string            `json:"machine,omitempty"    xorm:"proc_machine"`
-	Platform string            `json:"platform,omitempty"   xorm:"proc_platform"`
-	Environ  map[string]string `json:"environ,omitempty"    xorm:"json 'proc_environ'"`
-	Children []*Proc           `json:"children,omitempty"   xorm:"-"`
+	ID           int64             `json:"id"                        xorm:"pk autoincr 'proc_id'"`
+	BuildID      int64             `json:"build_id"                  xorm:"UNIQUE(s) INDEX 'proc_build_id'"`
+	PID          int               `json:"pid"                       xorm:"UNIQUE(s) 'proc_pid'"`
+	PPID         int               `json:"ppid"                      xorm:"proc_ppid"`
+	PGID         int               `json:"pgid"                      xorm:"proc_pgid"`
+	Name         string            `json:"name"                      xorm:"proc_name"`
+	State        string            `json:"state"                     xorm:"proc_state"`
+	Error        string            `json:"error,omitempty"           xorm:"VARCHAR(500) proc_error"`
+	ExitCode     int               `json:"exit_code"                 xorm:"proc_exit_code"`
+	Started      int64             `json:"start_time,omitempty"      xorm:"proc_started"`
+	Stopped      int64             `json:"end_time,omitempty"        xorm:"proc_stopped"`
+	PullStarted  int64             `json:"pull_start_time,omitempty" xorm:"proc_pull_started"`
+	PullFinished int64             `json:"pull_end_time,omitempty"   xorm:"proc_pull_finished"`
+	Machine      string            `json:"machine,omitempty"         xorm:"proc_machine"`
+	Platform     string            `json:"platform,omitempty"        xorm:"proc_platform"`
+	Environ      map[string]string `json:"environ,omitempty"         xorm:"json 'proc_environ'"`
+	Children     []*Proc           `json:"children,omitempty"        xorm:"-"`
+	Tests        *TestSummary      `json:"tests,omitempty"           xorm:"-"`
 }
 
 // TableName return database table name for xorm
@@ -42,6 +42,11 @@ type Repo struct {
 	IsGated    bool   `json:"gated"                    xorm:"repo_gated"`
 	IsActive   bool   `json:"active"                   xorm:"repo_active"`
 	AllowPull  bool   `json:"allow_pr"                 xorm:"repo_allow_pr"`
+	// Approval rules of gated builds
+	ApprovalCount  int      `json:"approval_count"           xorm:"repo_approval_count"`
+	ApprovalOrgs   []string `json:"approval_orgs,omitempty"  xorm:"json 'repo_approval_orgs'"`
+	ApproveMembers bool     `json:"approve_members"          xorm:"repo_approve_members"`
+	GateForks      bool     `json:"gate_forks"               xorm:"repo_gate_forks"`
 	// Counter is used as index to determine new build numbers
 	Counter int64  `json:"last_build"                  xorm:"NOT NULL DEFAULT 0 'repo_counter'"`
 	Config  string `json:"config_file"                 xorm:"varchar(500) 'repo_config_path'"`
@@ -54,6 +59,15 @@ func (Repo) TableName() string {
 	return "repos"
 }
 
+// RequiredApprovals returns the number of approvals a gated build of the
+// repository requires.
+func (r *Repo) RequiredApprovals() int {
+	if r.ApprovalCount < 1 {
+		return 1
+	}
+	return r.ApprovalCount
+}
+
 func (r *Repo) ResetVisibility() {
 	r.Visibility = VisibilityPublic
 	if r.IsPrivate {
@@ -99,4 +113,9 @@ type RepoPatch struct {
 	Visibility   *string `json:"visibility,omitempty"`
 	AllowPull    *bool   `json:"allow_pr,omitempty"`
 	BuildCounter *int64  `json:"build_counter,omitempty"`
+	// Approval rules of gated builds
+	ApprovalCount  *int      `json:"approval_count,omitempty"`
+	ApprovalOrgs   *[]string `json:"approval_orgs,omitempty"`
+	ApproveMembers *bool     `json:"approve_members,omitempty"`
+	GateForks      *bool     `json:"gate_forks,omitempty"`
 }
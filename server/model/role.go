@@ -0,0 +1,76 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "errors"
+
+var (
+	errRoleLoginInvalid = errors.New("Invalid Role Login")
+	errRoleNameInvalid  = errors.New("Invalid Role Name")
+	errRoleOwnerInvalid = errors.New("Invalid Role Owner")
+)
+
+// Woodpecker managed roles that are granted on top of the
+// permissions synced from the remote system.
+const (
+	// RoleAdmin grants admin permission on all repositories of an
+	// organization, or on a single repository.
+	RoleAdmin = "admin"
+	// RoleAuditor grants read-only access to builds, secrets and
+	// registries without push permission.
+	RoleAuditor = "auditor"
+	// RoleApprover allows to approve and decline gated builds.
+	RoleApprover = "approver"
+)
+
+// RoleStore persists woodpecker managed roles to storage.
+type RoleStore interface {
+	RoleList(owner string) ([]*Role, error)
+	RoleListRepo(*Repo) ([]*Role, error)
+	RoleListUser(*User, *Repo) ([]*Role, error)
+	RoleCreate(*Role) error
+	RoleDelete(*Role) error
+}
+
+// Role grants a user a woodpecker managed role, either for all
+// repositories of an owner (organization) or for a single repository.
+type Role struct {
+	ID     int64  `json:"id"      xorm:"pk autoincr 'role_id'"`
+	Owner  string `json:"owner"   xorm:"UNIQUE(s) INDEX 'role_owner'"`
+	RepoID int64  `json:"repo_id" xorm:"UNIQUE(s) INDEX 'role_repo_id'"`
+	Login  string `json:"login"   xorm:"UNIQUE(s) 'role_login'"`
+	Name   string `json:"role"    xorm:"UNIQUE(s) 'role_name'"`
+}
+
+// TableName return database table name for xorm
+func (Role) TableName() string {
+	return "roles"
+}
+
+// Validate validates the required fields and formats.
+func (r *Role) Validate() error {
+	switch {
+	case len(r.Owner) == 0:
+		return errRoleOwnerInvalid
+	case !reUsername.MatchString(r.Login):
+		return errRoleLoginInvalid
+	}
+	switch r.Name {
+	case RoleAdmin, RoleAuditor, RoleApprover:
+		return nil
+	default:
+		return errRoleNameInvalid
+	}
+}
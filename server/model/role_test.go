@@ -0,0 +1,65 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestRole(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Role", func() {
+
+		g.It("should pass validation", func() {
+			role := Role{Owner: "octocat", Login: "octocat", Name: RoleAuditor}
+			g.Assert(role.Validate()).Equal(nil)
+		})
+		g.Describe("should fail validation", func() {
+			g.It("when no owner", func() {
+				role := Role{Login: "octocat", Name: RoleAuditor}
+				g.Assert(role.Validate()).IsNotNil()
+			})
+			g.It("when invalid login", func() {
+				role := Role{Owner: "octocat", Login: "octo cat", Name: RoleAuditor}
+				g.Assert(role.Validate()).IsNotNil()
+			})
+			g.It("when unknown role", func() {
+				role := Role{Owner: "octocat", Login: "octocat", Name: "owner"}
+				g.Assert(role.Validate()).IsNotNil()
+			})
+		})
+		g.It("should grant admin permissions", func() {
+			perm := Perm{}
+			perm.Grant([]*Role{{Name: RoleAdmin}})
+			g.Assert(perm.Pull && perm.Push && perm.Admin).IsTrue()
+			g.Assert(perm.Approve && perm.Audit).IsTrue()
+		})
+		g.It("should grant read-only auditor permissions", func() {
+			perm := Perm{}
+			perm.Grant([]*Role{{Name: RoleAuditor}})
+			g.Assert(perm.Pull && perm.Audit).IsTrue()
+			g.Assert(perm.Push || perm.Admin || perm.Approve).IsFalse()
+		})
+		g.It("should grant approver permissions", func() {
+			perm := Perm{}
+			perm.Grant([]*Role{{Name: RoleApprover}})
+			g.Assert(perm.Pull && perm.Approve).IsTrue()
+			g.Assert(perm.Push || perm.Admin || perm.Audit).IsFalse()
+		})
+	})
+}
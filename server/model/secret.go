@@ -55,6 +55,9 @@ type Secret struct {
 	Events     []string `json:"event"           xorm:"json 'secret_events'"`
 	SkipVerify bool     `json:"-"               xorm:"secret_skip_verify"`
 	Conceal    bool     `json:"-"               xorm:"secret_conceal"`
+
+	// Environments restricts the secret to deployments to these environments.
+	Environments []string `json:"environment,omitempty" xorm:"json 'secret_environments'"`
 }
 
 // TableName return database table name for xorm
@@ -75,6 +78,26 @@ func (s *Secret) Match(event string) bool {
 	return false
 }
 
+// MatchEnvironment returns true if the secret is available to a build of
+// the event deploying to the target environment. Secrets scoped to
+// environments are only available to deployments to these environments.
+// Environments are matched by their exact name like the protection rules
+// of environments, so that scoped secrets never reach unprotected targets.
+func (s *Secret) MatchEnvironment(event, target string) bool {
+	if len(s.Environments) == 0 {
+		return true
+	}
+	if event != EventDeploy {
+		return false
+	}
+	for _, name := range s.Environments {
+		if name == target {
+			return true
+		}
+	}
+	return false
+}
+
 // Validate validates the required fields and formats.
 func (s *Secret) Validate() error {
 	switch {
@@ -95,5 +118,7 @@ func (s *Secret) Copy() *Secret {
 		Name:   s.Name,
 		Images: s.Images,
 		Events: s.Events,
+
+		Environments: s.Environments,
 	}
 }
@@ -39,6 +39,23 @@ func TestSecret(t *testing.T) {
 			secret := Secret{}
 			g.Assert(secret.Match("pull_request")).IsTrue()
 		})
+		g.It("should match environment", func() {
+			secret := Secret{}
+			secret.Environments = []string{"production", "staging"}
+			g.Assert(secret.MatchEnvironment("deployment", "production")).IsTrue()
+			g.Assert(secret.MatchEnvironment("deployment", "staging")).IsTrue()
+		})
+		g.It("should not match environment", func() {
+			secret := Secret{}
+			secret.Environments = []string{"production", "prod*"}
+			g.Assert(secret.MatchEnvironment("deployment", "staging")).IsFalse()
+			g.Assert(secret.MatchEnvironment("deployment", "prod-x")).IsFalse()
+			g.Assert(secret.MatchEnvironment("push", "production")).IsFalse()
+		})
+		g.It("should match when no environment filters defined", func() {
+			secret := Secret{}
+			g.Assert(secret.MatchEnvironment("push", "")).IsTrue()
+		})
 		g.It("should pass validation", func() {
 			secret := Secret{}
 			secret.Name = "secretname"
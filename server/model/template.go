@@ -0,0 +1,55 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"regexp"
+)
+
+var errTemplateNameInvalid = errors.New("Invalid Template Name")
+
+var reTemplateName = regexp.MustCompile("^[a-zA-Z0-9_.-]+$")
+
+// TemplateStore persists admin managed pipeline config templates to
+// storage.
+type TemplateStore interface {
+	TemplateFind(name string) (*Template, error)
+	TemplateList() ([]*Template, error)
+	TemplateCreate(*Template) error
+	TemplateUpdate(*Template) error
+	TemplateDelete(*Template) error
+}
+
+// Template is a pipeline config that pipelines of all repositories can
+// include by name.
+type Template struct {
+	ID   int64  `json:"id"   xorm:"pk autoincr 'template_id'"`
+	Name string `json:"name" xorm:"UNIQUE 'template_name'"`
+	Data string `json:"data" xorm:"TEXT 'template_data'"`
+}
+
+// TableName return database table name for xorm
+func (Template) TableName() string {
+	return "templates"
+}
+
+// Validate validates the required fields and formats.
+func (t *Template) Validate() error {
+	if !reTemplateName.MatchString(t.Name) {
+		return errTemplateNameInvalid
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Test result states.
+const (
+	TestPassed  = "passed"
+	TestFailed  = "failed"
+	TestSkipped = "skipped"
+)
+
+// TestStore persists the results of test reports to storage.
+type TestStore interface {
+	TestCreate([]*TestResult) error
+	TestList(*Build) ([]*TestResult, error)
+	TestDelete(*Proc) error
+	TestHistory(repo *Repo, suite, name string, limit int) ([]*TestResult, error)
+}
+
+// testFieldLength is the maximum length of the indexed fields of a test
+// result.
+const testFieldLength = 255
+
+// TruncateTestField shortens a suite, test or file name to the length
+// of the database column.
+func TruncateTestField(s string) string {
+	if len(s) <= testFieldLength {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= testFieldLength {
+		return s
+	}
+	return string(runes[:testFieldLength])
+}
+
+// TestResult represents the result of a single test of a test report
+// uploaded by a build step.
+type TestResult struct {
+	ID          int64   `json:"id"                xorm:"pk autoincr 'test_id'"`
+	RepoID      int64   `json:"-"                 xorm:"INDEX 'test_repo_id'"`
+	BuildID     int64   `json:"-"                 xorm:"INDEX 'test_build_id'"`
+	BuildNumber int64   `json:"build_number"      xorm:"test_build_number"`
+	Commit      string  `json:"commit"            xorm:"test_commit"`
+	ProcID      int64   `json:"proc_id"           xorm:"test_proc_id"`
+	PID         int     `json:"pid"               xorm:"test_pid"`
+	File        string  `json:"file"              xorm:"test_file"`
+	Suite       string  `json:"suite"             xorm:"INDEX(name) 'test_suite'"`
+	Name        string  `json:"name"              xorm:"INDEX(name) 'test_name'"`
+	Status      string  `json:"status"            xorm:"test_status"`
+	Duration    float64 `json:"duration"          xorm:"test_duration"`
+	Message     string  `json:"message,omitempty" xorm:"TEXT 'test_message'"`
+	Created     int64   `json:"created"           xorm:"test_created"`
+}
+
+// TableName return database table name for xorm
+func (TestResult) TableName() string {
+	return "test_results"
+}
+
+// TestSummary counts the results of the test reports of a build or
+// workflow.
+type TestSummary struct {
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// NewTestSummary returns the summary of the test results, or nil if there
+// are none.
+func NewTestSummary(results []*TestResult) *TestSummary {
+	if len(results) == 0 {
+		return nil
+	}
+	summary := new(TestSummary)
+	for _, result := range results {
+		switch result.Status {
+		case TestPassed:
+			summary.Passed++
+		case TestFailed:
+			summary.Failed++
+		case TestSkipped:
+			summary.Skipped++
+		}
+	}
+	return summary
+}
+
+// Describe appends the summary to the status description of a commit
+// status. The description is returned unchanged without summary.
+func (s *TestSummary) Describe(desc string) string {
+	if s == nil {
+		return desc
+	}
+	parts := []string{fmt.Sprintf("%d passed", s.Passed)}
+	if s.Failed != 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", s.Failed))
+	}
+	if s.Skipped != 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", s.Skipped))
+	}
+	return fmt.Sprintf("%s (%s)", desc, strings.Join(parts, ", "))
+}
+
+// TestHistory represents the results of a test in the recent builds, a
+// test is flaky if it passed and failed for the same commit.
+type TestHistory struct {
+	Suite   string        `json:"suite"`
+	Name    string        `json:"name"`
+	Passed  int           `json:"passed"`
+	Failed  int           `json:"failed"`
+	Skipped int           `json:"skipped"`
+	Flaky   bool          `json:"flaky"`
+	Results []*TestResult `json:"results"`
+}
+
+// NewTestHistory returns the history of the results of the test.
+func NewTestHistory(suite, name string, results []*TestResult) *TestHistory {
+	history := &TestHistory{
+		Suite:   suite,
+		Name:    name,
+		Results: results,
+	}
+	if summary := NewTestSummary(results); summary != nil {
+		history.Passed, history.Failed, history.Skipped = summary.Passed, summary.Failed, summary.Skipped
+	}
+
+	states := map[string]string{}
+	for _, result := range results {
+		if result.Status == TestSkipped {
+			continue
+		}
+		if state, ok := states[result.Commit]; ok && state != result.Status {
+			history.Flaky = true
+		}
+		states[result.Commit] = result.Status
+	}
+	return history
+}
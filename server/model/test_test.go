@@ -0,0 +1,62 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestTestResults(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Test results", func() {
+
+		g.It("should describe the summary", func() {
+			var summary *TestSummary
+			g.Assert(summary.Describe("Build is passing")).Equal("Build is passing")
+
+			summary = NewTestSummary([]*TestResult{{Status: TestPassed}, {Status: TestPassed}, {Status: TestSkipped}})
+			g.Assert(summary.Describe("Build is passing")).Equal("Build is passing (2 passed, 1 skipped)")
+
+			summary = &TestSummary{Failed: 1}
+			g.Assert(summary.Describe("Build is failing")).Equal("Build is failing (0 passed, 1 failed)")
+		})
+		g.It("should truncate long names", func() {
+			g.Assert(TruncateTestField("TestStream")).Equal("TestStream")
+			g.Assert(len(TruncateTestField(strings.Repeat("a", 300)))).Equal(255)
+			g.Assert(len([]rune(TruncateTestField(strings.Repeat("ä", 300))))).Equal(255)
+			g.Assert(TruncateTestField(strings.Repeat("ä", 200))).Equal(strings.Repeat("ä", 200))
+		})
+		g.It("should detect flaky tests", func() {
+			history := NewTestHistory("api", "TestStream", []*TestResult{
+				{Commit: "a", Status: TestPassed},
+				{Commit: "b", Status: TestFailed},
+				{Commit: "b", Status: TestSkipped},
+			})
+			g.Assert(history.Flaky).IsFalse()
+			g.Assert(history.Passed).Equal(1)
+			g.Assert(history.Failed).Equal(1)
+
+			history = NewTestHistory("api", "TestStream", []*TestResult{
+				{Commit: "a", Status: TestPassed},
+				{Commit: "a", Status: TestFailed},
+			})
+			g.Assert(history.Flaky).IsTrue()
+		})
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"net/url"
+)
+
+var errWebhookURLInvalid = errors.New("Invalid Webhook URL")
+
+// WebhookStore persists outbound webhook subscriptions and their
+// delivery log to storage.
+type WebhookStore interface {
+	WebhookFind(repoID, id int64) (*Webhook, error)
+	WebhookList(repoID int64) ([]*Webhook, error)
+	WebhookListActive(*Repo) ([]*Webhook, error)
+	WebhookCreate(*Webhook) error
+	WebhookUpdate(*Webhook) error
+	WebhookDelete(*Webhook) error
+	WebhookDeliveryCreate(*WebhookDelivery) error
+	WebhookDeliveryUpdate(*WebhookDelivery) error
+	WebhookDeliveryList(*Webhook) ([]*WebhookDelivery, error)
+}
+
+// Webhook is an outbound webhook subscription for build lifecycle events.
+// A webhook without a repository (RepoID = 0) receives the events of all
+// repositories.
+type Webhook struct {
+	ID     int64    `json:"id"               xorm:"pk autoincr 'webhook_id'"`
+	RepoID int64    `json:"-"                xorm:"INDEX 'webhook_repo_id'"`
+	URL    string   `json:"url"              xorm:"TEXT 'webhook_url'"`
+	Secret string   `json:"secret,omitempty" xorm:"TEXT 'webhook_secret'"`
+	Events []string `json:"events"           xorm:"json 'webhook_events'"`
+	Active bool     `json:"active"           xorm:"webhook_active"`
+}
+
+// TableName return database table name for xorm
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Match returns true if the webhook subscribes to the event. A webhook
+// without events subscribes to all events.
+func (w *Webhook) Match(event EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if EventType(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the required fields and formats.
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errWebhookURLInvalid
+	}
+	return nil
+}
+
+// Copy makes a copy of the webhook without the signing secret.
+func (w *Webhook) Copy() *Webhook {
+	return &Webhook{
+		ID:     w.ID,
+		RepoID: w.RepoID,
+		URL:    w.URL,
+		Events: w.Events,
+		Active: w.Active,
+	}
+}
+
+// WebhookDelivery records a single delivery of an event to a webhook.
+type WebhookDelivery struct {
+	ID        int64  `json:"id"              xorm:"pk autoincr 'delivery_id'"`
+	WebhookID int64  `json:"webhook_id"      xorm:"INDEX 'delivery_webhook_id'"`
+	Event     string `json:"event"           xorm:"delivery_event"`
+	Payload   string `json:"payload"         xorm:"TEXT 'delivery_payload'"`
+	Attempts  int    `json:"attempts"        xorm:"delivery_attempts"`
+	Status    int    `json:"status_code"     xorm:"delivery_status"`
+	Error     string `json:"error,omitempty" xorm:"TEXT 'delivery_error'"`
+	Success   bool   `json:"success"         xorm:"delivery_success"`
+	Created   int64  `json:"created"         xorm:"delivery_created"`
+	Finished  int64  `json:"finished"        xorm:"delivery_finished"`
+}
+
+// TableName return database table name for xorm
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/plugins/internal"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+)
+
+// Extension generates the pipeline configs of a build, for example from
+// a jsonnet source or the changed paths of a monorepo.
+type Extension interface {
+	// FetchConfig returns the configs replacing the fetched configs of
+	// the build, or nil to keep them.
+	FetchConfig(ctx context.Context, repo *model.Repo, build *model.Build, files []*remote.FileMeta) ([]*remote.FileMeta, error)
+}
+
+type request struct {
+	Repo    *model.Repo   `json:"repo"`
+	Build   *model.Build  `json:"build"`
+	Configs []*configFile `json:"configs"`
+}
+
+type response struct {
+	Configs []*configFile `json:"configs"`
+}
+
+type configFile struct {
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+type plugin struct {
+	endpoint string
+	secret   string
+	timeout  time.Duration
+}
+
+// NewHTTP returns a config extension posting the configs to the endpoint.
+// The requests are signed with the secret and canceled after the timeout.
+// The endpoint responds with the replacing configs, or with no content
+// to keep the fetched configs.
+func NewHTTP(endpoint, secret string, timeout time.Duration) Extension {
+	return &plugin{
+		endpoint: endpoint,
+		secret:   secret,
+		timeout:  timeout,
+	}
+}
+
+func (p *plugin) FetchConfig(ctx context.Context, repo *model.Repo, build *model.Build, files []*remote.FileMeta) ([]*remote.FileMeta, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	in := &request{
+		Repo:  repo,
+		Build: build,
+	}
+	for _, file := range files {
+		in.Configs = append(in.Configs, &configFile{Name: file.Name, Data: string(file.Data)})
+	}
+
+	out := new(response)
+	if err := internal.SendSigned(ctx, "POST", p.endpoint, p.secret, in, out); err != nil {
+		return nil, err
+	}
+	if len(out.Configs) == 0 {
+		return nil, nil
+	}
+
+	configs := make([]*remote.FileMeta, 0, len(out.Configs))
+	for _, config := range out.Configs {
+		configs = append(configs, &remote.FileMeta{Name: config.Name, Data: []byte(config.Data)})
+	}
+	return configs, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/plugins/internal"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+)
+
+func TestFetchConfig(t *testing.T) {
+	var (
+		body   []byte
+		header http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = ioutil.ReadAll(r.Body)
+		header = r.Header
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"configs": []map[string]string{
+				{"name": ".woodpecker/generated.yml", "data": "pipeline: {}"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	ext := NewHTTP(server.URL, "secret", time.Second)
+	configs, err := ext.FetchConfig(context.Background(), &model.Repo{FullName: "octocat/hello-world"}, &model.Build{Number: 1}, []*remote.FileMeta{
+		{Name: ".woodpecker.jsonnet", Data: []byte("{}")},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []*remote.FileMeta{{Name: ".woodpecker/generated.yml", Data: []byte("pipeline: {}")}}, configs)
+	assert.Equal(t, internal.Sign("secret", header.Get("Date"), body), header.Get(internal.HeaderSignature))
+
+	in := new(request)
+	assert.NoError(t, json.Unmarshal(body, in))
+	assert.Equal(t, "octocat/hello-world", in.Repo.FullName)
+	assert.Equal(t, []*configFile{{Name: ".woodpecker.jsonnet", Data: "{}"}}, in.Configs)
+}
+
+func TestFetchConfigNoContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	configs, err := NewHTTP(server.URL, "secret", time.Second).FetchConfig(context.Background(), &model.Repo{}, &model.Build{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, configs)
+}
+
+func TestFetchConfigTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	_, err := NewHTTP(server.URL, "secret", time.Millisecond*10).FetchConfig(context.Background(), &model.Repo{}, &model.Build{}, nil)
+	assert.Error(t, err)
+}
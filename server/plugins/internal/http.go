@@ -3,16 +3,43 @@ package internal
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 )
 
+// HeaderSignature is the header of signed requests carrying the
+// signature of the date header and the body.
+const HeaderSignature = "X-Woodpecker-Signature"
+
 // Send makes an http request to the given endpoint, writing the input
 // to the request body and unmarshaling the output from the response body.
 func Send(ctx context.Context, method, path string, in, out interface{}) error {
+	return send(ctx, method, path, "", in, out)
+}
+
+// SendSigned makes an http request like Send and signs it with the
+// secret, so the endpoint can verify the request was sent by the server.
+func SendSigned(ctx context.Context, method, path, secret string, in, out interface{}) error {
+	return send(ctx, method, path, secret, in, out)
+}
+
+// Sign returns the HMAC-SHA256 signature of the date and the body in the
+// format sha256=<hex>, as sent in the signature header.
+func Sign(secret, date string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(date))
+	mac.Write([]byte("\n"))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func send(ctx context.Context, method, path, secret string, in, out interface{}) error {
 	uri, err := url.Parse(path)
 	if err != nil {
 		return err
@@ -20,23 +47,28 @@ func Send(ctx context.Context, method, path string, in, out interface{}) error {
 
 	// if we are posting or putting data, we need to
 	// write it to the body of the request.
-	var buf io.ReadWriter
+	buf := new(bytes.Buffer)
 	if in != nil {
-		buf = new(bytes.Buffer)
 		jsonerr := json.NewEncoder(buf).Encode(in)
 		if jsonerr != nil {
 			return jsonerr
 		}
 	}
+	body := buf.Bytes()
 
 	// creates a new http request to bitbucket.
-	req, err := http.NewRequestWithContext(ctx, method, uri.String(), buf)
+	req, err := http.NewRequestWithContext(ctx, method, uri.String(), bytes.NewReader(body))
 	if err != nil {
 		return err
 	}
 	if in != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if secret != "" {
+		date := time.Now().UTC().Format(http.TimeFormat)
+		req.Header.Set("Date", date)
+		req.Header.Set(HeaderSignature, Sign(secret, date, body))
+	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -59,7 +91,7 @@ func Send(ctx context.Context, method, path string, in, out interface{}) error {
 
 	// if a json response is expected, parse and return
 	// the json response.
-	if out != nil {
+	if out != nil && resp.StatusCode != http.StatusNoContent {
 		return json.NewDecoder(resp.Body).Decode(out)
 	}
 
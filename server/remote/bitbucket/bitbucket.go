@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"golang.org/x/oauth2"
 
@@ -216,15 +217,37 @@ func (c *config) File(ctx context.Context, u *model.User, r *model.Repo, b *mode
 	return []byte(*config), err
 }
 
+// Dir fetches all files of the folder from the Bitbucket repository.
 func (c *config) Dir(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, f string) ([]*remote.FileMeta, error) {
-	return nil, fmt.Errorf("Not implemented")
+	client := c.newClient(ctx, u)
+
+	sources, err := client.ListSourceAll(r.Owner, r.Name, b.Commit, strings.TrimSuffix(f, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*remote.FileMeta
+	for _, source := range sources {
+		if source.Type != "commit_file" {
+			continue
+		}
+		data, err := client.FindSource(r.Owner, r.Name, b.Commit, source.Path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &remote.FileMeta{
+			Name: source.Path,
+			Data: []byte(*data),
+		})
+	}
+	return files, nil
 }
 
 // Status creates a build status for the Bitbucket commit.
 func (c *config) Status(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, link string, proc *model.Proc) error {
 	status := internal.BuildStatus{
 		State: convertStatus(b.Status),
-		Desc:  convertDesc(b.Status),
+		Desc:  b.Tests.Describe(convertDesc(b.Status)),
 		Key:   "Woodpecker",
 		Url:   link,
 	}
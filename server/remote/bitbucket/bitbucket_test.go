@@ -206,6 +206,20 @@ func Test_bitbucket(t *testing.T) {
 			})
 		})
 
+		g.Describe("When downloading a folder", func() {
+			g.It("Should return the files", func() {
+				files, err := c.Dir(ctx, fakeUser, fakeRepo, fakeBuild, "dir")
+				g.Assert(err).IsNil()
+				g.Assert(len(files)).Equal(1)
+				g.Assert(files[0].Name).Equal("dir/file")
+				g.Assert(string(files[0].Data)).Equal("dummy payload")
+			})
+			g.It("Should handle not found error", func() {
+				_, err := c.Dir(ctx, fakeUser, fakeRepo, fakeBuild, "dir_not_found")
+				g.Assert(err).IsNotNil()
+			})
+		})
+
 		g.Describe("When activating a repository", func() {
 			g.It("Should error when malformed hook", func() {
 				err := c.Activate(ctx, fakeUser, fakeRepo, "%gh&%ij")
@@ -30,7 +30,7 @@ func Handler() http.Handler {
 	e.POST("/site/oauth2/access_token", getOauth)
 	e.GET("/2.0/repositories/:owner/:name", getRepo)
 	e.GET("/2.0/repositories/:owner/:name/hooks", getRepoHooks)
-	e.GET("/2.0/repositories/:owner/:name/src/:commit/:file", getRepoFile)
+	e.GET("/2.0/repositories/:owner/:name/src/:commit/*file", getRepoFile)
 	e.DELETE("/2.0/repositories/:owner/:name/hooks/:hook", deleteRepoHook)
 	e.POST("/2.0/repositories/:owner/:name/hooks", createRepoHook)
 	e.POST("/2.0/repositories/:owner/:name/commit/:commit/statuses/build", createRepoStatus)
@@ -92,8 +92,10 @@ func getRepoHooks(c *gin.Context) {
 
 func getRepoFile(c *gin.Context) {
 	switch c.Param("file") {
-	case "file_not_found":
+	case "/file_not_found", "/dir_not_found/":
 		c.String(404, "")
+	case "/dir/":
+		c.String(200, repoDirPayload)
 	default:
 		c.String(200, repoFilePayload)
 	}
@@ -216,6 +218,23 @@ const repoHookPayload = `
 
 const repoFilePayload = "dummy payload"
 
+const repoDirPayload = `
+{
+  "pagelen": 100,
+  "page": 1,
+  "values": [
+    {
+      "path": "dir/file",
+      "type": "commit_file"
+    },
+    {
+      "path": "dir/subdir",
+      "type": "commit_directory"
+    }
+  ]
+}
+`
+
 const userPayload = `
 {
   "username": "superman",
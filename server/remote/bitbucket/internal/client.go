@@ -45,6 +45,7 @@ const (
 	pathHook        = "%s/2.0/repositories/%s/%s/hooks/%s"
 	pathHooks       = "%s/2.0/repositories/%s/%s/hooks?%s"
 	pathSource      = "%s/2.0/repositories/%s/%s/src/%s/%s"
+	pathSourceDir   = "%s/2.0/repositories/%s/%s/src/%s/%s/?%s"
 	pathStatus      = "%s/2.0/repositories/%s/%s/commit/%s/statuses/build"
 )
 
@@ -155,6 +156,31 @@ func (c *Client) FindSource(owner, name, revision, path string) (*string, error)
 	return c.do(uri, get, nil, nil)
 }
 
+func (c *Client) ListSource(owner, name, revision, path string, opts *ListOpts) (*SourceResp, error) {
+	out := new(SourceResp)
+	uri := fmt.Sprintf(pathSourceDir, c.base, owner, name, revision, path, opts.Encode())
+	_, err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+func (c *Client) ListSourceAll(owner, name, revision, path string) ([]*Source, error) {
+	var page = 1
+	var sources []*Source
+
+	for {
+		resp, err := c.ListSource(owner, name, revision, path, &ListOpts{Page: page, PageLen: 100})
+		if err != nil {
+			return sources, err
+		}
+		sources = append(sources, resp.Values...)
+		if len(resp.Next) == 0 {
+			break
+		}
+		page = resp.Page + 1
+	}
+	return sources, nil
+}
+
 func (c *Client) CreateStatus(owner, name, revision string, status *BuildStatus) error {
 	uri := fmt.Sprintf(pathStatus, c.base, owner, name, revision)
 	_, err := c.do(uri, post, status, nil)
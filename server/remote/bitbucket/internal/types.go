@@ -107,6 +107,19 @@ type RepoResp struct {
 	Values []*Repo `json:"values"`
 }
 
+type Source struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+type SourceResp struct {
+	Page   int       `json:"page"`
+	Pages  int       `json:"pagelen"`
+	Size   int       `json:"size"`
+	Next   string    `json:"next"`
+	Values []*Source `json:"values"`
+}
+
 type Change struct {
 	New struct {
 		Type   string `json:"type"`
@@ -181,23 +181,40 @@ func (c *Config) File(ctx context.Context, u *model.User, r *model.Repo, b *mode
 	return client.FindFileForRepo(r.Owner, r.Name, f, b.Ref)
 }
 
+// Dir fetches all files of the folder from the Bitbucket Server repository.
 func (c *Config) Dir(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, f string) ([]*remote.FileMeta, error) {
-	return nil, fmt.Errorf("Not implemented")
-}
+	client := internal.NewClientWithToken(ctx, c.URL, c.Consumer, u.Token)
 
-// Status is not supported by the bitbucketserver driver.
-func (c *Config) Status(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, link string, proc *model.Proc) error {
-	status := internal.BuildStatus{
-		State: convertStatus(b.Status),
-		Desc:  convertDesc(b.Status),
-		Name:  fmt.Sprintf("Woodpecker #%d - %s", b.Number, b.Branch),
-		Key:   "Woodpecker",
-		Url:   link,
+	f = strings.TrimSuffix(f, "/")
+	names, err := client.ListFiles(r.Owner, r.Name, f, b.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*remote.FileMeta
+	for _, name := range names {
+		// skip files of sub folders
+		if strings.Contains(name, "/") {
+			continue
+		}
+		data, err := client.FindFileForRepo(r.Owner, r.Name, f+"/"+name, b.Ref)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &remote.FileMeta{
+			Name: f + "/" + name,
+			Data: data,
+		})
 	}
+	return files, nil
+}
 
+// Status creates a build status for the Bitbucket Server commit. If a
+// proc is given, a separate status is created for the workflow.
+func (c *Config) Status(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, link string, proc *model.Proc) error {
 	client := internal.NewClientWithToken(ctx, c.URL, c.Consumer, u.Token)
 
-	return client.CreateStatus(b.Commit, &status)
+	return client.CreateStatus(b.Commit, convertBuildStatus(b, link, proc))
 }
 
 func (c *Config) Netrc(user *model.User, r *model.Repo) (*model.Netrc, error) {
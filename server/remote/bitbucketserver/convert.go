@@ -69,6 +69,25 @@ func convertDesc(status string) string {
 	}
 }
 
+// convertBuildStatus is a helper function used to convert the build, or
+// the workflow if not nil, to a Bitbucket Server build status.
+func convertBuildStatus(b *model.Build, link string, proc *model.Proc) *internal.BuildStatus {
+	status := &internal.BuildStatus{
+		State: convertStatus(b.Status),
+		Desc:  b.Tests.Describe(convertDesc(b.Status)),
+		Name:  fmt.Sprintf("Woodpecker #%d - %s", b.Number, b.Branch),
+		Key:   "Woodpecker",
+		Url:   link,
+	}
+	if proc != nil {
+		status.State = convertStatus(proc.State)
+		status.Desc = proc.Tests.Describe(convertDesc(proc.State))
+		status.Name += " - " + proc.Name
+		status.Key += "/" + proc.Name
+	}
+	return status
+}
+
 // convertRepo is a helper function used to convert a Bitbucket server repository
 // structure to the common Woodpecker repository structure.
 func convertRepo(from *internal.Repo) *model.Repo {
@@ -59,6 +59,35 @@ func Test_helper(t *testing.T) {
 			g.Assert(to.Link).Equal("https://server.org/foo/bar")
 		})
 
+		g.It("should convert build status", func() {
+			build := &model.Build{
+				Number: 1,
+				Branch: "main",
+				Status: model.StatusRunning,
+			}
+			status := convertBuildStatus(build, "http://woodpecker/1", nil)
+			g.Assert(status.Key).Equal("Woodpecker")
+			g.Assert(status.Name).Equal("Woodpecker #1 - main")
+			g.Assert(status.State).Equal(statusPending)
+			g.Assert(status.Url).Equal("http://woodpecker/1")
+		})
+
+		g.It("should convert workflow status", func() {
+			build := &model.Build{
+				Number: 1,
+				Branch: "main",
+				Status: model.StatusRunning,
+			}
+			proc := &model.Proc{
+				Name:  "test",
+				State: model.StatusFailure,
+			}
+			status := convertBuildStatus(build, "http://woodpecker/1", proc)
+			g.Assert(status.Key).Equal("Woodpecker/test")
+			g.Assert(status.Name).Equal("Woodpecker #1 - main - test")
+			g.Assert(status.State).Equal(statusFailure)
+		})
+
 		g.It("should convert user", func() {
 			token := &oauth.AccessToken{
 				Token: "foo",
@@ -38,6 +38,7 @@ const (
 	pathRepos        = "%s/rest/api/1.0/repos?start=%s&limit=%s"
 	pathHook         = "%s/rest/api/1.0/projects/%s/repos/%s/settings/hooks/%s"
 	pathSource       = "%s/projects/%s/repos/%s/browse/%s?at=%s&raw"
+	pathFiles        = "%s/rest/api/1.0/projects/%s/repos/%s/files/%s?at=%s&start=%d&limit=%d"
 	hookName         = "com.atlassian.stash.plugin.stash-web-post-receive-hooks-plugin:postReceiveHook"
 	pathHookDetails  = "%s/rest/api/1.0/projects/%s/repos/%s/settings/hooks/%s"
 	pathHookEnabled  = "%s/rest/api/1.0/projects/%s/repos/%s/settings/hooks/%s/enabled"
@@ -166,6 +167,37 @@ func (c *Client) FindFileForRepo(owner string, repo string, fileName string, ref
 	return responseBytes, nil
 }
 
+// ListFiles returns the paths of all files below the folder, relative
+// to the folder.
+func (c *Client) ListFiles(owner string, repo string, path string, ref string) ([]string, error) {
+	var (
+		files []string
+		start = 0
+		limit = 1000
+	)
+	for {
+		response, err := c.doGet(fmt.Sprintf(pathFiles, c.base, owner, repo, path, ref, start, limit))
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return nil, fmt.Errorf("could not list files of %s: %s", path, response.Status)
+		}
+		var page Files
+		err = json.NewDecoder(response.Body).Decode(&page)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, page.Values...)
+		if page.IsLastPage {
+			return files, nil
+		}
+		start = page.NextPageStart
+	}
+}
+
 func (c *Client) CreateHook(owner string, name string, callBackLink string) error {
 	hookDetails, err := c.GetHookDetails(owner, name)
 	if err != nil {
@@ -82,6 +82,15 @@ type Repos struct {
 	Values     []*Repo `json:"values"`
 }
 
+type Files struct {
+	IsLastPage    bool     `json:"isLastPage"`
+	Limit         int      `json:"limit"`
+	NextPageStart int      `json:"nextPageStart"`
+	Size          int      `json:"size"`
+	Start         int      `json:"start"`
+	Values        []string `json:"values"`
+}
+
 type Hook struct {
 	Enabled bool        `json:"enabled"`
 	Details *HookDetail `json:"details"`
@@ -238,8 +238,29 @@ func (c *Coding) File(ctx context.Context, u *model.User, r *model.Repo, b *mode
 	return data, nil
 }
 
+// Dir fetches all files of the folder from the remote repository.
 func (c *Coding) Dir(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, f string) ([]*remote.FileMeta, error) {
-	return nil, fmt.Errorf("Not implemented")
+	client := c.newClient(ctx, u)
+	infos, err := client.GetTree(r.Owner, r.Name, b.Commit, strings.TrimSuffix(f, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*remote.FileMeta
+	for _, info := range infos {
+		if info.Mode != "file" {
+			continue
+		}
+		data, err := client.GetFile(r.Owner, r.Name, b.Commit, info.Path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &remote.FileMeta{
+			Name: info.Path,
+			Data: data,
+		})
+	}
+	return files, nil
 }
 
 // Status sends the commit status to the remote system.
@@ -170,6 +170,20 @@ func Test_coding(t *testing.T) {
 			})
 		})
 
+		g.Describe("When downloading a folder", func() {
+			g.It("Should return the files for specified build", func() {
+				files, err := c.Dir(ctx, fakeUser, fakeRepo, fakeBuild, ".woodpecker")
+				g.Assert(err).IsNil()
+				g.Assert(len(files)).Equal(1)
+				g.Assert(files[0].Name).Equal(".woodpecker/test.yml")
+				g.Assert(string(files[0].Data)).Equal("pipeline:\n  test:\n    image: golang:1.6\n    commands:\n      - go test\n")
+			})
+			g.It("Should return error for a missing folder", func() {
+				_, err := c.Dir(ctx, fakeUser, fakeRepo, fakeBuild, "missing")
+				g.Assert(err).IsNotNil()
+			})
+		})
+
 		g.Describe("When requesting a netrc config", func() {
 			g.It("Should return the netrc file for global credential", func() {
 				remote, _ := New(Opts{
@@ -31,7 +31,8 @@ func Handler() http.Handler {
 	e.GET("/api/account/current_user", getUser)
 	e.GET("/api/user/:gk/project/:prj", getProject)
 	e.GET("/api/user/:gk/project/:prj/git", getDepot)
-	e.GET("/api/user/:gk/project/:prj/git/blob/:ref/:path", getFile)
+	e.GET("/api/user/:gk/project/:prj/git/blob/:ref/*path", getFile)
+	e.GET("/api/user/:gk/project/:prj/git/tree/:ref/*path", getTree)
 	e.GET("/api/user/:gk/project/:prj/git/hooks", getHooks)
 	e.POST("/api/user/:gk/project/:prj/git/hook", postHook)
 	e.PUT("/api/user/:gk/project/:prj/git/hook/:id", putHook)
@@ -107,14 +108,25 @@ func getProjects(c *gin.Context) {
 
 func getFile(c *gin.Context) {
 	c.Header("Content-Type", "application/json;charset=UTF-8")
-	switch fmt.Sprintf("%s/%s/%s/%s", c.Param("gk"), c.Param("prj"), c.Param("ref"), c.Param("path")) {
-	case "demo1/test1/master/.woodpecker.yml", "demo1/test1/4504a072cc/.woodpecker.yml":
+	switch fmt.Sprintf("%s/%s/%s%s", c.Param("gk"), c.Param("prj"), c.Param("ref"), c.Param("path")) {
+	case "demo1/test1/master/.woodpecker.yml", "demo1/test1/4504a072cc/.woodpecker.yml",
+		"demo1/test1/4504a072cc/.woodpecker/test.yml":
 		c.String(200, fakeFilePayload)
 	default:
 		c.String(200, fileNotFoundPayload)
 	}
 }
 
+func getTree(c *gin.Context) {
+	c.Header("Content-Type", "application/json;charset=UTF-8")
+	switch fmt.Sprintf("%s/%s/%s%s", c.Param("gk"), c.Param("prj"), c.Param("ref"), c.Param("path")) {
+	case "demo1/test1/4504a072cc/.woodpecker":
+		c.String(200, fakeTreePayload)
+	default:
+		c.String(404, "")
+	}
+}
+
 func getHooks(c *gin.Context) {
 	c.Header("Content-Type", "application/json;charset=UTF-8")
 	c.String(200, fakeHooksPayload)
@@ -301,6 +313,27 @@ const fakeFilePayload = `
 }
 `
 
+const fakeTreePayload = `
+{
+    "code":0,
+    "data":{
+        "ref":"4504a072cc",
+        "infos":[
+            {
+                "name":"test.yml",
+                "path":".woodpecker/test.yml",
+                "mode":"file"
+            },
+            {
+                "name":"templates",
+                "path":".woodpecker/templates",
+                "mode":"tree"
+            }
+        ]
+    }
+}
+`
+
 const fileNotFoundPayload = `
 {
     "code":0,
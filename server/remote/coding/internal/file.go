@@ -27,6 +27,16 @@ type File struct {
 	Data string `json:"data"`
 }
 
+type Tree struct {
+	Infos []*TreeInfo `json:"infos"`
+}
+
+type TreeInfo struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+}
+
 func (c *Client) GetFile(globalKey, projectName, ref, path string) ([]byte, error) {
 	u := fmt.Sprintf("/user/%s/project/%s/git/blob/%s/%s", globalKey, projectName, ref, path)
 	resp, err := c.Get(u, nil)
@@ -43,3 +53,17 @@ func (c *Client) GetFile(globalKey, projectName, ref, path string) ([]byte, erro
 	}
 	return []byte(commit.File.Data), nil
 }
+
+func (c *Client) GetTree(globalKey, projectName, ref, path string) ([]*TreeInfo, error) {
+	u := fmt.Sprintf("/user/%s/project/%s/git/tree/%s/%s", globalKey, projectName, ref, path)
+	resp, err := c.Get(u, nil)
+	if err != nil {
+		return nil, err
+	}
+	tree := &Tree{}
+	err = json.Unmarshal(resp, tree)
+	if err != nil {
+		return nil, APIClientErr{"fail to parse tree data", u, err}
+	}
+	return tree.Infos, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixtures
+
+// HookPush is a sample push hook.
+const HookPush = `
+{
+  "event": "push",
+  "ref": "refs/heads/main",
+  "before": "4b2626259b5a97b6b4eab5e6cca66adb986b672b",
+  "after": "ef98532add3b2feb7a137426bba1248724367df5",
+  "sender": "gordon",
+  "repository": {
+    "owner": "gordon",
+    "name": "hello-world",
+    "clone_url": "https://git.example.com/gordon/hello-world.git",
+    "link": "https://git.example.com/cgit/gordon/hello-world",
+    "default_branch": "main",
+    "private": true
+  },
+  "commit": {
+    "message": "bump\n",
+    "link": "https://git.example.com/cgit/gordon/hello-world/commit/?id=ef98532add3b2feb7a137426bba1248724367df5",
+    "timestamp": 1637760000,
+    "author": {
+      "name": "Gordon",
+      "email": "gordon@example.com",
+      "avatar": "https://example.com/gordon.png"
+    }
+  }
+}
+`
+
+// HookTag is a sample tag hook without explicit event and clone url.
+const HookTag = `
+{
+  "ref": "refs/tags/v1.0.0",
+  "after": "ef98532add3b2feb7a137426bba1248724367df5",
+  "repository": {
+    "owner": "gordon",
+    "name": "hello-world",
+    "default_branch": "main"
+  },
+  "commit": {
+    "message": "release\n",
+    "author": {
+      "name": "Gordon",
+      "email": "gordon@example.com"
+    }
+  }
+}
+`
+
+// HookDelete is a sample hook of a deleted branch.
+const HookDelete = `
+{
+  "event": "push",
+  "ref": "refs/heads/feature",
+  "before": "ef98532add3b2feb7a137426bba1248724367df5",
+  "after": "0000000000000000000000000000000000000000",
+  "repository": {
+    "owner": "gordon",
+    "name": "hello-world"
+  }
+}
+`
@@ -0,0 +1,218 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generic
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+)
+
+// Opts defines configuration options.
+type Opts struct {
+	URL        string   // Base url repositories are cloned from.
+	UsersFile  string   // Path to a htpasswd file with the local user accounts.
+	Repos      []string // Optional list of repositories in owner/name format.
+	Username   string   // Optional machine account username.
+	Password   string   // Optional machine account password.
+	SkipVerify bool     // Skip ssl verification.
+}
+
+type client struct {
+	URL        string
+	Machine    string
+	UsersFile  string
+	RepoList   []string
+	Username   string
+	Password   string
+	SkipVerify bool
+}
+
+// New returns a Remote implementation that integrates with plain git
+// servers, like git-daemon or cgit. Builds are triggered by a documented
+// json payload sent from a post-receive hook and users are local accounts.
+func New(opts Opts) (remote.Remote, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(u.Host)
+	if err == nil {
+		u.Host = host
+	}
+	return &client{
+		URL:        strings.TrimSuffix(opts.URL, "/"),
+		Machine:    u.Host,
+		UsersFile:  opts.UsersFile,
+		RepoList:   opts.Repos,
+		Username:   opts.Username,
+		Password:   opts.Password,
+		SkipVerify: opts.SkipVerify,
+	}, nil
+}
+
+// Login authenticates a local account using the users file.
+func (c *client) Login(ctx context.Context, res http.ResponseWriter, req *http.Request) (*model.User, error) {
+	var (
+		username = req.FormValue("username")
+		password = req.FormValue("password")
+	)
+
+	// if the username or password is empty we re-direct to the login screen.
+	if len(username) == 0 || len(password) == 0 {
+		http.Redirect(res, req, "/login/form", http.StatusSeeOther)
+		return nil, nil
+	}
+
+	if err := authenticate(c.UsersFile, username, password); err != nil {
+		return nil, err
+	}
+	return &model.User{
+		Login: username,
+	}, nil
+}
+
+// Auth is not supported by the generic driver.
+func (c *client) Auth(ctx context.Context, token, secret string) (string, error) {
+	return "", fmt.Errorf("Not Implemented")
+}
+
+// Teams is not supported by the generic driver.
+func (c *client) Teams(ctx context.Context, u *model.User) ([]*model.Team, error) {
+	return nil, nil
+}
+
+// Repo returns the named repository. If a list of repositories is
+// configured, only those are known.
+func (c *client) Repo(ctx context.Context, u *model.User, owner, name string) (*model.Repo, error) {
+	fullName := owner + "/" + name
+	if len(c.RepoList) != 0 && !c.hasRepo(fullName) {
+		return nil, fmt.Errorf("Repository %s not found", fullName)
+	}
+	return c.toRepo(fullName), nil
+}
+
+// Repos returns the configured list of repositories.
+func (c *client) Repos(ctx context.Context, u *model.User) ([]*model.Repo, error) {
+	repos := []*model.Repo{}
+	for _, fullName := range c.RepoList {
+		if strings.Count(fullName, "/") != 1 {
+			continue
+		}
+		repos = append(repos, c.toRepo(fullName))
+	}
+	return repos, nil
+}
+
+// Perm returns the user permissions for the named repository. Local
+// accounts have full access to all known repositories.
+func (c *client) Perm(ctx context.Context, u *model.User, owner, name string) (*model.Perm, error) {
+	if len(c.RepoList) != 0 && !c.hasRepo(owner+"/"+name) {
+		return &model.Perm{}, nil
+	}
+	return &model.Perm{Pull: true, Push: true, Admin: true}, nil
+}
+
+// File fetches the file from the repository and returns its contents.
+func (c *client) File(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, f string) ([]byte, error) {
+	files, err := c.fetch(ctx, r, b, f, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("File %s not found", f)
+	}
+	return files[0].Data, nil
+}
+
+// Dir fetches all files of the folder from the repository.
+func (c *client) Dir(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, f string) ([]*remote.FileMeta, error) {
+	return c.fetch(ctx, r, b, strings.TrimSuffix(f, "/"), true)
+}
+
+// Status is not supported by the generic driver.
+func (c *client) Status(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, link string, proc *model.Proc) error {
+	return nil
+}
+
+// Netrc returns a netrc file capable of cloning the repositories using
+// the global machine account.
+func (c *client) Netrc(u *model.User, r *model.Repo) (*model.Netrc, error) {
+	return &model.Netrc{
+		Login:    c.Username,
+		Password: c.Password,
+		Machine:  c.Machine,
+	}, nil
+}
+
+// Activate is not supported by the generic driver. The post-receive hook
+// has to be installed on the git server manually.
+func (c *client) Activate(ctx context.Context, u *model.User, r *model.Repo, link string) error {
+	return nil
+}
+
+// Deactivate is not supported by the generic driver.
+func (c *client) Deactivate(ctx context.Context, u *model.User, r *model.Repo, link string) error {
+	return nil
+}
+
+// Branches returns the names of all branches for the named repository.
+func (c *client) Branches(ctx context.Context, u *model.User, r *model.Repo) ([]string, error) {
+	return c.branches(ctx, r)
+}
+
+// Hook parses the incoming hook and returns the Repository and Build
+// details. If the hook is unsupported nil values are returned.
+func (c *client) Hook(r *http.Request) (*model.Repo, *model.Build, error) {
+	repo, build, err := parseHook(r.Body)
+	if repo != nil && repo.Clone == "" {
+		repo.Clone = c.cloneURL(repo.FullName)
+	}
+	return repo, build, err
+}
+
+func (c *client) hasRepo(fullName string) bool {
+	for _, repo := range c.RepoList {
+		if repo == fullName {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function that returns the repository cloned from the base url.
+func (c *client) toRepo(fullName string) *model.Repo {
+	parts := strings.SplitN(fullName, "/", 2)
+	return &model.Repo{
+		Kind:      model.RepoGit,
+		Owner:     parts[0],
+		Name:      parts[1],
+		FullName:  fullName,
+		Link:      c.URL + "/" + fullName,
+		Clone:     c.cloneURL(fullName),
+		Branch:    "master",
+		IsPrivate: c.Password != "",
+	}
+}
+
+func (c *client) cloneURL(fullName string) string {
+	return c.URL + "/" + fullName + ".git"
+}
@@ -0,0 +1,219 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generic
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+)
+
+var shaRegexp = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+
+// validRef returns an error unless the ref is a commit sha or a full ref
+// name, refs and names are passed to git as arguments.
+func validRef(ref string) error {
+	if shaRegexp.MatchString(ref) {
+		return nil
+	}
+	if !strings.HasPrefix(ref, "refs/") || strings.Contains(ref, "..") || strings.ContainsAny(ref, " \t\n\\:~^?*[") {
+		return fmt.Errorf("invalid git ref %q", ref)
+	}
+	return nil
+}
+
+// validName returns an error if the file name is not a relative path
+// within the repository.
+func validName(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, "/") {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if elem == ".." {
+			return fmt.Errorf("invalid file name %q", name)
+		}
+	}
+	return nil
+}
+
+// fetch reads the file, or all files of the folder if dir is true, at
+// the build commit. It first tries git archive, which is supported by
+// git-daemon and ssh remotes, and falls back to a shallow fetch of the
+// commit into a temporary repository.
+func (c *client) fetch(ctx context.Context, r *model.Repo, b *model.Build, name string, dir bool) ([]*remote.FileMeta, error) {
+	remoteURL := c.remoteURL(r)
+	ref := b.Commit
+	if ref == "" {
+		ref = b.Ref
+	}
+	if err := validRef(ref); err != nil {
+		return nil, err
+	}
+	if b.Ref != "" && b.Ref != ref {
+		if err := validRef(b.Ref); err != nil {
+			return nil, err
+		}
+	}
+	if err := validName(name); err != nil {
+		return nil, err
+	}
+
+	if files, err := c.archive(ctx, remoteURL, ref, name, dir); err == nil {
+		return files, nil
+	}
+
+	tmp, err := ioutil.TempDir("", "woodpecker-generic")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	if _, err := c.git(ctx, tmp, "init", "-q"); err != nil {
+		return nil, err
+	}
+	if _, err := c.git(ctx, tmp, "fetch", "-q", "--depth=1", "--end-of-options", remoteURL, ref); err != nil {
+		// servers usually do not allow to fetch unadvertised commits
+		if b.Ref == "" || b.Ref == ref {
+			return nil, err
+		}
+		if _, err := c.git(ctx, tmp, "fetch", "-q", "--depth=1", "--end-of-options", remoteURL, b.Ref); err != nil {
+			return nil, err
+		}
+	}
+
+	if !dir {
+		data, err := c.git(ctx, tmp, "show", "--end-of-options", "FETCH_HEAD:"+name)
+		if err != nil {
+			return nil, err
+		}
+		return []*remote.FileMeta{{Name: name, Data: data}}, nil
+	}
+
+	out, err := c.git(ctx, tmp, "ls-tree", "--end-of-options", "FETCH_HEAD", name+"/")
+	if err != nil {
+		return nil, err
+	}
+	var files []*remote.FileMeta
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		// <mode> SP <type> SP <object> TAB <file>
+		fields := strings.SplitN(scanner.Text(), "\t", 2)
+		meta := strings.Fields(fields[0])
+		if len(fields) != 2 || len(meta) != 3 || meta[1] != "blob" {
+			continue
+		}
+		data, err := c.git(ctx, tmp, "cat-file", "blob", "--end-of-options", meta[2])
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &remote.FileMeta{Name: fields[1], Data: data})
+	}
+	return files, nil
+}
+
+// archive reads the file or folder using git archive.
+func (c *client) archive(ctx context.Context, remoteURL, ref, name string, dir bool) ([]*remote.FileMeta, error) {
+	out, err := c.git(ctx, "", "archive", "--format=tar", "--remote="+remoteURL, "--end-of-options", ref, "--", name)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*remote.FileMeta
+	reader := tar.NewReader(bytes.NewReader(out))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if dir && path.Dir(header.Name) != name || !dir && header.Name != name {
+			continue
+		}
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &remote.FileMeta{Name: header.Name, Data: data})
+	}
+	return files, nil
+}
+
+// branches lists the branches of the repository using git ls-remote.
+func (c *client) branches(ctx context.Context, r *model.Repo) ([]string, error) {
+	out, err := c.git(ctx, "", "ls-remote", "--heads", "--end-of-options", c.remoteURL(r))
+	if err != nil {
+		return nil, err
+	}
+	var branches []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		branches = append(branches, strings.TrimPrefix(fields[1], "refs/heads/"))
+	}
+	return branches, nil
+}
+
+// remoteURL returns the clone url of the repository, including the
+// machine account credentials for http remotes.
+func (c *client) remoteURL(r *model.Repo) string {
+	clone := r.Clone
+	if clone == "" {
+		clone = c.cloneURL(r.FullName)
+	}
+	u, err := url.Parse(clone)
+	if err != nil || c.Username == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return clone
+	}
+	u.User = url.UserPassword(c.Username, c.Password)
+	return u.String()
+}
+
+// git runs the git command in dir and returns its output.
+func (c *client) git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if c.SkipVerify {
+		cmd.Env = append(cmd.Env, "GIT_SSL_NO_VERIFY=true")
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+const (
+	eventPush = "push"
+	eventTag  = "tag"
+
+	refHeads = "refs/heads/"
+	refTags  = "refs/tags/"
+
+	zeroCommit = "0000000000000000000000000000000000000000"
+)
+
+// hook is the documented payload sent by the post-receive hook.
+type hook struct {
+	Event  string `json:"event"`
+	Ref    string `json:"ref"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+	Sender string `json:"sender"`
+
+	Repo struct {
+		Owner         string `json:"owner"`
+		Name          string `json:"name"`
+		CloneURL      string `json:"clone_url"`
+		Link          string `json:"link"`
+		DefaultBranch string `json:"default_branch"`
+		Private       bool   `json:"private"`
+	} `json:"repository"`
+
+	Commit struct {
+		Message   string `json:"message"`
+		Link      string `json:"link"`
+		Timestamp int64  `json:"timestamp"`
+		Author    struct {
+			Name   string `json:"name"`
+			Email  string `json:"email"`
+			Avatar string `json:"avatar"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// parseHook parses the hook payload and returns the Repo and Build
+// details. Deleted refs are ignored and nil values are returned.
+func parseHook(payload io.Reader) (*model.Repo, *model.Build, error) {
+	h := new(hook)
+	if err := json.NewDecoder(payload).Decode(h); err != nil {
+		return nil, nil, err
+	}
+	if h.Repo.Owner == "" || h.Repo.Name == "" {
+		return nil, nil, fmt.Errorf("Missing repository owner or name")
+	}
+	if h.Ref == "" || h.After == "" {
+		return nil, nil, fmt.Errorf("Missing ref or commit")
+	}
+	if h.After == zeroCommit {
+		return nil, nil, nil
+	}
+
+	event := h.Event
+	if event == "" {
+		event = eventPush
+		if strings.HasPrefix(h.Ref, refTags) {
+			event = eventTag
+		}
+	}
+
+	build := &model.Build{
+		Commit:    h.After,
+		Ref:       h.Ref,
+		Link:      h.Commit.Link,
+		Message:   h.Commit.Message,
+		Author:    h.Commit.Author.Name,
+		Email:     h.Commit.Author.Email,
+		Avatar:    h.Commit.Author.Avatar,
+		Timestamp: h.Commit.Timestamp,
+		Sender:    h.Sender,
+	}
+	if build.Timestamp == 0 {
+		build.Timestamp = time.Now().UTC().Unix()
+	}
+	if build.Sender == "" {
+		build.Sender = build.Author
+	}
+
+	switch event {
+	case eventPush:
+		build.Event = model.EventPush
+		build.Branch = strings.TrimPrefix(h.Ref, refHeads)
+	case eventTag:
+		build.Event = model.EventTag
+		build.Branch = h.Repo.DefaultBranch
+	default:
+		return nil, nil, nil
+	}
+
+	repo := &model.Repo{
+		Kind:      model.RepoGit,
+		Owner:     h.Repo.Owner,
+		Name:      h.Repo.Name,
+		FullName:  h.Repo.Owner + "/" + h.Repo.Name,
+		Link:      h.Repo.Link,
+		Clone:     h.Repo.CloneURL,
+		Branch:    h.Repo.DefaultBranch,
+		IsPrivate: h.Repo.Private,
+	}
+	return repo, build, nil
+}
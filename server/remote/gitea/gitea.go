@@ -336,15 +336,23 @@ func (c *Gitea) Dir(ctx context.Context, u *model.User, r *model.Repo, b *model.
 	return configs, nil
 }
 
-// Status is supported by the Gitea driver.
+// Status is supported by the Gitea driver. If a proc is given, a
+// separate status is sent for the workflow.
 func (c *Gitea) Status(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, link string, proc *model.Proc) error {
 	client, err := c.newClientToken(ctx, u.Token)
 	if err != nil {
 		return err
 	}
 
+	statusContext := c.Context
 	status := getStatus(b.Status)
-	desc := getDesc(b.Status)
+	desc := b.Tests.Describe(getDesc(b.Status))
+
+	if proc != nil {
+		statusContext += "/" + proc.Name
+		status = getStatus(proc.State)
+		desc = proc.Tests.Describe(getDesc(proc.State))
+	}
 
 	_, _, err = client.CreateStatus(
 		r.Owner,
@@ -354,7 +362,7 @@ func (c *Gitea) Status(ctx context.Context, u *model.User, r *model.Repo, b *mod
 			State:       status,
 			TargetURL:   link,
 			Description: desc,
-			Context:     c.Context,
+			Context:     statusContext,
 		},
 	)
 
@@ -156,6 +156,11 @@ func Test_gitea(t *testing.T) {
 			g.Assert(err).IsNil()
 		})
 
+		g.It("Should return nil from send workflow status", func() {
+			err := c.Status(ctx, fakeUser, fakeRepo, fakeBuild, "http://gitea.io", fakeProc)
+			g.Assert(err).IsNil()
+		})
+
 		g.Describe("Given an authentication request", func() {
 			g.It("Should redirect to login form")
 			g.It("Should create an access token")
@@ -197,4 +202,9 @@ var (
 	fakeBuild = &model.Build{
 		Commit: "9ecad50",
 	}
+
+	fakeProc = &model.Proc{
+		Name:  "test",
+		State: model.StatusSuccess,
+	}
 )
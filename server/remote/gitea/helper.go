@@ -168,6 +168,7 @@ func buildFromPullRequest(hook *pullRequestHook) *model.Build {
 		Avatar:  avatar,
 		Sender:  sender,
 		Title:   hook.PullRequest.Title,
+		Remote:  hook.PullRequest.Head.Repo.URL,
 		Refspec: fmt.Sprintf("%s:%s",
 			hook.PullRequest.Head.Ref,
 			hook.PullRequest.Base.Ref,
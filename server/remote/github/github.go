@@ -446,12 +446,12 @@ func repoStatus(c context.Context, client *github.Client, r *model.Repo, b *mode
 	}
 
 	status := github.String(convertStatus(b.Status))
-	desc := github.String(convertDesc(b.Status))
+	desc := github.String(b.Tests.Describe(convertDesc(b.Status)))
 
 	if proc != nil {
 		ctx += "/" + proc.Name
 		status = github.String(convertStatus(proc.State))
-		desc = github.String(convertDesc(proc.State))
+		desc = github.String(proc.Tests.Describe(convertDesc(proc.State)))
 	}
 
 	data := github.RepoStatus{
@@ -362,7 +362,7 @@ func (g *Gitlab) Status(ctx context.Context, user *model.User, repo *model.Repo,
 	_, _, err = client.Commits.SetCommitStatus(repo_.ID, build.Commit, &gitlab.SetCommitStatusOptions{
 		Ref:         gitlab.String(strings.ReplaceAll(build.Ref, "refs/heads/", "")),
 		State:       getStatus(build.Status),
-		Description: gitlab.String(getDesc(build.Status)),
+		Description: gitlab.String(build.Tests.Describe(getDesc(build.Status))),
 		TargetURL:   &link,
 		Name:        nil,
 		Context:     gitlab.String(statusContext),
@@ -27,7 +27,8 @@ func Handler() http.Handler {
 
 	e := gin.New()
 	e.GET("/api/v1/repos/:owner/:name", getRepo)
-	e.GET("/api/v1/repos/:owner/:name/raw/:commit/:file", getRepoFile)
+	e.GET("/api/v1/repos/:owner/:name/raw/:commit/*file", getRepoFile)
+	e.GET("/api/v1/repos/:owner/:name/contents/*path", getRepoContents)
 	e.POST("/api/v1/repos/:owner/:name/hooks", createRepoHook)
 	e.GET("/api/v1/user/repos", getUserRepos)
 
@@ -44,7 +45,7 @@ func getRepo(c *gin.Context) {
 }
 
 func getRepoFile(c *gin.Context) {
-	if c.Param("file") == "file_not_found" {
+	if c.Param("file") == "/file_not_found" {
 		c.String(404, "")
 	}
 	if c.Param("commit") == "v1.0.0" || c.Param("commit") == "9ecad50" {
@@ -53,6 +54,14 @@ func getRepoFile(c *gin.Context) {
 	c.String(404, "")
 }
 
+func getRepoContents(c *gin.Context) {
+	if c.Param("path") == "/.woodpecker" && c.Query("ref") == "9ecad50" {
+		c.String(200, repoContentsPayload)
+		return
+	}
+	c.String(404, "")
+}
+
 func createRepoHook(c *gin.Context) {
 	in := struct {
 		Type string `json:"type"`
@@ -102,6 +111,21 @@ const repoPayload = `
 
 const repoFilePayload = `{ platform: linux/amd64 }`
 
+const repoContentsPayload = `
+[
+  {
+    "type": "file",
+    "name": "build.yml",
+    "path": ".woodpecker/build.yml"
+  },
+  {
+    "type": "dir",
+    "name": "templates",
+    "path": ".woodpecker/templates"
+  }
+]
+`
+
 const userRepoPayload = `
 [
   {
@@ -17,6 +17,7 @@ package gogs
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -183,29 +184,34 @@ func (c *client) Perm(ctx context.Context, u *model.User, owner, name string) (*
 // File fetches the file from the Gogs repository and returns its contents.
 func (c *client) File(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, f string) ([]byte, error) {
 	client := c.newClientToken(u.Token)
-	ref := b.Commit
-
-	// TODO gogs does not yet return a sha with the pull request
-	// so unfortunately we need to use the pull request branch.
-	if b.Event == model.EventPull {
-		ref = b.Branch
-	}
-	if ref == "" {
-		// Remove refs/tags or refs/heads, Gogs needs a short ref
-		ref = strings.TrimPrefix(
-			strings.TrimPrefix(
-				b.Ref,
-				"refs/heads/",
-			),
-			"refs/tags/",
-		)
-	}
-	cfg, err := client.GetFile(r.Owner, r.Name, ref, f)
+	cfg, err := client.GetFile(r.Owner, r.Name, fileRef(b), f)
 	return cfg, err
 }
 
+// Dir fetches all files of the folder from the Gogs repository.
 func (c *client) Dir(ctx context.Context, u *model.User, r *model.Repo, b *model.Build, f string) ([]*remote.FileMeta, error) {
-	return nil, fmt.Errorf("Not implemented")
+	ref := fileRef(b)
+	entries, err := c.listContents(ctx, u.Token, r.Owner, r.Name, ref, strings.TrimSuffix(f, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	client := c.newClientToken(u.Token)
+	var files []*remote.FileMeta
+	for _, entry := range entries {
+		if entry.Type != "file" {
+			continue
+		}
+		data, err := client.GetFile(r.Owner, r.Name, ref, entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &remote.FileMeta{
+			Name: entry.Path,
+			Data: data,
+		})
+	}
+	return files, nil
 }
 
 // Status is not supported by the Gogs driver.
@@ -277,11 +283,43 @@ func (c *client) newClient() *gogs.Client {
 func (c *client) newClientToken(token string) *gogs.Client {
 	client := gogs.NewClient(c.URL, token)
 	if c.SkipVerify {
-		httpClient := &http.Client{}
+		client.SetHTTPClient(c.newHTTPClient())
+	}
+	return client
+}
+
+// helper function to return the http client used for requests not
+// supported by the Gogs client.
+func (c *client) newHTTPClient() *http.Client {
+	httpClient := &http.Client{}
+	if c.SkipVerify {
 		httpClient.Transport = &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 		}
-		client.SetHTTPClient(httpClient)
 	}
-	return client
+	return httpClient
+}
+
+// helper function to list the contents of a folder in the Gogs
+// repository. The Gogs client does not support the contents endpoint.
+func (c *client) listContents(ctx context.Context, token, owner, name, ref, path string) ([]*contentEntry, error) {
+	uri := fmt.Sprintf("%s/api/v1/repos/%s/%s/contents/%s?ref=%s", c.URL, owner, name, path, url.QueryEscape(ref))
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "token "+token)
+
+	resp, err := c.newHTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not list contents of %s: %s", path, resp.Status)
+	}
+
+	var entries []*contentEntry
+	err = json.NewDecoder(resp.Body).Decode(&entries)
+	return entries, err
 }
@@ -150,6 +150,19 @@ func Test_gogs(t *testing.T) {
 			g.Assert(string(raw)).Equal("{ platform: linux/amd64 }")
 		})
 
+		g.It("Should return the files of a folder", func() {
+			files, err := c.Dir(ctx, fakeUser, fakeRepo, fakeBuild, ".woodpecker")
+			g.Assert(err).IsNil()
+			g.Assert(len(files)).Equal(1)
+			g.Assert(files[0].Name).Equal(".woodpecker/build.yml")
+			g.Assert(string(files[0].Data)).Equal("{ platform: linux/amd64 }")
+		})
+
+		g.It("Should return error for a missing folder", func() {
+			_, err := c.Dir(ctx, fakeUser, fakeRepo, fakeBuildWithRef, ".woodpecker")
+			g.Assert(err).IsNotNil()
+		})
+
 		g.Describe("Given an authentication request", func() {
 			g.It("Should redirect to login form")
 			g.It("Should create an access token")
@@ -148,6 +148,7 @@ func buildFromPullRequest(hook *pullRequestHook) *model.Build {
 		Avatar:  avatar,
 		Sender:  sender,
 		Title:   hook.PullRequest.Title,
+		Remote:  hook.PullRequest.Head.Repo.URL,
 		Refspec: fmt.Sprintf("%s:%s",
 			hook.PullRequest.HeadBranch,
 			hook.PullRequest.BaseBranch,
@@ -224,3 +225,25 @@ func expandAvatar(repo, rawurl string) string {
 
 	return aurl.String()
 }
+
+// helper function that returns the ref files of the build are read at.
+func fileRef(b *model.Build) string {
+	ref := b.Commit
+
+	// TODO gogs does not yet return a sha with the pull request
+	// so unfortunately we need to use the pull request branch.
+	if b.Event == model.EventPull {
+		ref = b.Branch
+	}
+	if ref == "" {
+		// Remove refs/tags or refs/heads, Gogs needs a short ref
+		ref = strings.TrimPrefix(
+			strings.TrimPrefix(
+				b.Ref,
+				"refs/heads/",
+			),
+			"refs/tags/",
+		)
+	}
+	return ref
+}
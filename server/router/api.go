@@ -61,12 +61,19 @@ func apiRoutes(e *gin.Engine) {
 
 			repo.GET("/builds", api.GetBuilds)
 			repo.GET("/builds/:number", api.GetBuild)
+			repo.GET("/builds/:number/tests", api.GetBuildTests)
+			repo.GET("/builds/:number/coverage", api.GetBuildCoverage)
+			repo.GET("/tests/history", api.GetTestHistory)
 
-			// requires push permissions
+			// requires push permissions, the configs contain the resolved
+			// includes of other repositories and templates
+			repo.GET("/builds/:number/config", session.MustPush, api.GetBuildConfig)
 			repo.POST("/builds/:number", session.MustPush, api.PostBuild)
 			repo.DELETE("/builds/:number", session.MustPush, api.DeleteBuild)
-			repo.POST("/builds/:number/approve", session.MustPush, api.PostApproval)
-			repo.POST("/builds/:number/decline", session.MustPush, api.PostDecline)
+			repo.POST("/builds/:number/restart", session.MustPush, api.PostBuildRestart)
+			repo.POST("/builds/:number/promote", session.MustPush, api.PostPromote)
+			repo.POST("/builds/:number/approve", session.MustApprove, api.PostApproval)
+			repo.POST("/builds/:number/decline", session.MustApprove, api.PostDecline)
 			repo.DELETE("/builds/:number/:job", session.MustPush, api.DeleteBuild)
 
 			repo.GET("/logs/:number/:pid", api.GetProcLogs)
@@ -78,17 +85,17 @@ func apiRoutes(e *gin.Engine) {
 			repo.GET("/files/:number", api.FileList)
 			repo.GET("/files/:number/:proc/*file", api.FileGet)
 
-			// requires push permissions
-			repo.GET("/secrets", session.MustPush, api.GetSecretList)
+			// requires push permissions, auditors have read access
+			repo.GET("/secrets", session.MustAudit, api.GetSecretList)
 			repo.POST("/secrets", session.MustPush, api.PostSecret)
-			repo.GET("/secrets/:secret", session.MustPush, api.GetSecret)
+			repo.GET("/secrets/:secret", session.MustAudit, api.GetSecret)
 			repo.PATCH("/secrets/:secret", session.MustPush, api.PatchSecret)
 			repo.DELETE("/secrets/:secret", session.MustPush, api.DeleteSecret)
 
-			// requires push permissions
-			repo.GET("/registry", session.MustPush, api.GetRegistryList)
+			// requires push permissions, auditors have read access
+			repo.GET("/registry", session.MustAudit, api.GetRegistryList)
 			repo.POST("/registry", session.MustPush, api.PostRegistry)
-			repo.GET("/registry/:registry", session.MustPush, api.GetRegistry)
+			repo.GET("/registry/:registry", session.MustAudit, api.GetRegistry)
 			repo.PATCH("/registry/:registry", session.MustPush, api.PatchRegistry)
 			repo.DELETE("/registry/:registry", session.MustPush, api.DeleteRegistry)
 
@@ -97,14 +104,59 @@ func apiRoutes(e *gin.Engine) {
 			repo.DELETE("", session.MustRepoAdmin(), api.DeleteRepo)
 			repo.POST("/chown", session.MustRepoAdmin(), api.ChownRepo)
 			repo.POST("/repair", session.MustRepoAdmin(), api.RepairRepo)
+			repo.GET("/hook", session.MustRepoAdmin(), api.GetRepoHook)
 			repo.POST("/move", session.MustRepoAdmin(), api.MoveRepo)
+			repo.GET("/webhooks", session.MustRepoAdmin(), api.GetWebhookList)
+			repo.POST("/webhooks", session.MustRepoAdmin(), api.PostWebhook)
+			repo.GET("/webhooks/:webhook", session.MustRepoAdmin(), api.GetWebhook)
+			repo.PATCH("/webhooks/:webhook", session.MustRepoAdmin(), api.PatchWebhook)
+			repo.DELETE("/webhooks/:webhook", session.MustRepoAdmin(), api.DeleteWebhook)
+			repo.POST("/webhooks/:webhook/test", session.MustRepoAdmin(), api.PostWebhookTest)
+			repo.GET("/webhooks/:webhook/deliveries", session.MustRepoAdmin(), api.GetWebhookDeliveries)
+			repo.GET("/environments", api.GetEnvironmentList)
+			repo.POST("/environments", session.MustRepoAdmin(), api.PostEnvironment)
+			repo.GET("/environments/:environment", api.GetEnvironment)
+			repo.PATCH("/environments/:environment", session.MustRepoAdmin(), api.PatchEnvironment)
+			repo.DELETE("/environments/:environment", session.MustRepoAdmin(), api.DeleteEnvironment)
+			repo.GET("/environments/:environment/deployments", api.GetDeployments)
+			repo.POST("/environments/:environment/rollback/:number", session.MustPush, api.PostRollback)
+
+			repo.GET("/senders", session.MustRepoAdmin(), api.GetSenderList)
+			repo.POST("/senders", session.MustRepoAdmin(), api.PostSender)
+			repo.DELETE("/senders/:login", session.MustRepoAdmin(), api.DeleteSender)
+			repo.GET("/roles", session.MustRepoAdmin(), api.GetRepoRoles)
+			repo.POST("/roles", session.MustRepoAdmin(), api.PostRepoRole)
+			repo.DELETE("/roles/:login/:role", session.MustRepoAdmin(), api.DeleteRepoRole)
 		}
 	}
 
+	orgs := e.Group("/api/orgs/:owner")
+	{
+		orgs.Use(session.MustOrgAdmin())
+		orgs.GET("/roles", api.GetOrgRoles)
+		orgs.POST("/roles", api.PostOrgRole)
+		orgs.DELETE("/roles/:login/:role", api.DeleteOrgRole)
+	}
+
 	badges := e.Group("/api/badges/:owner/:name")
 	{
 		badges.GET("/status.svg", api.GetBadge)
+		badges.GET("/status.json", api.GetBadge)
 		badges.GET("/cc.xml", api.GetCC)
+
+		// the build details of the badges are only shown to users who
+		// can read the repository
+		details := badges.Group("")
+		{
+			details.Use(session.SetRepo())
+			details.Use(session.SetPerm())
+			details.Use(session.MustPull)
+
+			details.GET("/duration.svg", api.GetBadge)
+			details.GET("/duration.json", api.GetBadge)
+			details.GET("/coverage.svg", api.GetBadge)
+			details.GET("/coverage.json", api.GetBadge)
+		}
 	}
 
 	builds := e.Group("/api/builds")
@@ -137,6 +189,35 @@ func apiRoutes(e *gin.Engine) {
 		debugger.GET("/pprof/trace", debug.TraceHandler())
 	}
 
+	webhooks := e.Group("/api/webhooks")
+	{
+		webhooks.Use(session.MustAdmin())
+		webhooks.GET("", api.GetWebhookList)
+		webhooks.POST("", api.PostWebhook)
+		webhooks.GET("/:webhook", api.GetWebhook)
+		webhooks.PATCH("/:webhook", api.PatchWebhook)
+		webhooks.DELETE("/:webhook", api.DeleteWebhook)
+		webhooks.POST("/:webhook/test", api.PostWebhookTest)
+		webhooks.GET("/:webhook/deliveries", api.GetWebhookDeliveries)
+	}
+
+	templates := e.Group("/api/templates")
+	{
+		templates.Use(session.MustAdmin())
+		templates.GET("", api.GetTemplateList)
+		templates.POST("", api.PostTemplate)
+		templates.GET("/:template", api.GetTemplate)
+		templates.PATCH("/:template", api.PatchTemplate)
+		templates.DELETE("/:template", api.DeleteTemplate)
+	}
+
+	audit := e.Group("/api/audit")
+	{
+		audit.Use(session.MustAdmin())
+		audit.GET("", api.GetAuditEvents)
+		audit.GET("/export", api.ExportAuditEvents)
+	}
+
 	logLevel := e.Group("/api/log-level")
 	{
 		logLevel.Use(session.MustAdmin())
@@ -152,6 +233,12 @@ func apiRoutes(e *gin.Engine) {
 	sse := e.Group("/stream")
 	{
 		sse.GET("/events", api.EventStreamSSE)
+		sse.GET("/logs/:owner/:name/:build",
+			session.SetRepo(),
+			session.SetPerm(),
+			session.MustPull,
+			api.LogStreamSSE,
+		)
 		sse.GET("/logs/:owner/:name/:build/:number",
 			session.SetRepo(),
 			session.SetPerm(),
@@ -119,6 +119,15 @@ func SetPerm() gin.HandlerFunc {
 			perm.Admin = true
 		}
 
+		if user != nil {
+			roles, err := store_.RoleListUser(user, repo)
+			if err != nil {
+				log.Error().Msgf("Error fetching roles for %s %s. %s",
+					user.Login, repo.FullName, err)
+			}
+			perm.Grant(roles)
+		}
+
 		switch {
 		case repo.Visibility == model.VisibilityPublic:
 			perm.Pull = true
@@ -187,3 +196,55 @@ func MustPush(c *gin.Context) {
 		)
 	}
 }
+
+// MustApprove requires push permission or the approver role
+// to approve or decline gated builds.
+func MustApprove(c *gin.Context) {
+	user := User(c)
+	perm := Perm(c)
+
+	if perm.Push || perm.Approve {
+		c.Next()
+		return
+	}
+
+	// debugging
+	if user != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		log.Debug().Msgf("User %s denied approve access to %s",
+			user.Login, c.Request.URL.Path)
+
+	} else {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		log.Debug().Msgf("Guest denied approve access to %s %s",
+			c.Request.Method,
+			c.Request.URL.Path,
+		)
+	}
+}
+
+// MustAudit requires push permission or the auditor role
+// to read repository settings like secrets and registries.
+func MustAudit(c *gin.Context) {
+	user := User(c)
+	perm := Perm(c)
+
+	if perm.Push || perm.Audit {
+		c.Next()
+		return
+	}
+
+	// debugging
+	if user != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		log.Debug().Msgf("User %s denied audit access to %s",
+			user.Login, c.Request.URL.Path)
+
+	} else {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		log.Debug().Msgf("Guest denied audit access to %s %s",
+			c.Request.Method,
+			c.Request.URL.Path,
+		)
+	}
+}
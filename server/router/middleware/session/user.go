@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 
 	"github.com/woodpecker-ci/woodpecker/server/model"
 	"github.com/woodpecker-ci/woodpecker/server/store"
@@ -104,6 +105,40 @@ func MustRepoAdmin() gin.HandlerFunc {
 	}
 }
 
+// MustOrgAdmin requires the user to be a system administrator or to
+// hold the admin role for the organization named by the owner parameter.
+func MustOrgAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := User(c)
+		switch {
+		case user == nil:
+			c.String(401, "User not authorized")
+			c.Abort()
+		case user.Admin:
+			c.Next()
+		case isOrgAdmin(c, user, c.Param("owner")):
+			c.Next()
+		default:
+			c.String(403, "User not authorized")
+			c.Abort()
+		}
+	}
+}
+
+func isOrgAdmin(c *gin.Context, user *model.User, owner string) bool {
+	roles, err := store.FromContext(c).RoleList(owner)
+	if err != nil {
+		log.Error().Msgf("Error fetching roles for %s. %s", owner, err)
+		return false
+	}
+	for _, role := range roles {
+		if role.Login == user.Login && role.Name == model.RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
 func MustUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user := User(c)
@@ -0,0 +1,106 @@
+package shared
+
+import (
+	"context"
+	"strings"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+)
+
+// GateStore provides the sender lists and users the approval rules of
+// gated repositories depend on.
+type GateStore interface {
+	SenderFind(*model.Repo, string) (*model.Sender, error)
+	GetUserLogin(string) (*model.User, error)
+}
+
+// BuildGated returns true if the build must be approved before it runs.
+// Builds of the repository owner are never gated. If the repository only
+// gates forks, builds of the repository itself run immediately. Senders on
+// the block list are always gated, senders on the allow list and, if
+// enabled, members of the organization owning the repository are approved
+// automatically.
+func BuildGated(ctx context.Context, r remote.Remote, store GateStore, repo *model.Repo, build *model.Build, owner *model.User) bool {
+	if !repo.IsGated || build.Sender == owner.Login {
+		return false
+	}
+	if repo.GateForks && !IsFork(repo, build) {
+		return false
+	}
+
+	if sender, err := store.SenderFind(repo, build.Sender); err == nil {
+		if sender.Block {
+			return true
+		}
+		if sender.Allow {
+			return false
+		}
+	}
+
+	if repo.ApproveMembers && isMember(ctx, r, store, repo.Owner, build.Sender) {
+		return false
+	}
+	return true
+}
+
+// IsFork returns true if the build is a pull request from a fork of the
+// repository. Pull requests of unknown origin are treated as forks.
+func IsFork(repo *model.Repo, build *model.Build) bool {
+	if build.Event != model.EventPull {
+		return false
+	}
+	if build.Remote == "" {
+		return true
+	}
+	remote := normalizeRemote(build.Remote)
+	return remote != normalizeRemote(repo.Clone) && remote != normalizeRemote(repo.Link)
+}
+
+func normalizeRemote(url string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(url), "/"), ".git")
+}
+
+// isMember returns true if the sender is a member of the organization.
+// Only senders who logged in to Woodpecker can be checked.
+func isMember(ctx context.Context, r remote.Remote, store GateStore, org, sender string) bool {
+	if strings.EqualFold(org, sender) {
+		return true
+	}
+	user, err := store.GetUserLogin(sender)
+	if err != nil {
+		return false
+	}
+	teams, err := r.Teams(ctx, user)
+	if err != nil {
+		return false
+	}
+	for _, team := range teams {
+		if strings.EqualFold(team.Login, org) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanApprove returns true if the user is a member of one of the approval
+// organizations of the repository, or if the repository has no approval
+// organizations. Organizations are resolved with Remote.Teams, which returns
+// the organizations of the user and not the teams within them.
+func CanApprove(ctx context.Context, r remote.Remote, repo *model.Repo, user *model.User) (bool, error) {
+	if len(repo.ApprovalOrgs) == 0 {
+		return true, nil
+	}
+	orgs, err := r.Teams(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	for _, org := range orgs {
+		for _, allowed := range repo.ApprovalOrgs {
+			if strings.EqualFold(org.Login, allowed) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
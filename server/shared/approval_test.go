@@ -0,0 +1,102 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/remote/mocks"
+)
+
+type gateStore struct {
+	senders map[string]*model.Sender
+	users   map[string]*model.User
+}
+
+func (s *gateStore) SenderFind(_ *model.Repo, login string) (*model.Sender, error) {
+	if sender, ok := s.senders[login]; ok {
+		return sender, nil
+	}
+	return nil, fmt.Errorf("sender not found")
+}
+
+func (s *gateStore) GetUserLogin(login string) (*model.User, error) {
+	if user, ok := s.users[login]; ok {
+		return user, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func TestBuildGated(t *testing.T) {
+	store := &gateStore{
+		senders: map[string]*model.Sender{
+			"friend": {Login: "friend", Allow: true},
+			"spam":   {Login: "spam", Block: true},
+		},
+		users: map[string]*model.User{
+			"member": {Login: "member"},
+			"spam":   {Login: "spam"},
+		},
+	}
+	r := new(mocks.Remote)
+	r.On("Teams", mock.Anything, store.users["member"]).Return([]*model.Team{{Login: "octocat"}}, nil)
+	r.On("Teams", mock.Anything, store.users["spam"]).Return([]*model.Team{{Login: "octocat"}}, nil)
+
+	owner := &model.User{Login: "admin"}
+	repo := &model.Repo{Owner: "octocat", Clone: "https://github.com/octocat/hello-world.git", IsGated: true}
+	fork := &model.Build{Event: model.EventPull, Remote: "https://github.com/stranger/hello-world.git"}
+	branch := &model.Build{Event: model.EventPull, Remote: "https://github.com/octocat/hello-world.git"}
+
+	gated := func(sender string, build model.Build) bool {
+		build.Sender = sender
+		return BuildGated(context.Background(), r, store, repo, &build, owner)
+	}
+
+	assert.True(t, gated("stranger", *fork))
+	assert.True(t, gated("stranger", *branch))
+	assert.False(t, gated("admin", *fork))
+	assert.False(t, gated("friend", *fork))
+	assert.True(t, gated("member", *fork))
+
+	repo.ApproveMembers = true
+	assert.False(t, gated("member", *fork))
+	assert.True(t, gated("spam", *fork), "blocked senders are gated")
+
+	repo.GateForks = true
+	assert.False(t, gated("stranger", *branch))
+	assert.True(t, gated("stranger", *fork))
+
+	repo.IsGated = false
+	assert.False(t, gated("spam", *fork))
+}
+
+func TestIsFork(t *testing.T) {
+	repo := &model.Repo{Link: "https://bitbucket.org/octocat/hello-world", Clone: "https://bitbucket.org/octocat/hello-world.git"}
+	assert.False(t, IsFork(repo, &model.Build{Event: model.EventPull, Remote: "https://bitbucket.org/octocat/hello-world"}))
+	assert.True(t, IsFork(repo, &model.Build{Event: model.EventPull, Remote: "https://bitbucket.org/stranger/hello-world"}))
+	assert.False(t, IsFork(repo, &model.Build{Event: model.EventPush, Remote: "https://bitbucket.org/stranger/hello-world"}))
+	assert.True(t, IsFork(repo, &model.Build{Event: model.EventPull}))
+	assert.False(t, IsFork(repo, &model.Build{Event: model.EventPush}))
+}
+
+func TestCanApprove(t *testing.T) {
+	user := &model.User{Login: "octocat"}
+	r := new(mocks.Remote)
+	r.On("Teams", mock.Anything, user).Return([]*model.Team{{Login: "Octo-Org"}}, nil)
+
+	ok, err := CanApprove(context.Background(), r, &model.Repo{}, user)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = CanApprove(context.Background(), r, &model.Repo{ApprovalOrgs: []string{"octo-org"}}, user)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = CanApprove(context.Background(), r, &model.Repo{ApprovalOrgs: []string{"security"}}, user)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
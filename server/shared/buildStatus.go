@@ -24,6 +24,10 @@ type UpdateBuildStore interface {
 	UpdateBuild(*model.Build) error
 }
 
+type ApproveBuildStore interface {
+	UpdateBlockedBuild(*model.Build) (bool, error)
+}
+
 func UpdateToStatusRunning(store UpdateBuildStore, build model.Build, started int64) (*model.Build, error) {
 	build.Status = model.StatusRunning
 	build.Started = started
@@ -37,6 +41,17 @@ func UpdateToStatusPending(store UpdateBuildStore, build model.Build, reviewer s
 	return &build, store.UpdateBuild(&build)
 }
 
+// ApproveBlockedBuild updates the blocked build to pending like
+// UpdateToStatusPending. It returns false if the build is not blocked
+// anymore, concurrent approvals start the build only once.
+func ApproveBlockedBuild(store ApproveBuildStore, build model.Build, reviewer string) (*model.Build, bool, error) {
+	build.Reviewer = reviewer
+	build.Status = model.StatusPending
+	build.Reviewed = time.Now().Unix()
+	updated, err := store.UpdateBlockedBuild(&build)
+	return &build, updated, err
+}
+
 func UpdateToStatusDeclined(store UpdateBuildStore, build model.Build, reviewer string) (*model.Build, error) {
 	build.Reviewer = reviewer
 	build.Status = model.StatusDeclined
@@ -29,6 +29,10 @@ func (m *mockUpdateBuildStore) UpdateBuild(build *model.Build) error {
 	return nil
 }
 
+func (m *mockUpdateBuildStore) UpdateBlockedBuild(build *model.Build) (bool, error) {
+	return true, nil
+}
+
 func TestUpdateToStatusRunning(t *testing.T) {
 	t.Parallel()
 
@@ -57,6 +61,20 @@ func TestUpdateToStatusPending(t *testing.T) {
 	}
 }
 
+func TestApproveBlockedBuild(t *testing.T) {
+	t.Parallel()
+
+	build, started, _ := ApproveBlockedBuild(&mockUpdateBuildStore{}, model.Build{Status: model.StatusBlocked}, "Reviewer")
+
+	if !started {
+		t.Errorf("Build not started")
+	} else if model.StatusPending != build.Status {
+		t.Errorf("Build status not equals '%s' != '%s'", model.StatusPending, build.Status)
+	} else if build.Reviewer != "Reviewer" {
+		t.Errorf("Reviewer not equals 'Reviewer' != '%s'", build.Reviewer)
+	}
+}
+
 func TestUpdateToStatusDeclined(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,25 @@
+package shared
+
+import (
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/starlark"
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+)
+
+// EvalConfigs evaluates the starlark configs with the build metadata in
+// place, their data is replaced by the generated yaml configs. The system
+// name and link are the metadata of the CI system.
+func EvalConfigs(repo *model.Repo, build *model.Build, system, link string, files []*remote.FileMeta) error {
+	metadata := metadataFromStruct(repo, build, &model.Build{}, &model.Proc{}, system, link)
+	for _, file := range files {
+		if !starlark.IsConfig(file.Name) {
+			continue
+		}
+		data, err := starlark.Eval(file.Name, file.Data, metadata)
+		if err != nil {
+			return err
+		}
+		file.Data = data
+	}
+	return nil
+}
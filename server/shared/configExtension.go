@@ -0,0 +1,31 @@
+package shared
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/plugins/config"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+)
+
+// ExtendConfigs passes the fetched configs to the config extension and
+// returns the configs replacing them. The fetched configs are returned
+// if no extension is configured, it fails or it keeps them, the returned
+// bool reports if they were replaced.
+func ExtendConfigs(ctx context.Context, extension config.Extension, repo *model.Repo, build *model.Build, files []*remote.FileMeta) ([]*remote.FileMeta, bool) {
+	if extension == nil {
+		return files, false
+	}
+	configs, err := extension.FetchConfig(ctx, repo, build, files)
+	if err != nil {
+		log.Error().Err(err).Msgf("config extension failed for %s, using the fetched configs", repo.FullName)
+		return files, false
+	}
+	if len(configs) == 0 {
+		return files, false
+	}
+	log.Trace().Msgf("ConfigExtension[%s]: replaced %d configs by %d configs", repo.FullName, len(files), len(configs))
+	return configs, true
+}
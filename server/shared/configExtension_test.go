@@ -0,0 +1,45 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+)
+
+type configExtension struct {
+	configs []*remote.FileMeta
+	err     error
+}
+
+func (e *configExtension) FetchConfig(context.Context, *model.Repo, *model.Build, []*remote.FileMeta) ([]*remote.FileMeta, error) {
+	return e.configs, e.err
+}
+
+func TestExtendConfigs(t *testing.T) {
+	fetched := []*remote.FileMeta{{Name: ".woodpecker.yml"}}
+	generated := []*remote.FileMeta{{Name: "generated.yml"}}
+
+	testdata := []struct {
+		extension *configExtension
+		configs   []*remote.FileMeta
+		extended  bool
+	}{
+		{extension: &configExtension{configs: generated}, configs: generated, extended: true},
+		{extension: &configExtension{}, configs: fetched},
+		{extension: &configExtension{configs: generated, err: errors.New("timeout")}, configs: fetched},
+	}
+	for _, test := range testdata {
+		configs, extended := ExtendConfigs(context.Background(), test.extension, &model.Repo{}, &model.Build{}, fetched)
+		assert.Equal(t, test.configs, configs)
+		assert.Equal(t, test.extended, extended)
+	}
+
+	configs, extended := ExtendConfigs(context.Background(), nil, &model.Repo{}, &model.Build{}, fetched)
+	assert.Equal(t, fetched, configs)
+	assert.False(t, extended)
+}
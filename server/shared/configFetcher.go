@@ -9,6 +9,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/starlark"
 	"github.com/woodpecker-ci/woodpecker/server/model"
 	"github.com/woodpecker-ci/woodpecker/server/remote"
 )
@@ -79,7 +80,7 @@ func (cf *configFetcher) fetch(c context.Context, timeout time.Duration, config
 	}
 
 	log.Trace().Msgf("ConfigFetch[%s]: user did not defined own config follow default procedure", cf.repo.FullName)
-	// no user defined config so try .woodpecker/*.yml -> .woodpecker.yml -> .drone.yml
+	// no user defined config so try .woodpecker/*.yml|*.star -> .woodpecker.yml -> .drone.yml
 
 	// test .woodpecker/ folder
 	// if folder is not supported we will get a "Not implemented" error and continue
@@ -123,7 +124,7 @@ func filterPipelineFiles(files []*remote.FileMeta) []*remote.FileMeta {
 	var res []*remote.FileMeta
 
 	for _, file := range files {
-		if strings.HasSuffix(file.Name, ".yml") {
+		if strings.HasSuffix(file.Name, ".yml") || starlark.IsConfig(file.Name) {
 			res = append(res, file)
 		}
 	}
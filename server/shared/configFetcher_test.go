@@ -114,6 +114,22 @@ func TestFetch(t *testing.T) {
 			},
 			expectedError: false,
 		},
+		{
+			name:       "Default config - Starlark files",
+			repoConfig: "",
+			files: []file{{
+				name: ".woodpecker/build.star",
+				data: dummyData,
+			}, {
+				name: ".woodpecker/test.yml",
+				data: dummyData,
+			}},
+			expectedFileNames: []string{
+				".woodpecker/build.star",
+				".woodpecker/test.yml",
+			},
+			expectedError: false,
+		},
 		{
 			name:       "Default config - Empty Folder",
 			repoConfig: " ",
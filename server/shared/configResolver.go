@@ -0,0 +1,104 @@
+package shared
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/include"
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/remote"
+)
+
+// TemplateFinder finds the global config templates.
+type TemplateFinder interface {
+	TemplateFind(name string) (*model.Template, error)
+}
+
+type configResolver struct {
+	remote_    remote.Remote
+	templates  TemplateFinder
+	user       *model.User
+	repo       *model.Repo
+	build      *model.Build
+	restricted bool
+}
+
+// NewConfigResolver returns a fetcher for the files included by the
+// configs of the build. Files of other repositories are fetched with the
+// permissions of the user. Restricted builds, like pull requests from
+// forks or builds awaiting approval, can only include files of the
+// repository itself.
+func NewConfigResolver(remote remote.Remote, templates TemplateFinder, user *model.User, repo *model.Repo, build *model.Build, restricted bool) include.Fetcher {
+	return &configResolver{
+		remote_:    remote,
+		templates:  templates,
+		user:       user,
+		repo:       repo,
+		build:      build,
+		restricted: restricted,
+	}
+}
+
+// Fetch fetches the included file from the forge or the template store.
+func (cr *configResolver) Fetch(ctx context.Context, source include.Source) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(configFetchTimeout))
+	defer cancel()
+
+	switch {
+	case source.Repo == "" && source.Template == "" || source.Repo == cr.repo.FullName && source.Ref == "":
+		return cr.remote_.File(ctx, cr.user, cr.repo, cr.build, source.Path)
+	case cr.restricted && (source.Template != "" || source.Repo != cr.repo.FullName):
+		return nil, fmt.Errorf("cannot include %s, pull requests from forks and builds awaiting approval can only include files of the repository", source)
+	case source.Template != "":
+		template, err := cr.templates.TemplateFind(source.Template)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("template %s not found", source.Template)
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot load template %s: %w", source.Template, err)
+		}
+		return []byte(template.Data), nil
+	}
+
+	owner, name, err := model.ParseRepo(source.Repo)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := cr.remote_.Repo(ctx, cr.user, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("repository %s not found: %w", source.Repo, err)
+	}
+	// the resolved configs are stored with the build and shown to
+	// everyone who can see the build, files of other private repositories
+	// would be disclosed to them.
+	if repo.IsPrivate && repo.FullName != cr.repo.FullName {
+		return nil, fmt.Errorf("cannot include files of the private repository %s", source.Repo)
+	}
+	perm, err := cr.remote_.Perm(ctx, cr.user, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	if !perm.Pull {
+		return nil, fmt.Errorf("%s has no read access to repository %s", cr.user.Login, source.Repo)
+	}
+
+	ref := source.Ref
+	if ref == "" {
+		ref = repo.Branch
+	}
+	return cr.remote_.File(ctx, cr.user, repo, &model.Build{Commit: ref, Ref: ref, Branch: ref}, source.Path)
+}
+
+// ResolveIncludes resolves the includes of the config files in place.
+func ResolveIncludes(ctx context.Context, fetcher include.Fetcher, files []*remote.FileMeta) error {
+	for _, file := range files {
+		config, err := include.Resolve(ctx, fetcher, file.Name, file.Data)
+		if err != nil {
+			return err
+		}
+		file.Data = config.Data
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package shared
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/include"
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/remote/mocks"
+)
+
+type templateFinder map[string]string
+
+func (f templateFinder) TemplateFind(name string) (*model.Template, error) {
+	if name == "broken" {
+		return nil, fmt.Errorf("database is locked")
+	}
+	data, ok := f[name]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &model.Template{Name: name, Data: data}, nil
+}
+
+func TestConfigResolver(t *testing.T) {
+	user := &model.User{Login: "octocat"}
+	repo := &model.Repo{Owner: "octocat", Name: "hello-world", FullName: "octocat/hello-world"}
+	build := &model.Build{Commit: "a1b2c3"}
+	library := &model.Repo{Owner: "octocat", Name: "shared", FullName: "octocat/shared", Branch: "main"}
+	private := &model.Repo{Owner: "octocat", Name: "secret", FullName: "octocat/secret", Branch: "main", IsPrivate: true}
+
+	r := new(mocks.Remote)
+	r.On("File", mock.Anything, user, repo, build, "common.yml").Return([]byte("common"), nil)
+	r.On("Repo", mock.Anything, user, "octocat", "shared").Return(library, nil)
+	r.On("Repo", mock.Anything, user, "octocat", "secret").Return(private, nil)
+	r.On("Perm", mock.Anything, user, "octocat", "shared").Return(&model.Perm{Pull: true}, nil)
+	r.On("File", mock.Anything, user, library, mock.Anything, "steps.yml").Return([]byte("steps"), nil)
+	templates := templateFinder{"docker": "docker"}
+
+	fetch := func(restricted bool, source include.Source) (string, error) {
+		data, err := NewConfigResolver(r, templates, user, repo, build, restricted).Fetch(context.Background(), source)
+		return string(data), err
+	}
+
+	data, err := fetch(false, include.Source{Path: "common.yml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "common", data)
+	data, err = fetch(false, include.Source{Repo: "octocat/shared", Path: "steps.yml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "steps", data)
+	data, err = fetch(false, include.Source{Template: "docker"})
+	assert.NoError(t, err)
+	assert.Equal(t, "docker", data)
+
+	_, err = fetch(false, include.Source{Template: "missing"})
+	assert.EqualError(t, err, "template missing not found")
+	_, err = fetch(false, include.Source{Template: "broken"})
+	assert.EqualError(t, err, "cannot load template broken: database is locked")
+	_, err = fetch(false, include.Source{Repo: "octocat/secret", Path: "steps.yml"})
+	assert.Error(t, err, "files of other private repositories must not be included")
+
+	// restricted builds only include files of the repository
+	data, err = fetch(true, include.Source{Path: "common.yml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "common", data)
+	_, err = fetch(true, include.Source{Repo: "octocat/shared", Path: "steps.yml"})
+	assert.Error(t, err)
+	_, err = fetch(true, include.Source{Template: "docker"})
+	assert.Error(t, err)
+}
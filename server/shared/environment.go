@@ -0,0 +1,61 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+// EnvironmentLister provides the deployment environments of repositories.
+type EnvironmentLister interface {
+	EnvironmentList(*model.Repo) ([]*model.Environment, error)
+}
+
+// DeploymentBlocked applies the protection rules of the environment the
+// build deploys to. It returns an error if the branch of the build is not
+// allowed to deploy to the environment, and true if the deployment must be
+// approved before it runs. Builds of other events and deployments to
+// environments without rules are not protected. The branch of builds of
+// pull request refs is the base branch, they never satisfy branch rules.
+func DeploymentBlocked(store EnvironmentLister, repo *model.Repo, build *model.Build) (bool, error) {
+	if build.Event != model.EventDeploy {
+		return false, nil
+	}
+	envs, err := store.EnvironmentList(repo)
+	if err != nil {
+		return false, err
+	}
+	for _, env := range envs {
+		if env.Name != build.Deploy {
+			continue
+		}
+		if len(env.Branches) == 0 {
+			return env.RequireApproval, nil
+		}
+		branch, ok := deployBranch(build)
+		if !ok {
+			return false, fmt.Errorf("ref %s is not allowed to deploy to %s", build.Ref, env.Name)
+		}
+		if !env.MatchBranch(branch) {
+			return false, fmt.Errorf("branch %s is not allowed to deploy to %s", branch, env.Name)
+		}
+		return env.RequireApproval, nil
+	}
+	return false, nil
+}
+
+// deployBranch returns the branch the build deploys from. Branch refs name
+// the branch themselves, tag builds and deployments of the forge without a
+// full ref use the branch of the build. Other refs like the refs of pull
+// requests have no branch.
+func deployBranch(build *model.Build) (string, bool) {
+	switch {
+	case strings.HasPrefix(build.Ref, "refs/heads/"):
+		return strings.TrimPrefix(build.Ref, "refs/heads/"), true
+	case strings.HasPrefix(build.Ref, "refs/tags/"),
+		!strings.HasPrefix(build.Ref, "refs/"):
+		return build.Branch, true
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package shared
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+type environmentLister []*model.Environment
+
+func (l environmentLister) EnvironmentList(*model.Repo) ([]*model.Environment, error) {
+	if l == nil {
+		return nil, fmt.Errorf("database error")
+	}
+	return l, nil
+}
+
+func TestDeploymentBlocked(t *testing.T) {
+	store := environmentLister{
+		{Name: "production", Branches: []string{"main", "release/*"}, RequireApproval: true},
+		{Name: "staging"},
+	}
+	repo := &model.Repo{ID: 1}
+
+	testdata := []struct {
+		build   *model.Build
+		blocked bool
+		err     bool
+	}{
+		{build: &model.Build{Event: model.EventDeploy, Deploy: "production", Branch: "main"}, blocked: true},
+		{build: &model.Build{Event: model.EventDeploy, Deploy: "production", Branch: "release/1.0"}, blocked: true},
+		{build: &model.Build{Event: model.EventDeploy, Deploy: "production", Branch: "feature"}, err: true},
+		{build: &model.Build{Event: model.EventDeploy, Deploy: "production", Branch: "main", Ref: "refs/heads/main"}, blocked: true},
+		{build: &model.Build{Event: model.EventDeploy, Deploy: "production", Branch: "main", Ref: "refs/heads/feature"}, err: true},
+		{build: &model.Build{Event: model.EventDeploy, Deploy: "production", Branch: "main", Ref: "refs/tags/v1.0"}, blocked: true},
+		// the branch of pull requests is their base branch
+		{build: &model.Build{Event: model.EventDeploy, Deploy: "production", Branch: "main", Ref: "refs/pull/1/head"}, err: true},
+		{build: &model.Build{Event: model.EventDeploy, Deploy: "staging", Branch: "main", Ref: "refs/pull/1/head"}},
+		{build: &model.Build{Event: model.EventDeploy, Deploy: "staging", Branch: "feature"}},
+		{build: &model.Build{Event: model.EventDeploy, Deploy: "review", Branch: "feature"}},
+		{build: &model.Build{Event: model.EventPush, Branch: "feature"}},
+	}
+	for _, test := range testdata {
+		blocked, err := DeploymentBlocked(store, repo, test.build)
+		assert.Equal(t, test.err, err != nil, test.build.Deploy+" "+test.build.Branch)
+		assert.Equal(t, test.blocked, blocked, test.build.Deploy+" "+test.build.Branch)
+	}
+
+	// the rules cannot be skipped if the environments cannot be loaded
+	_, err := DeploymentBlocked(environmentLister(nil), repo, &model.Build{Event: model.EventDeploy, Deploy: "production"})
+	assert.Error(t, err)
+}
@@ -15,6 +15,8 @@
 package shared
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net/url"
@@ -26,8 +28,10 @@ import (
 
 	"github.com/woodpecker-ci/woodpecker/pipeline/backend"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/starlark"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/compiler"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/include"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/linter"
 	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/matrix"
 	"github.com/woodpecker-ci/woodpecker/server"
@@ -46,6 +50,25 @@ type ProcBuilder struct {
 	Link  string
 	Yamls []*remote.FileMeta
 	Envs  map[string]string
+
+	// Resolver fetches the files included by the yamls, the yamls are
+	// resolved in place. Yamls with includes fail to build without it.
+	Resolver include.Fetcher
+
+	// Pipeline configures the limits and defaults of the pipelines.
+	Pipeline server.PipelineConfig
+
+	// System is the name of the CI system in the metadata of the
+	// pipelines, it defaults to woodpecker.
+	System string
+
+	// JobNumber replaces the pid of the proc as job number in the
+	// metadata of the pipelines if it is not zero.
+	JobNumber int
+
+	// Options returns additional compiler options for the proc, they are
+	// applied after the options of the pipeline config.
+	Options func(proc *model.Proc, parsed *yaml.Config) ([]compiler.Option, error)
 }
 
 type BuildItem struct {
@@ -65,8 +88,15 @@ func (b *ProcBuilder) Build() ([]*BuildItem, error) {
 	pidSequence := 1
 
 	for _, y := range b.Yamls {
+		// resolve includes and extended steps
+		config, err := include.Resolve(context.Background(), b.Resolver, y.Name, y.Data)
+		if err != nil {
+			return nil, err
+		}
+		y.Data = config.Data
+
 		// matrix axes
-		axes, err := matrix.ParseString(string(y.Data))
+		axes, err := matrix.ParseWithLimits(y.Data, b.Pipeline.MatrixLimits)
 		if err != nil {
 			return nil, err
 		}
@@ -84,7 +114,10 @@ func (b *ProcBuilder) Build() ([]*BuildItem, error) {
 				Name:    SanitizePath(y.Name),
 			}
 
-			metadata := metadataFromStruct(b.Repo, b.Curr, b.Last, proc, b.Link)
+			metadata := metadataFromStruct(b.Repo, b.Curr, b.Last, proc, b.System, b.Link)
+			if b.JobNumber != 0 {
+				metadata.Job.Number = b.JobNumber
+			}
 			environ := b.environmentVariables(metadata, axis)
 
 			// substitute vars
@@ -104,16 +137,24 @@ func (b *ProcBuilder) Build() ([]*BuildItem, error) {
 				linter.WithTrusted(b.Repo.IsTrusted),
 			).Lint(parsed)
 			if lerr != nil {
-				return nil, lerr
+				return nil, includeError(lerr, config)
 			}
 
-			if !parsed.Branches.Match(b.Curr.Branch) {
+			metadata.SetPlatform(parsed.Platform)
+
+			if !parsed.Branches.Match(b.Curr.Branch) || !parsed.When.Match(metadata) {
 				proc.State = model.StatusSkipped
 			}
 
-			metadata.SetPlatform(parsed.Platform)
+			// promotions only run the steps of the target environment
+			if b.Curr.Promoted && !promote(parsed, b.Curr.Deploy) {
+				proc.State = model.StatusSkipped
+			}
 
-			ir := b.toInternalRepresentation(parsed, environ, metadata, proc.ID)
+			ir, err := b.toInternalRepresentation(parsed, environ, metadata, proc)
+			if err != nil {
+				return nil, err
+			}
 
 			if len(ir.Stages) == 0 {
 				continue
@@ -141,6 +182,17 @@ func (b *ProcBuilder) Build() ([]*BuildItem, error) {
 	return items, nil
 }
 
+// includeError adds the include chain of the invalid step to the lint error.
+func includeError(err error, config *include.Config) error {
+	var lerr *linter.Error
+	if errors.As(err, &lerr) {
+		if origin := config.Origin(lerr.Step); origin != "" {
+			return fmt.Errorf("%w (step %s included from %s)", err, lerr.Step, origin)
+		}
+	}
+	return err
+}
+
 func filterItemsWithMissingDependencies(items []*BuildItem) []*BuildItem {
 	itemsToRemove := make([]*BuildItem, 0)
 
@@ -196,10 +248,10 @@ func (b *ProcBuilder) environmentVariables(metadata frontend.Metadata, axis matr
 	return environ
 }
 
-func (b *ProcBuilder) toInternalRepresentation(parsed *yaml.Config, environ map[string]string, metadata frontend.Metadata, procID int64) *backend.Config {
+func (b *ProcBuilder) toInternalRepresentation(parsed *yaml.Config, environ map[string]string, metadata frontend.Metadata, proc *model.Proc) (*backend.Config, error) {
 	var secrets []compiler.Secret
 	for _, sec := range b.Secs {
-		if !sec.Match(b.Curr.Event) {
+		if !sec.Match(b.Curr.Event) || !sec.MatchEnvironment(b.Curr.Event, b.Curr.Deploy) {
 			continue
 		}
 		secrets = append(secrets, compiler.Secret{
@@ -219,13 +271,14 @@ func (b *ProcBuilder) toInternalRepresentation(parsed *yaml.Config, environ map[
 		})
 	}
 
-	return compiler.New(
+	options := []compiler.Option{
 		compiler.WithEnviron(environ),
 		compiler.WithEnviron(b.Envs),
-		compiler.WithEscalated(server.Config.Pipeline.Privileged...),
-		compiler.WithResourceLimit(server.Config.Pipeline.Limits.MemSwapLimit, server.Config.Pipeline.Limits.MemLimit, server.Config.Pipeline.Limits.ShmSize, server.Config.Pipeline.Limits.CPUQuota, server.Config.Pipeline.Limits.CPUShares, server.Config.Pipeline.Limits.CPUSet),
-		compiler.WithVolumes(server.Config.Pipeline.Volumes...),
-		compiler.WithNetworks(server.Config.Pipeline.Networks...),
+		compiler.WithEscalated(b.Pipeline.Privileged...),
+		compiler.WithResourceLimit(b.Pipeline.Limits.MemSwapLimit, b.Pipeline.Limits.MemLimit, b.Pipeline.Limits.ShmSize, b.Pipeline.Limits.CPUQuota, b.Pipeline.Limits.CPUShares, b.Pipeline.Limits.CPUSet),
+		compiler.WithVolumes(b.Pipeline.Volumes...),
+		compiler.WithNetworks(b.Pipeline.Networks...),
+		compiler.WithDefaultClone(b.Pipeline.DefaultCloneImage, b.Pipeline.DefaultCloneSettings),
 		compiler.WithLocal(false),
 		compiler.WithOption(
 			compiler.WithNetrc(
@@ -240,14 +293,22 @@ func (b *ProcBuilder) toInternalRepresentation(parsed *yaml.Config, environ map[
 		compiler.WithPrefix(
 			fmt.Sprintf(
 				"%d_%d",
-				procID,
+				proc.ID,
 				rand.Int(),
 			),
 		),
 		compiler.WithProxy(),
 		compiler.WithWorkspaceFromURL("/woodpecker", b.Repo.Link),
 		compiler.WithMetadata(metadata),
-	).Compile(parsed)
+	}
+	if b.Options != nil {
+		extra, err := b.Options(proc, parsed)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, extra...)
+	}
+	return compiler.New(options...).Compile(parsed), nil
 }
 
 func SetBuildStepsOnBuild(build *model.Build, buildItems []*BuildItem) *model.Build {
@@ -286,8 +347,15 @@ func SetBuildStepsOnBuild(build *model.Build, buildItems []*BuildItem) *model.Bu
 	return build
 }
 
+// DefaultSystem is the name of the CI system in the metadata of the
+// pipelines.
+const DefaultSystem = "woodpecker"
+
 // return the metadata from the cli context.
-func metadataFromStruct(repo *model.Repo, build, last *model.Build, proc *model.Proc, link string) frontend.Metadata {
+func metadataFromStruct(repo *model.Repo, build, last *model.Build, proc *model.Proc, system, link string) frontend.Metadata {
+	if system == "" {
+		system = DefaultSystem
+	}
 	host := link
 	uri, err := url.Parse(link)
 	if err == nil {
@@ -353,7 +421,7 @@ func metadataFromStruct(repo *model.Repo, build, last *model.Build, proc *model.
 			Matrix: proc.Environ,
 		},
 		Sys: frontend.System{
-			Name: "woodpecker",
+			Name: system,
 			Link: link,
 			Host: host,
 			Arch: "linux/amd64",
@@ -364,6 +432,7 @@ func metadataFromStruct(repo *model.Repo, build, last *model.Build, proc *model.
 func SanitizePath(path string) string {
 	path = filepath.Base(path)
 	path = strings.TrimSuffix(path, ".yml")
+	path = strings.TrimSuffix(path, starlark.Extension)
 	path = strings.TrimPrefix(path, ".")
 	return path
 }
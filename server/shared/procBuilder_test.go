@@ -15,9 +15,16 @@
 package shared
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/compiler"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/include"
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml/matrix"
+	"github.com/woodpecker-ci/woodpecker/server"
 	"github.com/woodpecker-ci/woodpecker/server/model"
 	"github.com/woodpecker-ci/woodpecker/server/remote"
 )
@@ -255,6 +262,162 @@ pipeline:
 	}
 }
 
+func TestPlatformFilter(t *testing.T) {
+	t.Parallel()
+
+	b := ProcBuilder{
+		Repo:  &model.Repo{},
+		Curr:  &model.Build{Branch: "dev"},
+		Last:  &model.Build{},
+		Netrc: &model.Netrc{},
+		Secs:  []*model.Secret{},
+		Regs:  []*model.Registry{},
+		Link:  "",
+		Yamls: []*remote.FileMeta{
+			{Data: []byte(`
+platform: linux/arm64
+when:
+  platform: linux/arm*
+pipeline:
+  build:
+    image: scratch
+`)},
+			{Data: []byte(`
+when:
+  platform: linux/arm*
+pipeline:
+  build:
+    image: scratch
+`)},
+		},
+	}
+
+	buildItems, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buildItems) != 2 {
+		t.Fatal("Should have generated 2 buildItems")
+	}
+	if buildItems[0].Proc.State != model.StatusPending {
+		t.Fatal("Should run on the platform of the pipeline")
+	}
+	if buildItems[1].Proc.State != model.StatusSkipped {
+		t.Fatal("Should not run on the default platform")
+	}
+}
+
+func TestPromotion(t *testing.T) {
+	t.Parallel()
+
+	b := ProcBuilder{
+		Repo:  &model.Repo{},
+		Curr:  &model.Build{Event: model.EventDeploy, Deploy: "production", Promoted: true},
+		Last:  &model.Build{},
+		Netrc: &model.Netrc{},
+		Secs:  []*model.Secret{},
+		Regs:  []*model.Registry{},
+		Link:  "",
+		Yamls: []*remote.FileMeta{
+			{Name: "build", Data: []byte(`
+pipeline:
+  test:
+    image: scratch
+  publish:
+    image: scratch
+    when:
+      event: deployment
+  deploy:
+    image: scratch
+    when:
+      environment: production
+  staging:
+    image: scratch
+    when:
+      environment: staging
+`)},
+			{Name: "release", Data: []byte(`
+pipeline:
+  release:
+    image: scratch
+when:
+  environment: [ staging, production ]
+`)},
+			{Name: "test", Data: []byte(`
+pipeline:
+  test:
+    image: scratch
+`)},
+		},
+	}
+
+	buildItems, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buildItems) != 3 {
+		t.Fatal("Should have generated 3 buildItems")
+	}
+
+	var steps []string
+	for _, stage := range buildItems[0].Config.Stages {
+		for _, step := range stage.Steps {
+			steps = append(steps, step.Alias)
+		}
+	}
+	if strings.Join(steps, ",") != "clone,deploy" {
+		t.Fatalf("Should only run the steps of the environment, got %v", steps)
+	}
+	if buildItems[1].Proc.State != model.StatusPending {
+		t.Fatal("Should run the workflow of the environment")
+	}
+	if buildItems[2].Proc.State != model.StatusSkipped {
+		t.Fatal("Should skip workflows without steps of the environment")
+	}
+}
+
+func TestMatrixFilter(t *testing.T) {
+	t.Parallel()
+
+	b := ProcBuilder{
+		Repo:  &model.Repo{},
+		Curr:  &model.Build{},
+		Last:  &model.Build{},
+		Netrc: &model.Netrc{},
+		Secs:  []*model.Secret{},
+		Regs:  []*model.Registry{},
+		Link:  "",
+		Yamls: []*remote.FileMeta{
+			{Data: []byte(`
+pipeline:
+  build:
+    image: golang:${GO_VERSION}
+matrix:
+  GO_VERSION:
+    - 1.16
+    - 1.17
+when:
+  matrix:
+    GO_VERSION: 1.17
+`)},
+		},
+	}
+
+	buildItems, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buildItems) != 2 {
+		t.Fatal("Should have generated 2 buildItems")
+	}
+	if buildItems[0].Proc.State != model.StatusSkipped {
+		t.Fatal("Should not run with GO_VERSION 1.16")
+	}
+	if buildItems[1].Proc.State != model.StatusPending {
+		t.Fatal("Should run with GO_VERSION 1.17")
+	}
+}
+
 func TestZeroSteps(t *testing.T) {
 	t.Parallel()
 
@@ -456,3 +619,169 @@ func TestSanitizePath(t *testing.T) {
 	}
 
 }
+
+type includeFetcher map[string]string
+
+func (f includeFetcher) Fetch(_ context.Context, source include.Source) ([]byte, error) {
+	return []byte(f[source.String()]), nil
+}
+
+func TestIncludes(t *testing.T) {
+	t.Parallel()
+
+	b := ProcBuilder{
+		Repo:  &model.Repo{},
+		Curr:  &model.Build{},
+		Last:  &model.Build{},
+		Netrc: &model.Netrc{},
+		Secs:  []*model.Secret{},
+		Regs:  []*model.Registry{},
+		Link:  "",
+		Yamls: []*remote.FileMeta{
+			{Name: ".woodpecker.yml", Data: []byte(`
+include: [ common.yml ]
+pipeline:
+  build:
+    extends: .build
+    commands: [ make ]
+`)},
+		},
+		Resolver: includeFetcher{
+			"common.yml": `
+pipeline:
+  .build:
+    image: golang
+  test:
+    image: golang
+`,
+		},
+	}
+
+	buildItems, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buildItems[0].Config.Stages) != 3 {
+		t.Fatal("Should run the included and the extended step")
+	}
+}
+
+func TestIncludesLintError(t *testing.T) {
+	t.Parallel()
+
+	b := ProcBuilder{
+		Repo:  &model.Repo{},
+		Curr:  &model.Build{},
+		Last:  &model.Build{},
+		Netrc: &model.Netrc{},
+		Secs:  []*model.Secret{},
+		Regs:  []*model.Registry{},
+		Link:  "",
+		Yamls: []*remote.FileMeta{
+			{Name: ".woodpecker.yml", Data: []byte(`
+include: [ common.yml ]
+pipeline:
+  build:
+    image: golang
+`)},
+		},
+		Resolver: includeFetcher{
+			"common.yml": `
+pipeline:
+  test:
+    image: golang
+    privileged: true
+`,
+		},
+	}
+
+	_, err := b.Build()
+	if err == nil || !strings.HasSuffix(err.Error(), "(step test included from .woodpecker.yml -> common.yml)") {
+		t.Fatalf("Should report the include chain, got %v", err)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	t.Parallel()
+
+	b := ProcBuilder{
+		Repo:  &model.Repo{},
+		Curr:  &model.Build{},
+		Last:  &model.Build{},
+		Netrc: &model.Netrc{},
+		Secs:  []*model.Secret{},
+		Regs:  []*model.Registry{},
+		Link:  "",
+		Yamls: []*remote.FileMeta{
+			{Data: []byte(`
+pipeline:
+  build:
+    image: scratch
+`)},
+		},
+		Options: func(proc *model.Proc, parsed *yaml.Config) ([]compiler.Option, error) {
+			return []compiler.Option{compiler.WithPrefix(fmt.Sprintf("local_%d", proc.PID))}, nil
+		},
+	}
+
+	buildItems, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step := buildItems[0].Config.Stages[0].Steps[0]; !strings.HasPrefix(step.Name, "local_1_") {
+		t.Fatalf("Should apply the options after the server options, got %s", step.Name)
+	}
+
+	b.Options = func(*model.Proc, *yaml.Config) ([]compiler.Option, error) {
+		return nil, fmt.Errorf("secret missing")
+	}
+	if _, err := b.Build(); err == nil || err.Error() != "secret missing" {
+		t.Fatalf("Should return the error of the options, got %v", err)
+	}
+}
+
+func TestPipelineConfig(t *testing.T) {
+	t.Parallel()
+
+	b := ProcBuilder{
+		Repo:  &model.Repo{},
+		Curr:  &model.Build{},
+		Last:  &model.Build{},
+		Netrc: &model.Netrc{},
+		Secs:  []*model.Secret{},
+		Regs:  []*model.Registry{},
+		Link:  "",
+		Yamls: []*remote.FileMeta{
+			{Data: []byte(`
+pipeline:
+  build:
+    image: scratch
+matrix:
+  GO_VERSION: [ 1.16, 1.17 ]
+`)},
+		},
+		Pipeline: server.PipelineConfig{
+			Volumes:      []string{"/tmp/cache:/cache"},
+			MatrixLimits: matrix.Limits{Tags: 1, Axis: 1},
+		},
+		System:    "pipec",
+		JobNumber: 7,
+	}
+
+	if _, err := b.Build(); err == nil {
+		t.Fatal("Should apply the matrix limits of the pipeline config")
+	}
+
+	b.Pipeline.MatrixLimits = matrix.DefaultLimits
+	buildItems, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := buildItems[0].Config.Stages[1].Steps[0]
+	if len(step.Volumes) != 2 || step.Volumes[1] != "/tmp/cache:/cache" {
+		t.Fatalf("Should apply the volumes of the pipeline config, got %v", step.Volumes)
+	}
+	if step.Environment["CI_SYSTEM_NAME"] != "pipec" || step.Environment["CI_JOB_NUMBER"] != "7" {
+		t.Fatalf("Should set the system name and job number, got %s and %s", step.Environment["CI_SYSTEM_NAME"], step.Environment["CI_JOB_NUMBER"])
+	}
+}
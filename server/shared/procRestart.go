@@ -0,0 +1,93 @@
+package shared
+
+import (
+	"fmt"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+// FailedWorkflows returns the pids of the failed workflows of the build.
+func FailedWorkflows(procs []*model.Proc) []int {
+	var pids []int
+	for _, proc := range procs {
+		if proc.PPID == 0 && proc.Failing() {
+			pids = append(pids, proc.PID)
+		}
+	}
+	return pids
+}
+
+// RestartWorkflows prepares the restart of the workflows with the given
+// pids and of the workflows depending on them within their build. The
+// build items, rebuilt from the configs of the build, are mapped to the
+// existing workflow procs by pid. The procs of the restarted workflows
+// are reset to pending and returned, the other procs keep their status.
+func RestartWorkflows(items []*BuildItem, procs []*model.Proc, pids []int) ([]*model.Proc, error) {
+	workflows := map[int]*model.Proc{}
+	for _, proc := range procs {
+		if proc.PPID == 0 {
+			workflows[proc.PID] = proc
+		}
+	}
+
+	// workflows skipped when the build was created are never restarted.
+	restartable := map[string]bool{}
+	for _, item := range items {
+		workflow, ok := workflows[item.Proc.PID]
+		if !ok || workflow.Name != item.Proc.Name {
+			return nil, fmt.Errorf("workflow %s changed since the build was created", item.Proc.Name)
+		}
+		restartable[workflow.Name] = item.Proc.State != model.StatusSkipped
+		item.Proc = workflow
+	}
+
+	restart := map[string]bool{}
+	for _, pid := range pids {
+		workflow, ok := workflows[pid]
+		if !ok {
+			return nil, fmt.Errorf("workflow %d not found", pid)
+		}
+		if !restartable[workflow.Name] {
+			return nil, fmt.Errorf("workflow %s can not be restarted", workflow.Name)
+		}
+		restart[workflow.Name] = true
+	}
+
+	// restart the workflows depending on restarted workflows.
+	for changed := true; changed; {
+		changed = false
+		for _, item := range items {
+			if restart[item.Proc.Name] || !restartable[item.Proc.Name] {
+				continue
+			}
+			for _, dep := range item.DependsOn {
+				if restart[dep] {
+					restart[item.Proc.Name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var reset []*model.Proc
+	for _, proc := range procs {
+		workflow := proc
+		if proc.PPID != 0 {
+			workflow = workflows[proc.PPID]
+		}
+		if workflow == nil || !restart[workflow.Name] {
+			continue
+		}
+		proc.State = model.StatusPending
+		proc.Error = ""
+		proc.ExitCode = 0
+		proc.Started = 0
+		proc.Stopped = 0
+		proc.PullStarted = 0
+		proc.PullFinished = 0
+		proc.Machine = ""
+		reset = append(reset, proc)
+	}
+	return reset, nil
+}
@@ -0,0 +1,74 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func restartProcs() []*model.Proc {
+	return []*model.Proc{
+		{ID: 1, PID: 1, Name: "lint", State: model.StatusSuccess},
+		{ID: 2, PID: 2, Name: "test", State: model.StatusFailure, ExitCode: 1},
+		{ID: 3, PID: 3, Name: "deploy", State: model.StatusSkipped},
+		{ID: 4, PID: 4, Name: "docs", State: model.StatusSkipped},
+		{ID: 5, PID: 5, PPID: 1, Name: "lint", State: model.StatusSuccess},
+		{ID: 6, PID: 6, PPID: 2, Name: "test", State: model.StatusFailure, ExitCode: 1},
+		{ID: 7, PID: 7, PPID: 3, Name: "deploy", State: model.StatusSkipped},
+	}
+}
+
+func restartItems() []*BuildItem {
+	return []*BuildItem{
+		{Proc: &model.Proc{PID: 1, Name: "lint", State: model.StatusPending}},
+		{Proc: &model.Proc{PID: 2, Name: "test", State: model.StatusPending}},
+		{Proc: &model.Proc{PID: 3, Name: "deploy", State: model.StatusPending}, DependsOn: []string{"test"}},
+		{Proc: &model.Proc{PID: 4, Name: "docs", State: model.StatusSkipped}, DependsOn: []string{"test"}},
+	}
+}
+
+func TestFailedWorkflows(t *testing.T) {
+	assert.Equal(t, []int{2}, FailedWorkflows(restartProcs()))
+}
+
+func TestRestartWorkflows(t *testing.T) {
+	procs := restartProcs()
+	items := restartItems()
+
+	reset, err := RestartWorkflows(items, procs, []int{2})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var ids []int64
+	for _, proc := range reset {
+		ids = append(ids, proc.ID)
+		assert.Equal(t, model.StatusPending, proc.State)
+		assert.Equal(t, 0, proc.ExitCode)
+	}
+	// the dependent deploy workflow is restarted, the docs workflow
+	// skipped when the build was created is not.
+	assert.Equal(t, []int64{2, 3, 6, 7}, ids)
+	assert.Equal(t, model.StatusSuccess, procs[0].State)
+	assert.Equal(t, model.StatusSkipped, procs[3].State)
+
+	// the items are mapped to the existing procs.
+	for i, item := range items {
+		assert.Equal(t, procs[i], item.Proc)
+	}
+}
+
+func TestRestartWorkflowsErr(t *testing.T) {
+	_, err := RestartWorkflows(restartItems(), restartProcs(), []int{5})
+	assert.EqualError(t, err, "workflow 5 not found")
+
+	_, err = RestartWorkflows(restartItems(), restartProcs(), []int{4})
+	assert.EqualError(t, err, "workflow docs can not be restarted")
+
+	items := restartItems()
+	items[1].Proc.Name = "unit"
+	_, err = RestartWorkflows(items, restartProcs(), []int{2})
+	assert.EqualError(t, err, "workflow unit changed since the build was created")
+}
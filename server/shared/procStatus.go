@@ -30,6 +30,8 @@ func UpdateProcStatus(store UpdateProcStore, proc model.Proc, state rpc.State, s
 		proc.Stopped = state.Finished
 		proc.ExitCode = state.ExitCode
 		proc.Error = state.Error
+		proc.PullStarted = state.PullStarted
+		proc.PullFinished = state.PullFinished
 		proc.State = model.StatusSuccess
 		if state.ExitCode != 0 || state.Error != "" {
 			proc.State = model.StatusFailure
@@ -40,6 +42,11 @@ func UpdateProcStatus(store UpdateProcStore, proc model.Proc, state rpc.State, s
 	} else {
 		proc.Started = state.Started
 		proc.State = model.StatusRunning
+		// detached steps report the pull timing while running
+		if state.PullStarted != 0 {
+			proc.PullStarted = state.PullStarted
+			proc.PullFinished = state.PullFinished
+		}
 	}
 
 	if proc.Started == 0 && proc.Stopped != 0 {
@@ -107,6 +107,46 @@ func TestUpdateProcStatusExited(t *testing.T) {
 	}
 }
 
+func TestUpdateProcStatusExitedWithPull(t *testing.T) {
+	t.Parallel()
+
+	state := rpc.State{
+		Started:      int64(42),
+		Exited:       true,
+		Finished:     int64(50),
+		PullStarted:  int64(42),
+		PullFinished: int64(45),
+	}
+	proc, _ := UpdateProcStatus(&mockUpdateProcStore{}, model.Proc{Started: int64(42)}, state, int64(42))
+
+	if proc.State != model.StatusSuccess {
+		t.Errorf("Proc status not equals '%s' != '%s'", model.StatusSuccess, proc.State)
+	} else if proc.PullStarted != int64(42) {
+		t.Errorf("Proc pull started not equals 42 != %d", proc.PullStarted)
+	} else if proc.PullFinished != int64(45) {
+		t.Errorf("Proc pull finished not equals 45 != %d", proc.PullFinished)
+	}
+}
+
+func TestUpdateProcStatusNotExitedWithPull(t *testing.T) {
+	t.Parallel()
+
+	state := rpc.State{
+		Started:      int64(46),
+		PullStarted:  int64(42),
+		PullFinished: int64(45),
+	}
+	proc, _ := UpdateProcStatus(&mockUpdateProcStore{}, model.Proc{Started: int64(42)}, state, int64(42))
+
+	if proc.State != model.StatusRunning {
+		t.Errorf("Proc status not equals '%s' != '%s'", model.StatusRunning, proc.State)
+	} else if proc.PullStarted != int64(42) {
+		t.Errorf("Proc pull started not equals 42 != %d", proc.PullStarted)
+	} else if proc.PullFinished != int64(45) {
+		t.Errorf("Proc pull finished not equals 45 != %d", proc.PullFinished)
+	}
+}
+
 func TestUpdateProcStatusExitedButNot137(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,30 @@
+package shared
+
+import (
+	"github.com/woodpecker-ci/woodpecker/pipeline/frontend/yaml"
+)
+
+// promote reduces the workflow to the steps of a promotion to the target
+// environment. A workflow whose when.environment includes the target runs
+// all steps, otherwise only the steps whose when.environment includes the
+// target run. Services are kept. It returns false if no step runs.
+func promote(parsed *yaml.Config, target string) bool {
+	if environmentIncludes(parsed.When.Environment, target) {
+		return true
+	}
+
+	var steps []*yaml.Container
+	for _, step := range parsed.Pipeline.Containers {
+		if environmentIncludes(step.Constraints.Environment, target) {
+			steps = append(steps, step)
+		}
+	}
+	parsed.Pipeline.Containers = steps
+	return len(steps) != 0
+}
+
+// environmentIncludes returns true if the environment constraint names
+// the target explicitly, constraints without includes match every target.
+func environmentIncludes(constraint yaml.Constraint, target string) bool {
+	return len(constraint.Include) != 0 && constraint.Match(target)
+}
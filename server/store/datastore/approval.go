@@ -0,0 +1,33 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func (s storage) ApprovalCreate(approval *model.Approval) error {
+	// only Insert set auto created ID back to object
+	_, err := s.engine.Insert(approval)
+	return err
+}
+
+func (s storage) ApprovalList(build *model.Build) ([]*model.Approval, error) {
+	approvals := make([]*model.Approval, 0, perPage)
+	return approvals, s.engine.
+		Where("approval_build_id = ?", build.ID).
+		Asc("approval_id").
+		Find(&approvals)
+}
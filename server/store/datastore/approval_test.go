@@ -0,0 +1,42 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func TestApprovalList(t *testing.T) {
+	store, closer := newTestStore(t, new(model.Approval))
+	defer closer()
+
+	assert.NoError(t, store.ApprovalCreate(&model.Approval{BuildID: 1, Login: "octocat", Created: 1}))
+	assert.NoError(t, store.ApprovalCreate(&model.Approval{BuildID: 1, Login: "defunkt", Created: 2}))
+	assert.NoError(t, store.ApprovalCreate(&model.Approval{BuildID: 2, Login: "octocat", Created: 3}))
+
+	// a user approves a build once
+	assert.Error(t, store.ApprovalCreate(&model.Approval{BuildID: 1, Login: "octocat"}))
+
+	approvals, err := store.ApprovalList(&model.Build{ID: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, approvals, 2) {
+		assert.Equal(t, "octocat", approvals[0].Login)
+		assert.Equal(t, "defunkt", approvals[1].Login)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func (s storage) AuditCreate(event *model.AuditEvent) error {
+	// only Insert set auto created ID back to object
+	_, err := s.engine.Insert(event)
+	return err
+}
+
+func (s storage) AuditList(filter *model.AuditFilter) ([]*model.AuditEvent, error) {
+	events := make([]*model.AuditEvent, 0, perPage)
+	sess := s.engine.Desc("audit_id")
+	if filter.Actor != "" {
+		sess = sess.And("audit_actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		sess = sess.And("audit_action = ?", filter.Action)
+	}
+	if filter.Repo != "" {
+		sess = sess.And("audit_repo = ?", filter.Repo)
+	}
+	if filter.Before != 0 {
+		sess = sess.And("audit_created < ?", filter.Before)
+	}
+	if filter.After != 0 {
+		sess = sess.And("audit_created > ?", filter.After)
+	}
+	if filter.Limit > 0 {
+		sess = sess.Limit(filter.Limit)
+	}
+	return events, sess.Find(&events)
+}
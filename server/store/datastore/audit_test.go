@@ -0,0 +1,73 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func TestAuditList(t *testing.T) {
+	store, closer := newTestStore(t, new(model.AuditEvent))
+	defer closer()
+
+	assert.NoError(t, store.AuditCreate(&model.AuditEvent{
+		Created: 1,
+		Actor:   "octocat",
+		Action:  model.AuditSecretCreate,
+		Repo:    "octocat/hello-world",
+		Target:  "password",
+	}))
+	assert.NoError(t, store.AuditCreate(&model.AuditEvent{
+		Created: 2,
+		Actor:   "octocat",
+		Action:  model.AuditRepoUpdate,
+		Repo:    "octocat/hello-world",
+		Data:    map[string]string{"trusted": "true"},
+	}))
+	assert.NoError(t, store.AuditCreate(&model.AuditEvent{
+		Created: 3,
+		Actor:   "defunkt",
+		Action:  model.AuditLogLevel,
+		Target:  "debug",
+	}))
+
+	events, err := store.AuditList(&model.AuditFilter{})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 3) {
+		// newest first
+		assert.Equal(t, model.AuditLogLevel, events[0].Action)
+		assert.Equal(t, "true", events[1].Data["trusted"])
+	}
+
+	events, err = store.AuditList(&model.AuditFilter{Actor: "octocat", After: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, model.AuditRepoUpdate, events[0].Action)
+	}
+
+	events, err = store.AuditList(&model.AuditFilter{Repo: "octocat/hello-world", Before: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "password", events[0].Target)
+	}
+
+	events, err = store.AuditList(&model.AuditFilter{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+}
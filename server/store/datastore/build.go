@@ -51,14 +51,30 @@ func (s storage) GetBuildCommit(repo *model.Repo, sha, branch string) (*model.Bu
 }
 
 func (s storage) GetBuildLast(repo *model.Repo, branch string) (*model.Build, error) {
+	return s.GetBuildLastEvent(repo, branch, model.EventPush)
+}
+
+func (s storage) GetBuildLastEvent(repo *model.Repo, branch, event string) (*model.Build, error) {
 	build := &model.Build{
 		RepoID: repo.ID,
 		Branch: branch,
-		Event:  "push",
+		Event:  event,
 	}
 	return build, wrapGet(s.engine.Desc("build_number").Get(build))
 }
 
+func (s storage) GetBuildLastEventBefore(repo *model.Repo, branch, event string, num int64) (*model.Build, error) {
+	build := &model.Build{
+		RepoID: repo.ID,
+		Branch: branch,
+		Event:  event,
+	}
+	return build, wrapGet(s.engine.
+		Desc("build_number").
+		Where("build_number < ?", num).
+		Get(build))
+}
+
 func (s storage) GetBuildLastBefore(repo *model.Repo, branch string, num int64) (*model.Build, error) {
 	build := &model.Build{
 		RepoID: repo.ID,
@@ -78,6 +94,26 @@ func (s storage) GetBuildList(repo *model.Repo, page int) ([]*model.Build, error
 		Find(&builds)
 }
 
+func (s storage) GetDeploymentList(repo *model.Repo, env string, page int) ([]*model.Build, error) {
+	builds := make([]*model.Build, 0, perPage)
+	return builds, s.engine.Where("build_repo_id = ?", repo.ID).
+		And("build_event = ?", model.EventDeploy).
+		And("build_deploy = ?", env).
+		Desc("build_number").
+		Limit(perPage, perPage*(page-1)).
+		Find(&builds)
+}
+
+func (s storage) GetDeploymentLast(repo *model.Repo, env string) (*model.Build, error) {
+	build := &model.Build{
+		RepoID: repo.ID,
+		Event:  model.EventDeploy,
+		Deploy: env,
+		Status: model.StatusSuccess,
+	}
+	return build, wrapGet(s.engine.Desc("build_number").Get(build))
+}
+
 func (s storage) GetBuildCount() (int64, error) {
 	return s.engine.Count(new(model.Build))
 }
@@ -121,3 +157,11 @@ func (s storage) UpdateBuild(build *model.Build) error {
 	_, err := s.engine.ID(build.ID).AllCols().Update(build)
 	return err
 }
+
+func (s storage) UpdateBlockedBuild(build *model.Build) (bool, error) {
+	updated, err := s.engine.ID(build.ID).
+		Where("build_status = ?", model.StatusBlocked).
+		AllCols().
+		Update(build)
+	return updated == 1, err
+}
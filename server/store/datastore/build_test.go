@@ -228,6 +228,93 @@ func TestBuilds(t *testing.T) {
 			g.Assert(build2.Commit).Equal(getbuild.Commit)
 		})
 
+		g.It("Should Get the last Build of the Event", func() {
+			build1 := &model.Build{
+				RepoID: repo.ID,
+				Status: model.StatusFailure,
+				Branch: "master",
+				Commit: "85f8c029b902ed9400bc600bac301a0aadb144ac",
+				Event:  model.EventTag,
+			}
+			build2 := &model.Build{
+				RepoID: repo.ID,
+				Status: model.StatusSuccess,
+				Branch: "master",
+				Commit: "85f8c029b902ed9400bc600bac301a0aadb144aa",
+				Event:  model.EventPush,
+			}
+			err1 := store.CreateBuild(build1, []*model.Proc{}...)
+			err2 := store.CreateBuild(build2, []*model.Proc{}...)
+			getbuild, err3 := store.GetBuildLastEvent(&model.Repo{ID: 1}, "", model.EventTag)
+			g.Assert(err1).IsNil()
+			g.Assert(err2).IsNil()
+			g.Assert(err3).IsNil()
+			g.Assert(build1.ID).Equal(getbuild.ID)
+			g.Assert(build1.Event).Equal(getbuild.Event)
+		})
+
+		g.It("Should Get the last Build of the Event Before Build N", func() {
+			build1 := &model.Build{
+				RepoID: repo.ID,
+				Branch: "master",
+				Event:  model.EventPush,
+			}
+			build2 := &model.Build{
+				RepoID: repo.ID,
+				Branch: "master",
+				Event:  model.EventPull,
+			}
+			build3 := &model.Build{
+				RepoID: repo.ID,
+				Branch: "master",
+				Event:  model.EventPush,
+			}
+			err1 := store.CreateBuild(build1, []*model.Proc{}...)
+			err2 := store.CreateBuild(build2, []*model.Proc{}...)
+			err3 := store.CreateBuild(build3, []*model.Proc{}...)
+			getbuild, err4 := store.GetBuildLastEventBefore(&model.Repo{ID: 1}, "master", model.EventPush, build3.Number)
+			g.Assert(err1).IsNil()
+			g.Assert(err2).IsNil()
+			g.Assert(err3).IsNil()
+			g.Assert(err4).IsNil()
+			g.Assert(build1.ID).Equal(getbuild.ID)
+		})
+
+		g.It("Should Get the Deployments of an Environment", func() {
+			build1 := &model.Build{
+				RepoID: repo.ID,
+				Status: model.StatusSuccess,
+				Event:  model.EventDeploy,
+				Deploy: "production",
+			}
+			build2 := &model.Build{
+				RepoID: repo.ID,
+				Status: model.StatusSuccess,
+				Event:  model.EventDeploy,
+				Deploy: "staging",
+			}
+			build3 := &model.Build{
+				RepoID: repo.ID,
+				Status: model.StatusFailure,
+				Event:  model.EventDeploy,
+				Deploy: "production",
+			}
+			err1 := store.CreateBuild(build1, []*model.Proc{}...)
+			err2 := store.CreateBuild(build2, []*model.Proc{}...)
+			err3 := store.CreateBuild(build3, []*model.Proc{}...)
+			builds, err4 := store.GetDeploymentList(&model.Repo{ID: 1}, "production", 1)
+			last, err5 := store.GetDeploymentLast(&model.Repo{ID: 1}, "production")
+			g.Assert(err1).IsNil()
+			g.Assert(err2).IsNil()
+			g.Assert(err3).IsNil()
+			g.Assert(err4).IsNil()
+			g.Assert(err5).IsNil()
+			g.Assert(len(builds)).Equal(2)
+			g.Assert(builds[0].ID).Equal(build3.ID)
+			g.Assert(builds[1].ID).Equal(build1.ID)
+			g.Assert(last.ID).Equal(build1.ID)
+		})
+
 		g.It("Should Get the last Build Before Build N", func() {
 			build1 := &model.Build{
 				RepoID: repo.ID,
@@ -286,6 +373,34 @@ func TestBuilds(t *testing.T) {
 	})
 }
 
+func TestUpdateBlockedBuild(t *testing.T) {
+	store, closer := newTestStore(t, new(model.Build), new(model.Repo))
+	defer closer()
+
+	repo := &model.Repo{UserID: 1, FullName: "octocat/hello-world", Owner: "octocat", Name: "hello-world"}
+	if err := store.CreateRepo(repo); err != nil {
+		t.Error(err)
+	}
+	build := &model.Build{RepoID: repo.ID, Status: model.StatusBlocked}
+	if err := store.CreateBuild(build); err != nil {
+		t.Error(err)
+	}
+
+	build.Status = model.StatusPending
+	if updated, err := store.UpdateBlockedBuild(build); err != nil || !updated {
+		t.Errorf("Want blocked build updated, got %v, %v", updated, err)
+	}
+	// a concurrent approval does not update the build again
+	if updated, err := store.UpdateBlockedBuild(build); err != nil || updated {
+		t.Errorf("Want pending build not updated, got %v, %v", updated, err)
+	}
+
+	build, _ = store.GetBuild(build.ID)
+	if build.Status != model.StatusPending {
+		t.Errorf("Want build status %s, got %s", model.StatusPending, build.Status)
+	}
+}
+
 func TestBuildIncrement(t *testing.T) {
 	store, closer := newTestStore(t, new(model.Build), new(model.Repo))
 	defer closer()
@@ -73,3 +73,10 @@ func (s storage) BuildConfigCreate(config *model.BuildConfig) error {
 	_, err := s.engine.Insert(config)
 	return err
 }
+
+func (s storage) BuildConfigDelete(config *model.BuildConfig) error {
+	_, err := s.engine.
+		Where("config_id = ? AND build_id = ?", config.ConfigID, config.BuildID).
+		Delete(new(model.BuildConfig))
+	return err
+}
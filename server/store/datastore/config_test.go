@@ -102,6 +102,19 @@ func TestConfig(t *testing.T) {
 	if got, want := loaded[0].ID, config.ID; got != want {
 		t.Errorf("Want config by id %d, got %d", want, got)
 	}
+
+	if err := store.BuildConfigDelete(&model.BuildConfig{ConfigID: config.ID, BuildID: build.ID}); err != nil {
+		t.Errorf("Unexpected error: delete build config: %s", err)
+		return
+	}
+	loaded, err = store.ConfigsForBuild(build.ID)
+	if err != nil {
+		t.Errorf("Want configs of build, got error %q", err)
+		return
+	}
+	if len(loaded) != 0 {
+		t.Errorf("Want no configs of the build after deleting the build config, got %d", len(loaded))
+	}
 }
 
 func TestConfigApproved(t *testing.T) {
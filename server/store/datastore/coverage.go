@@ -0,0 +1,44 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func (s storage) CoverageCreate(files []*model.CoverageFile) error {
+	sess := s.engine.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	for i := range files {
+		// only Insert on single object ref set auto created ID back to object
+		if _, err := sess.Insert(files[i]); err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+func (s storage) CoverageList(build *model.Build) ([]*model.CoverageFile, error) {
+	files := make([]*model.CoverageFile, 0, perPage)
+	return files, s.engine.
+		Where("coverage_build_id = ?", build.ID).
+		Asc("coverage_id").
+		Find(&files)
+}
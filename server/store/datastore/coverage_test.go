@@ -0,0 +1,41 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func TestCoverageList(t *testing.T) {
+	store, closer := newTestStore(t, new(model.CoverageFile))
+	defer closer()
+
+	assert.NoError(t, store.CoverageCreate([]*model.CoverageFile{
+		{BuildID: 1, PID: 2, Name: "main.go", CoveredLines: 3, TotalLines: 4},
+		{BuildID: 1, PID: 2, Name: "util.go", CoveredLines: 0, TotalLines: 2},
+		{BuildID: 2, PID: 2, Name: "main.go", CoveredLines: 4, TotalLines: 4},
+	}))
+
+	files, err := store.CoverageList(&model.Build{ID: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, files, 2) {
+		assert.Equal(t, "main.go", files[0].Name)
+		assert.NotZero(t, files[0].ID)
+	}
+}
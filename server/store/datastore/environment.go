@@ -0,0 +1,50 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func (s storage) EnvironmentFind(repo *model.Repo, name string) (*model.Environment, error) {
+	env := &model.Environment{
+		RepoID: repo.ID,
+		Name:   name,
+	}
+	return env, wrapGet(s.engine.Get(env))
+}
+
+func (s storage) EnvironmentList(repo *model.Repo) ([]*model.Environment, error) {
+	envs := make([]*model.Environment, 0, perPage)
+	return envs, s.engine.Where("environment_repo_id = ?", repo.ID).
+		Asc("environment_name").
+		Find(&envs)
+}
+
+func (s storage) EnvironmentCreate(env *model.Environment) error {
+	// only Insert set auto created ID back to object
+	_, err := s.engine.Insert(env)
+	return err
+}
+
+func (s storage) EnvironmentUpdate(env *model.Environment) error {
+	_, err := s.engine.ID(env.ID).AllCols().Update(env)
+	return err
+}
+
+func (s storage) EnvironmentDelete(env *model.Environment) error {
+	_, err := s.engine.ID(env.ID).Delete(new(model.Environment))
+	return err
+}
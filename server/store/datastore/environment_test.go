@@ -0,0 +1,68 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func TestEnvironments(t *testing.T) {
+	store, closer := newTestStore(t, new(model.Environment))
+	defer closer()
+
+	repo := &model.Repo{ID: 1}
+	production := &model.Environment{
+		RepoID:          repo.ID,
+		Name:            "production",
+		Branches:        []string{"main", "release/*"},
+		RequireApproval: true,
+	}
+	assert.NoError(t, store.EnvironmentCreate(production))
+	assert.NoError(t, store.EnvironmentCreate(&model.Environment{RepoID: repo.ID, Name: "staging"}))
+	assert.NoError(t, store.EnvironmentCreate(&model.Environment{RepoID: 2, Name: "production"}))
+
+	// environment names are unique per repository
+	assert.Error(t, store.EnvironmentCreate(&model.Environment{RepoID: repo.ID, Name: "staging"}))
+
+	env, err := store.EnvironmentFind(repo, "production")
+	if assert.NoError(t, err) {
+		assert.Equal(t, production.ID, env.ID)
+		assert.Equal(t, []string{"main", "release/*"}, env.Branches)
+		assert.True(t, env.RequireApproval)
+	}
+
+	env.Branches = nil
+	env.RequireApproval = false
+	assert.NoError(t, store.EnvironmentUpdate(env))
+	env, err = store.EnvironmentFind(repo, "production")
+	if assert.NoError(t, err) {
+		assert.Empty(t, env.Branches)
+		assert.False(t, env.RequireApproval)
+	}
+
+	envs, err := store.EnvironmentList(repo)
+	if assert.NoError(t, err) && assert.Len(t, envs, 2) {
+		assert.Equal(t, "production", envs[0].Name)
+		assert.Equal(t, "staging", envs[1].Name)
+	}
+
+	assert.NoError(t, store.EnvironmentDelete(env))
+	_, err = store.EnvironmentFind(repo, "production")
+	assert.Error(t, err)
+}
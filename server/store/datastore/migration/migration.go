@@ -122,19 +122,28 @@ func runTasks(sess *xorm.Session, tasks []task) error {
 func syncAll(sess *xorm.Session) error {
 	for _, bean := range []interface{}{
 		new(model.Agent),
+		new(model.Approval),
+		new(model.Environment),
+		new(model.AuditEvent),
 		new(model.Build),
 		new(model.BuildConfig),
 		new(model.Config),
+		new(model.CoverageFile),
 		new(model.File),
 		new(model.Logs),
 		new(model.Perm),
 		new(model.Proc),
 		new(model.Registry),
 		new(model.Repo),
+		new(model.Role),
 		new(model.Secret),
 		new(model.Sender),
 		new(model.Task),
+		new(model.Template),
+		new(model.TestResult),
 		new(model.User),
+		new(model.Webhook),
+		new(model.WebhookDelivery),
 	} {
 		if err := sess.Sync2(bean); err != nil {
 			return fmt.Errorf("sync2 error '%s': %v", reflect.TypeOf(bean), err)
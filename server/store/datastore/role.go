@@ -0,0 +1,54 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func (s storage) RoleList(owner string) ([]*model.Role, error) {
+	roles := make([]*model.Role, 0, perPage)
+	return roles, s.engine.
+		Where("role_owner = ? AND role_repo_id = 0", owner).
+		OrderBy("role_login").
+		Find(&roles)
+}
+
+func (s storage) RoleListRepo(repo *model.Repo) ([]*model.Role, error) {
+	roles := make([]*model.Role, 0, perPage)
+	return roles, s.engine.
+		Where("role_repo_id = ?", repo.ID).
+		OrderBy("role_login").
+		Find(&roles)
+}
+
+func (s storage) RoleListUser(user *model.User, repo *model.Repo) ([]*model.Role, error) {
+	roles := make([]*model.Role, 0, perPage)
+	return roles, s.engine.
+		Where("role_login = ?", user.Login).
+		And("(role_owner = ? AND role_repo_id = 0) OR role_repo_id = ?", repo.Owner, repo.ID).
+		Find(&roles)
+}
+
+func (s storage) RoleCreate(role *model.Role) error {
+	// only Insert set auto created ID back to object
+	_, err := s.engine.Insert(role)
+	return err
+}
+
+func (s storage) RoleDelete(role *model.Role) error {
+	_, err := s.engine.ID(role.ID).Delete(new(model.Role))
+	return err
+}
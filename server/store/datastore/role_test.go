@@ -0,0 +1,68 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func TestRoleList(t *testing.T) {
+	store, closer := newTestStore(t, new(model.Role))
+	defer closer()
+
+	assert.NoError(t, store.RoleCreate(&model.Role{Owner: "octocat", Login: "octocat", Name: model.RoleAdmin}))
+	assert.NoError(t, store.RoleCreate(&model.Role{Owner: "octocat", Login: "defunkt", Name: model.RoleAuditor}))
+	assert.NoError(t, store.RoleCreate(&model.Role{Owner: "octocat", RepoID: 1, Login: "defunkt", Name: model.RoleApprover}))
+	assert.NoError(t, store.RoleCreate(&model.Role{Owner: "octocat", RepoID: 2, Login: "defunkt", Name: model.RoleAdmin}))
+	assert.NoError(t, store.RoleCreate(&model.Role{Owner: "github", Login: "defunkt", Name: model.RoleAdmin}))
+
+	roles, err := store.RoleList("octocat")
+	assert.NoError(t, err)
+	assert.Len(t, roles, 2)
+
+	roles, err = store.RoleListRepo(&model.Repo{ID: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, roles, 1) {
+		assert.Equal(t, model.RoleApprover, roles[0].Name)
+	}
+
+	roles, err = store.RoleListUser(&model.User{Login: "defunkt"}, &model.Repo{ID: 1, Owner: "octocat"})
+	assert.NoError(t, err)
+	if assert.Len(t, roles, 2) {
+		names := []string{roles[0].Name, roles[1].Name}
+		assert.ElementsMatch(t, []string{model.RoleAuditor, model.RoleApprover}, names)
+	}
+}
+
+func TestRoleIndexes(t *testing.T) {
+	store, closer := newTestStore(t, new(model.Role))
+	defer closer()
+
+	role := &model.Role{Owner: "octocat", Login: "octocat", Name: model.RoleAdmin}
+	assert.NoError(t, store.RoleCreate(role))
+	assert.EqualValues(t, 1, role.ID)
+
+	// fail due to duplicate role
+	assert.Error(t, store.RoleCreate(&model.Role{Owner: "octocat", Login: "octocat", Name: model.RoleAdmin}))
+
+	assert.NoError(t, store.RoleDelete(role))
+	roles, err := store.RoleList("octocat")
+	assert.NoError(t, err)
+	assert.Len(t, roles, 0)
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func (s storage) TemplateFind(name string) (*model.Template, error) {
+	template := &model.Template{
+		Name: name,
+	}
+	return template, wrapGet(s.engine.Get(template))
+}
+
+func (s storage) TemplateList() ([]*model.Template, error) {
+	templates := make([]*model.Template, 0, perPage)
+	return templates, s.engine.OrderBy("template_name").Find(&templates)
+}
+
+func (s storage) TemplateCreate(template *model.Template) error {
+	// only Insert set auto created ID back to object
+	_, err := s.engine.Insert(template)
+	return err
+}
+
+func (s storage) TemplateUpdate(template *model.Template) error {
+	_, err := s.engine.ID(template.ID).AllCols().Update(template)
+	return err
+}
+
+func (s storage) TemplateDelete(template *model.Template) error {
+	_, err := s.engine.ID(template.ID).Delete(new(model.Template))
+	return err
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func TestTemplates(t *testing.T) {
+	store, closer := newTestStore(t, new(model.Template))
+	defer closer()
+
+	golang := &model.Template{Name: "go", Data: "pipeline:\n  test:\n    image: golang\n"}
+	assert.NoError(t, store.TemplateCreate(golang))
+	assert.NoError(t, store.TemplateCreate(&model.Template{Name: "node", Data: "pipeline: {}"}))
+	// names are unique.
+	assert.Error(t, store.TemplateCreate(&model.Template{Name: "go"}))
+
+	templates, err := store.TemplateList()
+	assert.NoError(t, err)
+	if assert.Len(t, templates, 2) {
+		assert.Equal(t, "go", templates[0].Name)
+	}
+
+	golang.Data = "pipeline:\n  test:\n    image: golang:1.17\n"
+	assert.NoError(t, store.TemplateUpdate(golang))
+	template, err := store.TemplateFind("go")
+	assert.NoError(t, err)
+	assert.Equal(t, golang.Data, template.Data)
+
+	_, err = store.TemplateFind("missing")
+	assert.Error(t, err)
+
+	assert.NoError(t, store.TemplateDelete(golang))
+	_, err = store.TemplateFind("go")
+	assert.Error(t, err)
+}
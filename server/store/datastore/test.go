@@ -0,0 +1,71 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+// testBatchSize is the number of test results inserted at once, it keeps
+// the number of parameters of a statement below the sqlite limit.
+const testBatchSize = 50
+
+func (s storage) TestCreate(results []*model.TestResult) error {
+	sess := s.engine.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	// insert in batches, a single report can contain thousands of tests
+	for start := 0; start < len(results); start += testBatchSize {
+		end := start + testBatchSize
+		if end > len(results) {
+			end = len(results)
+		}
+		if _, err := sess.InsertMulti(results[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+func (s storage) TestList(build *model.Build) ([]*model.TestResult, error) {
+	results := make([]*model.TestResult, 0, perPage)
+	return results, s.engine.
+		Where("test_build_id = ?", build.ID).
+		Asc("test_id").
+		Find(&results)
+}
+
+func (s storage) TestDelete(proc *model.Proc) error {
+	_, err := s.engine.Where("test_proc_id = ?", proc.ID).Delete(new(model.TestResult))
+	return err
+}
+
+func (s storage) TestHistory(repo *model.Repo, suite, name string, limit int) ([]*model.TestResult, error) {
+	results := make([]*model.TestResult, 0, perPage)
+	sess := s.engine.
+		Where("test_repo_id = ? AND test_suite = ? AND test_name = ?", repo.ID, suite, name).
+		Desc("test_build_number", "test_id")
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > perPage {
+		limit = perPage
+	}
+	return results, sess.Limit(limit).Find(&results)
+}
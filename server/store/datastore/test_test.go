@@ -0,0 +1,104 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func TestTestResults(t *testing.T) {
+	store, closer := newTestStore(t, new(model.TestResult))
+	defer closer()
+
+	repo := &model.Repo{ID: 1}
+	assert.NoError(t, store.TestCreate([]*model.TestResult{
+		{RepoID: 1, BuildID: 1, BuildNumber: 1, Suite: "api", Name: "TestBadge", Status: model.TestPassed},
+		{RepoID: 1, BuildID: 1, BuildNumber: 1, Suite: "api", Name: "TestStream", Status: model.TestFailed},
+	}))
+	assert.NoError(t, store.TestCreate([]*model.TestResult{
+		{RepoID: 1, BuildID: 2, BuildNumber: 2, Suite: "api", Name: "TestStream", Status: model.TestPassed},
+		{RepoID: 2, BuildID: 3, BuildNumber: 1, Suite: "api", Name: "TestStream", Status: model.TestPassed},
+	}))
+	assert.NoError(t, store.TestCreate(nil))
+
+	results, err := store.TestList(&model.Build{ID: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "TestBadge", results[0].Name)
+		assert.NotZero(t, results[0].ID)
+	}
+
+	results, err = store.TestHistory(repo, "api", "TestStream", 50)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		// newest first
+		assert.Equal(t, int64(2), results[0].BuildNumber)
+		assert.Equal(t, model.TestFailed, results[1].Status)
+	}
+
+	results, err = store.TestHistory(repo, "api", "TestStream", 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	// the limit is clamped
+	results, err = store.TestHistory(repo, "api", "TestStream", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	results, err = store.TestHistory(repo, "api", "TestStream", -1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func TestTestCreateBatches(t *testing.T) {
+	store, closer := newTestStore(t, new(model.TestResult))
+	defer closer()
+
+	var results []*model.TestResult
+	for i := 0; i < 2*testBatchSize+5; i++ {
+		results = append(results, &model.TestResult{RepoID: 1, BuildID: 1, BuildNumber: int64(i + 1), Suite: "api", Name: "TestStream", Status: model.TestPassed})
+	}
+	assert.NoError(t, store.TestCreate(results))
+
+	stored, err := store.TestList(&model.Build{ID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, stored, len(results))
+
+	history, err := store.TestHistory(&model.Repo{ID: 1}, "api", "TestStream", 1000)
+	assert.NoError(t, err)
+	assert.Len(t, history, perPage)
+}
+
+func TestTestDelete(t *testing.T) {
+	store, closer := newTestStore(t, new(model.TestResult))
+	defer closer()
+
+	assert.NoError(t, store.TestCreate([]*model.TestResult{
+		{RepoID: 1, BuildID: 1, ProcID: 2, Suite: "api", Name: "TestBadge", Status: model.TestFailed},
+		{RepoID: 1, BuildID: 1, ProcID: 3, Suite: "api", Name: "TestStream", Status: model.TestPassed},
+	}))
+
+	// the results of a restarted proc are deleted
+	assert.NoError(t, store.TestDelete(&model.Proc{ID: 2}))
+
+	results, err := store.TestList(&model.Build{ID: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "TestStream", results[0].Name)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func (s storage) WebhookFind(repoID, id int64) (*model.Webhook, error) {
+	hook := new(model.Webhook)
+	return hook, wrapGet(s.engine.
+		Where("webhook_repo_id = ? AND webhook_id = ?", repoID, id).
+		Get(hook))
+}
+
+func (s storage) WebhookList(repoID int64) ([]*model.Webhook, error) {
+	hooks := make([]*model.Webhook, 0, perPage)
+	return hooks, s.engine.Where("webhook_repo_id = ?", repoID).Find(&hooks)
+}
+
+func (s storage) WebhookListActive(repo *model.Repo) ([]*model.Webhook, error) {
+	hooks := make([]*model.Webhook, 0, perPage)
+	return hooks, s.engine.
+		Where("webhook_active = ?", true).
+		And("webhook_repo_id = 0 OR webhook_repo_id = ?", repo.ID).
+		Find(&hooks)
+}
+
+func (s storage) WebhookCreate(hook *model.Webhook) error {
+	// only Insert set auto created ID back to object
+	_, err := s.engine.Insert(hook)
+	return err
+}
+
+func (s storage) WebhookUpdate(hook *model.Webhook) error {
+	_, err := s.engine.ID(hook.ID).AllCols().Update(hook)
+	return err
+}
+
+func (s storage) WebhookDelete(hook *model.Webhook) error {
+	sess := s.engine.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Where("delivery_webhook_id = ?", hook.ID).Delete(new(model.WebhookDelivery)); err != nil {
+		return err
+	}
+	if _, err := sess.ID(hook.ID).Delete(new(model.Webhook)); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+func (s storage) WebhookDeliveryCreate(delivery *model.WebhookDelivery) error {
+	// only Insert set auto created ID back to object
+	_, err := s.engine.Insert(delivery)
+	return err
+}
+
+func (s storage) WebhookDeliveryUpdate(delivery *model.WebhookDelivery) error {
+	_, err := s.engine.ID(delivery.ID).AllCols().Update(delivery)
+	return err
+}
+
+func (s storage) WebhookDeliveryList(hook *model.Webhook) ([]*model.WebhookDelivery, error) {
+	deliveries := make([]*model.WebhookDelivery, 0, perPage)
+	return deliveries, s.engine.
+		Where("delivery_webhook_id = ?", hook.ID).
+		Desc("delivery_id").
+		Limit(perPage).
+		Find(&deliveries)
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+func TestWebhooks(t *testing.T) {
+	store, closer := newTestStore(t, new(model.Webhook), new(model.WebhookDelivery))
+	defer closer()
+
+	global := &model.Webhook{URL: "https://example.com/global", Active: true}
+	assert.NoError(t, store.WebhookCreate(global))
+	repoHook := &model.Webhook{RepoID: 1, URL: "https://example.com/repo", Events: []string{"finished"}, Active: true}
+	assert.NoError(t, store.WebhookCreate(repoHook))
+	assert.NoError(t, store.WebhookCreate(&model.Webhook{RepoID: 2, URL: "https://example.com/other", Active: true}))
+	assert.NoError(t, store.WebhookCreate(&model.Webhook{RepoID: 1, URL: "https://example.com/inactive"}))
+
+	hooks, err := store.WebhookList(1)
+	assert.NoError(t, err)
+	assert.Len(t, hooks, 2)
+
+	hooks, err = store.WebhookListActive(&model.Repo{ID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, hooks, 2)
+
+	hook, err := store.WebhookFind(1, repoHook.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"finished"}, hook.Events)
+
+	// webhook of another scope
+	_, err = store.WebhookFind(0, repoHook.ID)
+	assert.Error(t, err)
+
+	delivery := &model.WebhookDelivery{WebhookID: repoHook.ID, Event: "finished"}
+	assert.NoError(t, store.WebhookDeliveryCreate(delivery))
+	delivery.Success = true
+	assert.NoError(t, store.WebhookDeliveryUpdate(delivery))
+
+	deliveries, err := store.WebhookDeliveryList(repoHook)
+	assert.NoError(t, err)
+	if assert.Len(t, deliveries, 1) {
+		assert.True(t, deliveries[0].Success)
+	}
+
+	assert.NoError(t, store.WebhookDelete(repoHook))
+	deliveries, err = store.WebhookDeliveryList(repoHook)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 0)
+}
@@ -78,6 +78,14 @@ type Store interface {
 	// GetBuildLast gets the last build for the branch.
 	GetBuildLast(*model.Repo, string) (*model.Build, error)
 
+	// GetBuildLastEvent gets the last build of the event for the branch. An
+	// empty branch matches all branches.
+	GetBuildLastEvent(*model.Repo, string, string) (*model.Build, error)
+
+	// GetBuildLastEventBefore gets the last build of the event for the branch
+	// before build number N.
+	GetBuildLastEventBefore(*model.Repo, string, string, int64) (*model.Build, error)
+
 	// GetBuildLastBefore gets the last build before build number N.
 	GetBuildLastBefore(*model.Repo, string, int64) (*model.Build, error)
 
@@ -85,6 +93,14 @@ type Store interface {
 	// TODO: paginate
 	GetBuildList(*model.Repo, int) ([]*model.Build, error)
 
+	// GetDeploymentList gets a list of deployments to the environment,
+	// ordered by build number descending.
+	GetDeploymentList(*model.Repo, string, int) ([]*model.Build, error)
+
+	// GetDeploymentLast gets the last successful deployment to the
+	// environment.
+	GetDeploymentLast(*model.Repo, string) (*model.Build, error)
+
 	// GetBuildQueue gets a list of build in queue.
 	GetBuildQueue() ([]*model.Feed, error)
 
@@ -97,6 +113,10 @@ type Store interface {
 	// UpdateBuild updates a build.
 	UpdateBuild(*model.Build) error
 
+	// UpdateBlockedBuild updates a build if it is still blocked and
+	// returns false otherwise.
+	UpdateBlockedBuild(*model.Build) (bool, error)
+
 	//
 	// new functions
 	//
@@ -119,6 +139,32 @@ type Store interface {
 	ConfigFindApproved(*model.Config) (bool, error)
 	ConfigCreate(*model.Config) error
 	BuildConfigCreate(*model.BuildConfig) error
+	BuildConfigDelete(*model.BuildConfig) error
+
+	RoleList(owner string) ([]*model.Role, error)
+	RoleListRepo(*model.Repo) ([]*model.Role, error)
+	RoleListUser(*model.User, *model.Repo) ([]*model.Role, error)
+	RoleCreate(*model.Role) error
+	RoleDelete(*model.Role) error
+
+	AuditCreate(*model.AuditEvent) error
+	AuditList(*model.AuditFilter) ([]*model.AuditEvent, error)
+
+	WebhookFind(repoID, id int64) (*model.Webhook, error)
+	WebhookList(repoID int64) ([]*model.Webhook, error)
+	WebhookListActive(*model.Repo) ([]*model.Webhook, error)
+	WebhookCreate(*model.Webhook) error
+	WebhookUpdate(*model.Webhook) error
+	WebhookDelete(*model.Webhook) error
+	WebhookDeliveryCreate(*model.WebhookDelivery) error
+	WebhookDeliveryUpdate(*model.WebhookDelivery) error
+	WebhookDeliveryList(*model.Webhook) ([]*model.WebhookDelivery, error)
+
+	TemplateFind(name string) (*model.Template, error)
+	TemplateList() ([]*model.Template, error)
+	TemplateCreate(*model.Template) error
+	TemplateUpdate(*model.Template) error
+	TemplateDelete(*model.Template) error
 
 	SenderFind(*model.Repo, string) (*model.Sender, error)
 	// SenderList TODO: paginate
@@ -157,6 +203,26 @@ type Store interface {
 	FileRead(*model.Proc, string) (io.ReadCloser, error)
 	FileCreate(*model.File, io.Reader) error
 
+	TestCreate([]*model.TestResult) error
+	TestList(*model.Build) ([]*model.TestResult, error)
+	// TestDelete deletes the results of the test reports of the proc.
+	TestDelete(*model.Proc) error
+	// TestHistory returns the latest results of the test, ordered by build
+	// number descending.
+	TestHistory(repo *model.Repo, suite, name string, limit int) ([]*model.TestResult, error)
+
+	CoverageCreate([]*model.CoverageFile) error
+	CoverageList(*model.Build) ([]*model.CoverageFile, error)
+
+	ApprovalCreate(*model.Approval) error
+	ApprovalList(*model.Build) ([]*model.Approval, error)
+
+	EnvironmentFind(*model.Repo, string) (*model.Environment, error)
+	EnvironmentList(*model.Repo) ([]*model.Environment, error)
+	EnvironmentCreate(*model.Environment) error
+	EnvironmentUpdate(*model.Environment) error
+	EnvironmentDelete(*model.Environment) error
+
 	// TaskList TODO: paginate & opt filter
 	TaskList() ([]*model.Task, error)
 	TaskInsert(*model.Task) error
@@ -0,0 +1,145 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+var (
+	errAddressNotAllowed = errors.New("the webhook destination address is not allowed")
+	errDeliveryFailed    = errors.New("cannot deliver the webhook")
+)
+
+// restricted contains the networks webhooks must not be delivered
+// to unless they are explicitly allowed.
+var restricted = mustParseNetworks(
+	"0.0.0.0/8",      // current network
+	"10.0.0.0/8",     // private
+	"100.64.0.0/10",  // carrier-grade NAT
+	"127.0.0.0/8",    // loopback
+	"169.254.0.0/16", // link-local
+	"172.16.0.0/12",  // private
+	"192.168.0.0/16", // private
+	"224.0.0.0/4",    // multicast
+	"::/128",         // unspecified
+	"::1/128",        // loopback
+	"fc00::/7",       // unique local
+	"fe80::/10",      // link-local
+	"ff00::/8",       // multicast
+)
+
+// ParseNetworks parses a list of networks in CIDR notation. Single
+// addresses are treated as networks containing only that address.
+func ParseNetworks(values []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid network %q", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q", value)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func mustParseNetworks(values ...string) []*net.IPNet {
+	networks, err := ParseNetworks(values)
+	if err != nil {
+		panic(err)
+	}
+	return networks
+}
+
+// allowed returns true if webhooks can be delivered to the address.
+func (d *Dispatcher) allowed(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, network := range d.allow {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	for _, network := range restricted {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// control rejects connections to restricted addresses. It runs after
+// the host name is resolved, so it also covers redirects and names
+// resolving to a different address than at registration.
+func (d *Dispatcher) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errAddressNotAllowed
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !d.allowed(ip) {
+		return errAddressNotAllowed
+	}
+	return nil
+}
+
+// ValidateURL returns an error if the webhook url resolves to an
+// address webhooks must not be delivered to.
+func (d *Dispatcher) ValidateURL(ctx context.Context, rawurl string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return errAddressNotAllowed
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !d.allowed(ip) {
+			return errAddressNotAllowed
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve the webhook host %s", host)
+	}
+	for _, addr := range addrs {
+		if !d.allowed(addr.IP) {
+			return errAddressNotAllowed
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook delivers build lifecycle events to outbound webhooks.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+	"github.com/woodpecker-ci/woodpecker/server/pubsub"
+)
+
+// Headers set on every delivery.
+const (
+	HeaderEvent     = "X-Woodpecker-Event"
+	HeaderDelivery  = "X-Woodpecker-Delivery"
+	HeaderSignature = "X-Woodpecker-Signature"
+)
+
+// Store defines the storage used by the dispatcher.
+type Store interface {
+	WebhookListActive(*model.Repo) ([]*model.Webhook, error)
+	WebhookDeliveryCreate(*model.WebhookDelivery) error
+	WebhookDeliveryUpdate(*model.WebhookDelivery) error
+}
+
+// Dispatcher subscribes to build events and delivers them to the
+// matching webhooks.
+type Dispatcher struct {
+	store   Store
+	client  *http.Client
+	retries int
+	backoff time.Duration
+	allow   []*net.IPNet
+}
+
+// New returns a new dispatcher. Failed deliveries are retried up to
+// retries times, doubling the backoff duration after each attempt.
+// Deliveries to loopback, link-local and private addresses are
+// rejected unless the address is part of the allowed networks.
+func New(store Store, timeout time.Duration, retries int, backoff time.Duration, allow []*net.IPNet) *Dispatcher {
+	d := &Dispatcher{
+		store:   store,
+		retries: retries,
+		backoff: backoff,
+		allow:   allow,
+	}
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   d.control,
+	}
+	d.client = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// deliveries never go through a proxy, the destination
+			// address could not be checked otherwise
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	}
+	return d
+}
+
+// Start subscribes to the events topic and blocks until the
+// context is canceled.
+func (d *Dispatcher) Start(ctx context.Context, publisher pubsub.Publisher, topic string) error {
+	return publisher.Subscribe(ctx, topic, func(m pubsub.Message) {
+		event := new(model.Event)
+		if err := json.Unmarshal(m.Data, event); err != nil {
+			log.Error().Err(err).Msg("webhook: cannot decode event")
+			return
+		}
+		d.Dispatch(ctx, event)
+	})
+}
+
+// Dispatch delivers the event to all active webhooks subscribed to it.
+func (d *Dispatcher) Dispatch(ctx context.Context, event *model.Event) {
+	if event.Type == "" {
+		// intermediate state updates are only of interest for the UI
+		return
+	}
+
+	hooks, err := d.store.WebhookListActive(&event.Repo)
+	if err != nil {
+		log.Error().Err(err).Msgf("webhook: cannot list webhooks of %s", event.Repo.FullName)
+		return
+	}
+
+	types := []model.EventType{event.Type}
+	// the final workflow of a finished build completed as well
+	if event.Type == model.Finished && event.Proc.ID != 0 {
+		types = append(types, model.ProcFinished)
+	}
+
+	for _, typ := range types {
+		typed := *event
+		typed.Type = typ
+		payload, err := json.Marshal(typed)
+		if err != nil {
+			log.Error().Err(err).Msg("webhook: cannot encode event")
+			return
+		}
+		for _, hook := range hooks {
+			if !hook.Match(typ) {
+				continue
+			}
+			if _, err := d.Deliver(ctx, hook, typ, payload); err != nil {
+				log.Error().Err(err).Msgf("webhook: delivery of %s to %s failed", typ, hook.URL)
+			}
+		}
+	}
+}
+
+// Deliver sends the payload to the webhook, retrying on failure, and
+// records the delivery.
+func (d *Dispatcher) Deliver(ctx context.Context, hook *model.Webhook, event model.EventType, payload []byte) (*model.WebhookDelivery, error) {
+	delivery := &model.WebhookDelivery{
+		WebhookID: hook.ID,
+		Event:     string(event),
+		Payload:   string(payload),
+		Created:   time.Now().Unix(),
+	}
+	if err := d.store.WebhookDeliveryCreate(delivery); err != nil {
+		return nil, err
+	}
+
+	backoff := d.backoff
+	for delivery.Attempts <= d.retries {
+		if delivery.Attempts > 0 {
+			select {
+			case <-ctx.Done():
+				delivery.Error = ctx.Err().Error()
+				delivery.Attempts = d.retries + 1
+				continue
+			case <-time.After(backoff):
+				backoff *= 2
+			}
+		}
+		delivery.Attempts++
+
+		status, err := d.send(ctx, hook, delivery)
+		delivery.Status = status
+		if err == nil {
+			delivery.Success = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+	}
+	delivery.Finished = time.Now().Unix()
+
+	if err := d.store.WebhookDeliveryUpdate(delivery); err != nil {
+		return delivery, err
+	}
+	if !delivery.Success {
+		return delivery, fmt.Errorf("%s", delivery.Error)
+	}
+	return delivery, nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, hook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", hook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		log.Debug().Err(err).Msgf("webhook: cannot create the request of delivery %d", delivery.ID)
+		return 0, errDeliveryFailed
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	if hook.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(hook.Secret, []byte(delivery.Payload)))
+	}
+
+	// transport errors are not recorded, they would reveal details
+	// about the network of the server
+	resp, err := d.client.Do(req)
+	if err != nil {
+		log.Debug().Err(err).Msgf("webhook: delivery %d failed", delivery.ID)
+		if errors.Is(err, errAddressNotAllowed) {
+			return 0, errAddressNotAllowed
+		}
+		return 0, errDeliveryFailed
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the HMAC-SHA256 signature of the payload in the
+// format sha256=<hex>, as sent in the signature header.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/woodpecker-ci/woodpecker/server/model"
+)
+
+// the test servers listen on the loopback interface
+var loopback = mustParseNetworks("127.0.0.1")
+
+type mockStore struct {
+	sync.Mutex
+	hooks      []*model.Webhook
+	deliveries []*model.WebhookDelivery
+}
+
+func (s *mockStore) WebhookListActive(*model.Repo) ([]*model.Webhook, error) {
+	return s.hooks, nil
+}
+
+func (s *mockStore) WebhookDeliveryCreate(d *model.WebhookDelivery) error {
+	s.Lock()
+	defer s.Unlock()
+	d.ID = int64(len(s.deliveries) + 1)
+	s.deliveries = append(s.deliveries, d)
+	return nil
+}
+
+func (s *mockStore) WebhookDeliveryUpdate(*model.WebhookDelivery) error {
+	return nil
+}
+
+func TestDeliverSigned(t *testing.T) {
+	var header http.Header
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	store := new(mockStore)
+	hook := &model.Webhook{ID: 1, URL: ts.URL, Secret: "correct-horse-battery-staple"}
+	d := New(store, time.Second, 0, 0, loopback)
+
+	delivery, err := d.Deliver(context.Background(), hook, model.Started, []byte(`{"type":"started"}`))
+	assert.NoError(t, err)
+	assert.True(t, delivery.Success)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.Status)
+	assert.Equal(t, `{"type":"started"}`, string(body))
+	assert.Equal(t, "started", header.Get(HeaderEvent))
+	assert.Equal(t, "1", header.Get(HeaderDelivery))
+	assert.Equal(t, Sign(hook.Secret, body), header.Get(HeaderSignature))
+}
+
+func TestDeliverRetry(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer ts.Close()
+
+	d := New(new(mockStore), time.Second, 2, time.Millisecond, loopback)
+	delivery, err := d.Deliver(context.Background(), &model.Webhook{URL: ts.URL}, model.Finished, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Empty(t, delivery.Error)
+
+	calls = -10
+	d = New(new(mockStore), time.Second, 1, time.Millisecond, loopback)
+	delivery, err = d.Deliver(context.Background(), &model.Webhook{URL: ts.URL}, model.Finished, []byte(`{}`))
+	assert.Error(t, err)
+	assert.False(t, delivery.Success)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, http.StatusBadGateway, delivery.Status)
+}
+
+func TestDispatch(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		events = append(events, r.Header.Get(HeaderEvent))
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	store := &mockStore{hooks: []*model.Webhook{
+		{ID: 1, URL: ts.URL, Events: []string{"finished"}},
+		{ID: 2, URL: ts.URL, Events: []string{"proc_finished"}},
+	}}
+	d := New(store, time.Second, 0, 0, loopback)
+
+	// untyped state updates are not delivered
+	d.Dispatch(context.Background(), &model.Event{})
+	d.Dispatch(context.Background(), &model.Event{Type: model.Started})
+	d.Dispatch(context.Background(), &model.Event{Type: model.Finished, Proc: model.Proc{ID: 1}})
+
+	assert.ElementsMatch(t, []string{"finished", "proc_finished"}, events)
+	assert.Len(t, store.deliveries, 2)
+}
+
+func TestDeliverRestricted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("delivered to a restricted address")
+	}))
+	defer ts.Close()
+
+	d := New(new(mockStore), time.Second, 0, 0, nil)
+	delivery, err := d.Deliver(context.Background(), &model.Webhook{URL: ts.URL}, model.Finished, []byte(`{}`))
+	assert.Error(t, err)
+	assert.False(t, delivery.Success)
+	assert.Equal(t, errAddressNotAllowed.Error(), delivery.Error)
+	assert.Error(t, d.ValidateURL(context.Background(), ts.URL))
+	assert.Error(t, d.ValidateURL(context.Background(), "http://localhost:8000"))
+
+	// transport errors are not recorded
+	d = New(new(mockStore), time.Second, 0, 0, loopback)
+	delivery, err = d.Deliver(context.Background(), &model.Webhook{URL: "http://127.0.0.1:1"}, model.Finished, []byte(`{}`))
+	assert.Error(t, err)
+	assert.Equal(t, errDeliveryFailed.Error(), delivery.Error)
+}
+
+func TestValidateURL(t *testing.T) {
+	d := New(new(mockStore), time.Second, 0, 0, mustParseNetworks("10.1.0.0/16", "fd00::1"))
+	for _, u := range []string{
+		"http://127.0.0.1",
+		"http://[::1]:8000",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.2.0.1",
+		"http://192.168.1.1",
+		"http://[::ffff:127.0.0.1]",
+		"http://[fe80::1]",
+		"http://0.0.0.0",
+	} {
+		assert.Error(t, d.ValidateURL(context.Background(), u), u)
+	}
+	for _, u := range []string{
+		"http://10.1.2.3",
+		"https://[fd00::1]:8443",
+		"http://93.184.216.34",
+	} {
+		assert.NoError(t, d.ValidateURL(context.Background(), u), u)
+	}
+}
+
+func TestParseNetworks(t *testing.T) {
+	networks, err := ParseNetworks([]string{"10.0.0.0/8", " 192.168.1.1 ", "", "fd00::/8"})
+	assert.NoError(t, err)
+	assert.Len(t, networks, 3)
+	assert.Equal(t, "192.168.1.1/32", networks[1].String())
+
+	_, err = ParseNetworks([]string{"example.com"})
+	assert.Error(t, err)
+	_, err = ParseNetworks([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
@@ -56,8 +56,13 @@ func LoadYmlFileAsJson(path string) ([]byte, error) {
 		return nil, err
 	}
 
+	return ToJson(data)
+}
+
+// ToJson converts the yaml data to json.
+func ToJson(data []byte) ([]byte, error) {
 	m := make(map[interface{}]interface{})
-	err = yaml.Unmarshal(data, &m)
+	err := yaml.Unmarshal(data, &m)
 	if err != nil {
 		return nil, err
 	}
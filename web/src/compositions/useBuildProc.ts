@@ -24,7 +24,7 @@ export default () => {
     }
   }
 
-  async function load(owner: string, repo: string, build: number, _proc: BuildProc) {
+  function load(owner: string, repo: string, build: number, _proc: BuildProc) {
     unload();
 
     proc.value = _proc;
@@ -35,11 +35,8 @@ export default () => {
       return;
     }
 
-    if (isProcFinished(_proc)) {
-      logs.value = await apiClient.getLogs(owner, repo, build, _proc.pid);
-    }
-
-    if (isProcRunning(_proc)) {
+    // the stream replays the logs of finished jobs
+    if (isProcFinished(_proc) || isProcRunning(_proc)) {
       stream = apiClient.streamLogs(owner, repo, build, _proc.pid, onLogsUpdate);
     }
   }
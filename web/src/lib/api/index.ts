@@ -134,8 +134,9 @@ export default class WoodpeckerClient extends ApiClient {
     // eslint-disable-next-line promise/prefer-await-to-callbacks
     callback: (data: BuildLog) => void,
   ): EventSource {
+    // the stream resumes after reconnects and ends with an eof error
     return this._subscribe(`/stream/logs/${owner}/${repo}/${build}/${proc}`, callback, {
-      reconnect: true,
+      reconnect: false,
     });
   }
 }
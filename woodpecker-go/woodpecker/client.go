@@ -23,6 +23,12 @@ const (
 	pathRepair         = "%s/api/repos/%s/%s/repair"
 	pathBuilds         = "%s/api/repos/%s/%s/builds"
 	pathBuild          = "%s/api/repos/%s/%s/builds/%v"
+	pathBuildConfig    = "%s/api/repos/%s/%s/builds/%d/config"
+	pathBuildTests     = "%s/api/repos/%s/%s/builds/%d/tests"
+	pathBuildCoverage  = "%s/api/repos/%s/%s/builds/%d/coverage"
+	pathTestHistory    = "%s/api/repos/%s/%s/tests/history?%s"
+	pathRestart        = "%s/api/repos/%s/%s/builds/%d/restart"
+	pathPromote        = "%s/api/repos/%s/%s/builds/%d/promote"
 	pathApprove        = "%s/api/repos/%s/%s/builds/%d/approve"
 	pathDecline        = "%s/api/repos/%s/%s/builds/%d/decline"
 	pathJob            = "%s/api/repos/%s/%s/builds/%d/%d"
@@ -32,10 +38,24 @@ const (
 	pathRepoSecret     = "%s/api/repos/%s/%s/secrets/%s"
 	pathRepoRegistries = "%s/api/repos/%s/%s/registry"
 	pathRepoRegistry   = "%s/api/repos/%s/%s/registry/%s"
+	pathEnvironments   = "%s/api/repos/%s/%s/environments"
+	pathEnvironment    = "%s/api/repos/%s/%s/environments/%s"
+	pathDeployments    = "%s/api/repos/%s/%s/environments/%s/deployments?page=%d"
+	pathRollback       = "%s/api/repos/%s/%s/environments/%s/rollback/%d"
+	pathRepoSenders    = "%s/api/repos/%s/%s/senders"
+	pathRepoSender     = "%s/api/repos/%s/%s/senders/%s"
+	pathRepoRoles      = "%s/api/repos/%s/%s/roles"
+	pathRepoRole       = "%s/api/repos/%s/%s/roles/%s/%s"
+	pathOrgRoles       = "%s/api/orgs/%s/roles"
+	pathOrgRole        = "%s/api/orgs/%s/roles/%s/%s"
 	pathUsers          = "%s/api/users"
 	pathUser           = "%s/api/users/%s"
 	pathBuildQueue     = "%s/api/builds"
 	pathQueue          = "%s/api/queue"
+	pathRepoWebhooks   = "%s/api/repos/%s/%s/webhooks"
+	pathWebhooks       = "%s/api/webhooks"
+	pathAudit          = "%s/api/audit?%s"
+	pathAuditExport    = "%s/api/audit/export?%s"
 	pathVersion        = "%s/version"
 	pathLogLevel       = "%s/api/log-level"
 )
@@ -189,6 +209,42 @@ func (c *client) Build(owner, name string, num int) (*Build, error) {
 	return out, err
 }
 
+// BuildConfig returns the pipeline configs of the build.
+func (c *client) BuildConfig(owner, name string, num int) ([]*Config, error) {
+	var out []*Config
+	uri := fmt.Sprintf(pathBuildConfig, c.addr, owner, name, num)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// BuildTests returns the test results of the build.
+func (c *client) BuildTests(owner, name string, num int) ([]*TestResult, error) {
+	var out []*TestResult
+	uri := fmt.Sprintf(pathBuildTests, c.addr, owner, name, num)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// BuildCoverage returns the coverage of the build compared to its base
+// build.
+func (c *client) BuildCoverage(owner, name string, num int) (*Coverage, error) {
+	out := new(Coverage)
+	uri := fmt.Sprintf(pathBuildCoverage, c.addr, owner, name, num)
+	err := c.get(uri, out)
+	return out, err
+}
+
+// TestHistory returns the results of the test in the latest builds.
+func (c *client) TestHistory(owner, name, suite, test string) (*TestHistory, error) {
+	out := new(TestHistory)
+	val := url.Values{}
+	val.Set("suite", suite)
+	val.Set("name", test)
+	uri := fmt.Sprintf(pathTestHistory, c.addr, owner, name, val.Encode())
+	err := c.get(uri, out)
+	return out, err
+}
+
 // Build returns the latest repository build by branch.
 func (c *client) BuildLast(owner, name, branch string) (*Build, error) {
 	out := new(Build)
@@ -226,6 +282,22 @@ func (c *client) BuildStart(owner, name string, num int, params map[string]strin
 	return out, err
 }
 
+// BuildRestart restarts the failed or the given workflows of a finished
+// build, and the workflows depending on them, within the build.
+func (c *client) BuildRestart(owner, name string, num int, failed bool, pids []int) (*Build, error) {
+	out := new(Build)
+	val := url.Values{}
+	if failed {
+		val.Set("failed", "true")
+	}
+	for _, pid := range pids {
+		val.Add("pid", strconv.Itoa(pid))
+	}
+	uri := fmt.Sprintf(pathRestart, c.addr, owner, name, num)
+	err := c.post(uri+"?"+val.Encode(), nil, out)
+	return out, err
+}
+
 // BuildStop cancels the running job.
 func (c *client) BuildStop(owner, name string, num, job int) error {
 	uri := fmt.Sprintf(pathJob, c.addr, owner, name, num, job)
@@ -273,6 +345,17 @@ func (c *client) Deploy(owner, name string, num int, env string, params map[stri
 	return out, err
 }
 
+// Promote promotes a successful build to the target environment, only the
+// workflows and steps of the environment run.
+func (c *client) Promote(owner, name string, num int, target string, params map[string]string) (*Build, error) {
+	out := new(Build)
+	val := mapValues(params)
+	val.Set("target", target)
+	uri := fmt.Sprintf(pathPromote, c.addr, owner, name, num)
+	err := c.post(uri+"?"+val.Encode(), nil, out)
+	return out, err
+}
+
 // LogsPurge purges the build logs for the specified build.
 func (c *client) LogsPurge(owner, name string, num int) error {
 	uri := fmt.Sprintf(pathLogPurge, c.addr, owner, name, num)
@@ -318,6 +401,87 @@ func (c *client) RegistryDelete(owner, name, hostname string) error {
 	return c.delete(uri)
 }
 
+// EnvironmentList returns the deployment environments of the repository.
+func (c *client) EnvironmentList(owner, name string) ([]*Environment, error) {
+	var out []*Environment
+	uri := fmt.Sprintf(pathEnvironments, c.addr, owner, name)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// Environment returns a deployment environment by name.
+func (c *client) Environment(owner, name, env string) (*Environment, error) {
+	out := new(Environment)
+	uri := fmt.Sprintf(pathEnvironment, c.addr, owner, name, env)
+	err := c.get(uri, out)
+	return out, err
+}
+
+// EnvironmentCreate creates a deployment environment.
+func (c *client) EnvironmentCreate(owner, name string, in *Environment) (*Environment, error) {
+	out := new(Environment)
+	uri := fmt.Sprintf(pathEnvironments, c.addr, owner, name)
+	err := c.post(uri, in, out)
+	return out, err
+}
+
+// EnvironmentPatch updates the protection rules of a deployment
+// environment.
+func (c *client) EnvironmentPatch(owner, name, env string, in *EnvironmentPatch) (*Environment, error) {
+	out := new(Environment)
+	uri := fmt.Sprintf(pathEnvironment, c.addr, owner, name, env)
+	err := c.patch(uri, in, out)
+	return out, err
+}
+
+// EnvironmentDelete deletes a deployment environment.
+func (c *client) EnvironmentDelete(owner, name, env string) error {
+	uri := fmt.Sprintf(pathEnvironment, c.addr, owner, name, env)
+	return c.delete(uri)
+}
+
+// DeploymentList returns the deployment history of the environment.
+func (c *client) DeploymentList(owner, name, env string, page int) ([]*Build, error) {
+	var out []*Build
+	uri := fmt.Sprintf(pathDeployments, c.addr, owner, name, env, page)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// Rollback deploys the commit of a previous successful deployment to the
+// environment again.
+func (c *client) Rollback(owner, name, env string, num int) (*Build, error) {
+	out := new(Build)
+	uri := fmt.Sprintf(pathRollback, c.addr, owner, name, env, num)
+	err := c.post(uri, nil, out)
+	return out, err
+}
+
+// SenderList returns the senders on the allow and block lists of the
+// repository.
+func (c *client) SenderList(owner, name string) ([]*Sender, error) {
+	var out []*Sender
+	uri := fmt.Sprintf(pathRepoSenders, c.addr, owner, name)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// SenderUpdate adds the sender to the allow or block list of the
+// repository.
+func (c *client) SenderUpdate(owner, name string, in *Sender) (*Sender, error) {
+	out := new(Sender)
+	uri := fmt.Sprintf(pathRepoSenders, c.addr, owner, name)
+	err := c.post(uri, in, out)
+	return out, err
+}
+
+// SenderDelete removes the sender from the allow and block lists of the
+// repository.
+func (c *client) SenderDelete(owner, name, login string) error {
+	uri := fmt.Sprintf(pathRepoSender, c.addr, owner, name, login)
+	return c.delete(uri)
+}
+
 // Secret returns a secret by name.
 func (c *client) Secret(owner, name, secret string) (*Secret, error) {
 	out := new(Secret)
@@ -356,6 +520,111 @@ func (c *client) SecretDelete(owner, name, secret string) error {
 	return c.delete(uri)
 }
 
+// OrgRoleList returns the roles granted for an organization.
+func (c *client) OrgRoleList(owner string) ([]*Role, error) {
+	var out []*Role
+	uri := fmt.Sprintf(pathOrgRoles, c.addr, owner)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// OrgRoleCreate grants a role for an organization.
+func (c *client) OrgRoleCreate(owner string, in *Role) (*Role, error) {
+	out := new(Role)
+	uri := fmt.Sprintf(pathOrgRoles, c.addr, owner)
+	err := c.post(uri, in, out)
+	return out, err
+}
+
+// OrgRoleDelete revokes a role granted for an organization.
+func (c *client) OrgRoleDelete(owner, login, role string) error {
+	uri := fmt.Sprintf(pathOrgRole, c.addr, owner, login, role)
+	return c.delete(uri)
+}
+
+// RoleList returns the roles granted for a repository.
+func (c *client) RoleList(owner, name string) ([]*Role, error) {
+	var out []*Role
+	uri := fmt.Sprintf(pathRepoRoles, c.addr, owner, name)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// RoleCreate grants a role for a repository.
+func (c *client) RoleCreate(owner, name string, in *Role) (*Role, error) {
+	out := new(Role)
+	uri := fmt.Sprintf(pathRepoRoles, c.addr, owner, name)
+	err := c.post(uri, in, out)
+	return out, err
+}
+
+// RoleDelete revokes a role granted for a repository.
+func (c *client) RoleDelete(owner, name, login, role string) error {
+	uri := fmt.Sprintf(pathRepoRole, c.addr, owner, name, login, role)
+	return c.delete(uri)
+}
+
+// WebhookList returns the webhooks of the repository. The global
+// webhooks are returned if owner and name are empty.
+func (c *client) WebhookList(owner, name string) ([]*Webhook, error) {
+	var out []*Webhook
+	err := c.get(c.webhookPath(owner, name), &out)
+	return out, err
+}
+
+// WebhookCreate creates a webhook for the repository, or a global
+// webhook if owner and name are empty.
+func (c *client) WebhookCreate(owner, name string, in *Webhook) (*Webhook, error) {
+	out := new(Webhook)
+	err := c.post(c.webhookPath(owner, name), in, out)
+	return out, err
+}
+
+// WebhookDelete deletes a webhook.
+func (c *client) WebhookDelete(owner, name string, webhook int64) error {
+	uri := fmt.Sprintf("%s/%d", c.webhookPath(owner, name), webhook)
+	return c.delete(uri)
+}
+
+// WebhookTest sends a test delivery to the webhook.
+func (c *client) WebhookTest(owner, name string, webhook int64) (*WebhookDelivery, error) {
+	out := new(WebhookDelivery)
+	uri := fmt.Sprintf("%s/%d/test", c.webhookPath(owner, name), webhook)
+	err := c.post(uri, nil, out)
+	return out, err
+}
+
+// WebhookDeliveries returns the most recent deliveries of the webhook.
+func (c *client) WebhookDeliveries(owner, name string, webhook int64) ([]*WebhookDelivery, error) {
+	var out []*WebhookDelivery
+	uri := fmt.Sprintf("%s/%d/deliveries", c.webhookPath(owner, name), webhook)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// webhookPath returns the path of the repository webhooks, or of
+// the global webhooks if owner and name are empty.
+func (c *client) webhookPath(owner, name string) string {
+	if owner == "" && name == "" {
+		return fmt.Sprintf(pathWebhooks, c.addr)
+	}
+	return fmt.Sprintf(pathRepoWebhooks, c.addr, owner, name)
+}
+
+// AuditList returns the audit events matching the filter.
+func (c *client) AuditList(filter *AuditFilter) ([]*AuditEvent, error) {
+	var out []*AuditEvent
+	uri := fmt.Sprintf(pathAudit, c.addr, auditValues(filter).Encode())
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// AuditExport returns the audit events matching the filter as json lines.
+func (c *client) AuditExport(filter *AuditFilter) (io.ReadCloser, error) {
+	uri := fmt.Sprintf(pathAuditExport, c.addr, auditValues(filter).Encode())
+	return c.open(uri, "GET", nil, nil)
+}
+
 // QueueInfo returns queue info
 func (c *client) QueueInfo() (*Info, error) {
 	out := new(Info)
@@ -463,3 +732,30 @@ func mapValues(params map[string]string) url.Values {
 	}
 	return values
 }
+
+// auditValues converts an audit filter to url.Values
+func auditValues(filter *AuditFilter) url.Values {
+	values := url.Values{}
+	if filter == nil {
+		return values
+	}
+	if filter.Actor != "" {
+		values.Set("actor", filter.Actor)
+	}
+	if filter.Action != "" {
+		values.Set("action", filter.Action)
+	}
+	if filter.Repo != "" {
+		values.Set("repo", filter.Repo)
+	}
+	if filter.Before != 0 {
+		values.Set("before", strconv.FormatInt(filter.Before, 10))
+	}
+	if filter.After != 0 {
+		values.Set("after", strconv.FormatInt(filter.After, 10))
+	}
+	if filter.Limit != 0 {
+		values.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	return values
+}
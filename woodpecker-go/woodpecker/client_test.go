@@ -92,3 +92,100 @@ func Test_LogLevel(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func Test_AuditList(t *testing.T) {
+	fixtureHandler := func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("actor"), "octocat"; got != want {
+			t.Errorf("Want actor filter %q, got %q", want, got)
+		}
+		if got, want := r.URL.Query().Get("after"), "1600000000"; got != want {
+			t.Errorf("Want after filter %q, got %q", want, got)
+		}
+		fmt.Fprint(w, `[{"id": 1, "actor": "octocat", "action": "secret.create", "repo": "octocat/hello-world", "target": "password"}]`)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(fixtureHandler))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+
+	events, err := client.AuditList(&AuditFilter{Actor: "octocat", After: 1600000000})
+	if err != nil {
+		t.Fatalf("could not list audit events: %v", err)
+	}
+	if len(events) != 1 || events[0].Target != "password" {
+		t.Errorf("Unexpected audit events: %v", events)
+	}
+}
+
+func Test_BuildConfig(t *testing.T) {
+	fixtureHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/repos/octocat/hello-world/builds/42/config" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `[{"hash":"a1b2","name":".woodpecker/test.yml","data":"cGlwZWxpbmU6"}]`)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(fixtureHandler))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+
+	configs, err := client.BuildConfig("octocat", "hello-world", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 1 || configs[0].Name != ".woodpecker/test.yml" || string(configs[0].Data) != "pipeline:" {
+		t.Errorf("Unexpected configs: %v", configs)
+	}
+}
+
+func Test_TestHistory(t *testing.T) {
+	fixtureHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/repos/octocat/hello-world/tests/history" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("suite") != "server/api" || r.URL.Query().Get("name") != "TestStream" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"suite":"server/api","name":"TestStream","passed":1,"failed":1,"flaky":true,"results":[{"build_number":2,"status":"passed"},{"build_number":2,"status":"failed"}]}`)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(fixtureHandler))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+
+	history, err := client.TestHistory("octocat", "hello-world", "server/api", "TestStream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !history.Flaky || len(history.Results) != 2 {
+		t.Errorf("Unexpected history: %v", history)
+	}
+}
+
+func Test_Promote(t *testing.T) {
+	fixtureHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/repos/octocat/hello-world/builds/42/promote" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("target") != "production" || r.URL.Query().Get("VERSION") != "1.0" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"number":43,"parent":42,"event":"deployment","deploy_to":"production","promoted":true}`)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(fixtureHandler))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+
+	build, err := client.Promote("octocat", "hello-world", 42, "production", map[string]string{"VERSION": "1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !build.Promoted || build.Parent != 42 || build.Deploy != "production" {
+		t.Errorf("Unexpected build: %v", build)
+	}
+}
@@ -1,6 +1,7 @@
 package woodpecker
 
 import (
+	"io"
 	"net/http"
 )
 
@@ -62,6 +63,20 @@ type Client interface {
 	// Build returns a repository build by number.
 	Build(string, string, int) (*Build, error)
 
+	// BuildConfig returns the pipeline configs the build was created from.
+	BuildConfig(string, string, int) ([]*Config, error)
+
+	// BuildTests returns the test results of the build.
+	BuildTests(string, string, int) ([]*TestResult, error)
+
+	// BuildCoverage returns the coverage of the build and its change
+	// compared to the base build.
+	BuildCoverage(string, string, int) (*Coverage, error)
+
+	// TestHistory returns the results of a test in the latest builds, the
+	// test is identified by its suite and name.
+	TestHistory(owner, name, suite, test string) (*TestHistory, error)
+
 	// BuildLast returns the latest repository build by branch. An empty branch
 	// will result in the default branch.
 	BuildLast(string, string, string) (*Build, error)
@@ -76,6 +91,10 @@ type Client interface {
 	// BuildStart re-starts a stopped build.
 	BuildStart(string, string, int, map[string]string) (*Build, error)
 
+	// BuildRestart restarts the failed or the given workflows of a
+	// finished build, and the workflows depending on them, within the build.
+	BuildRestart(string, string, int, bool, []int) (*Build, error)
+
 	// BuildStop stops the specified running job for given build.
 	BuildStop(string, string, int, int) error
 
@@ -92,6 +111,10 @@ type Client interface {
 	// target environment.
 	Deploy(string, string, int, string, map[string]string) (*Build, error)
 
+	// Promote promotes a successful build to the target environment, only
+	// the workflows and steps of the environment run.
+	Promote(string, string, int, string, map[string]string) (*Build, error)
+
 	// LogsPurge purges the build logs for the specified build.
 	LogsPurge(string, string, int) error
 
@@ -110,6 +133,42 @@ type Client interface {
 	// RegistryDelete deletes a registry.
 	RegistryDelete(owner, name, hostname string) error
 
+	// EnvironmentList returns the deployment environments of the
+	// repository.
+	EnvironmentList(owner, name string) ([]*Environment, error)
+
+	// Environment returns a deployment environment by name.
+	Environment(owner, name, env string) (*Environment, error)
+
+	// EnvironmentCreate creates a deployment environment.
+	EnvironmentCreate(owner, name string, env *Environment) (*Environment, error)
+
+	// EnvironmentPatch updates the protection rules of a deployment
+	// environment.
+	EnvironmentPatch(owner, name, env string, patch *EnvironmentPatch) (*Environment, error)
+
+	// EnvironmentDelete deletes a deployment environment.
+	EnvironmentDelete(owner, name, env string) error
+
+	// DeploymentList returns the deployment history of the environment.
+	DeploymentList(owner, name, env string, page int) ([]*Build, error)
+
+	// Rollback deploys the commit of a previous successful deployment to
+	// the environment again.
+	Rollback(owner, name, env string, num int) (*Build, error)
+
+	// SenderList returns the senders on the allow and block lists of the
+	// repository.
+	SenderList(owner, name string) ([]*Sender, error)
+
+	// SenderUpdate adds the sender to the allow or block list of the
+	// repository.
+	SenderUpdate(owner, name string, sender *Sender) (*Sender, error)
+
+	// SenderDelete removes the sender from the allow and block lists of
+	// the repository.
+	SenderDelete(owner, name, login string) error
+
 	// Secret returns a secret by name.
 	Secret(owner, name, secret string) (*Secret, error)
 
@@ -125,6 +184,47 @@ type Client interface {
 	// SecretDelete deletes a secret.
 	SecretDelete(owner, name, secret string) error
 
+	// OrgRoleList returns the roles granted for an organization.
+	OrgRoleList(owner string) ([]*Role, error)
+
+	// OrgRoleCreate grants a role for an organization.
+	OrgRoleCreate(owner string, role *Role) (*Role, error)
+
+	// OrgRoleDelete revokes a role granted for an organization.
+	OrgRoleDelete(owner, login, role string) error
+
+	// RoleList returns the roles granted for a repository.
+	RoleList(owner, name string) ([]*Role, error)
+
+	// RoleCreate grants a role for a repository.
+	RoleCreate(owner, name string, role *Role) (*Role, error)
+
+	// RoleDelete revokes a role granted for a repository.
+	RoleDelete(owner, name, login, role string) error
+
+	// WebhookList returns the webhooks of the repository. The global
+	// webhooks are returned if owner and name are empty.
+	WebhookList(owner, name string) ([]*Webhook, error)
+
+	// WebhookCreate creates a webhook for the repository, or a global
+	// webhook if owner and name are empty.
+	WebhookCreate(owner, name string, webhook *Webhook) (*Webhook, error)
+
+	// WebhookDelete deletes a webhook.
+	WebhookDelete(owner, name string, webhook int64) error
+
+	// WebhookTest sends a test delivery to the webhook.
+	WebhookTest(owner, name string, webhook int64) (*WebhookDelivery, error)
+
+	// WebhookDeliveries returns the most recent deliveries of the webhook.
+	WebhookDeliveries(owner, name string, webhook int64) ([]*WebhookDelivery, error)
+
+	// AuditList returns the audit events matching the filter.
+	AuditList(filter *AuditFilter) ([]*AuditEvent, error)
+
+	// AuditExport returns the audit events matching the filter as json lines.
+	AuditExport(filter *AuditFilter) (io.ReadCloser, error)
+
 	// QueueInfo returns the queue state.
 	QueueInfo() (*Info, error)
 
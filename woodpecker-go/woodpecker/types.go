@@ -30,6 +30,12 @@ type (
 		IsGated    bool   `json:"gated"`
 		AllowPull  bool   `json:"allow_pr"`
 		Config     string `json:"config_file"`
+
+		// approval rules of gated builds
+		ApprovalCount  int      `json:"approval_count"`
+		ApprovalOrgs   []string `json:"approval_orgs,omitempty"`
+		ApproveMembers bool     `json:"approve_members"`
+		GateForks      bool     `json:"gate_forks"`
 	}
 
 	// RepoPatch defines a repository patch request.
@@ -41,6 +47,12 @@ type (
 		Visibility   *string `json:"visibility"`
 		AllowPull    *bool   `json:"allow_pr,omitempty"`
 		BuildCounter *int    `json:"build_counter,omitempty"`
+
+		// approval rules of gated builds
+		ApprovalCount  *int      `json:"approval_count,omitempty"`
+		ApprovalOrgs   *[]string `json:"approval_orgs,omitempty"`
+		ApproveMembers *bool     `json:"approve_members,omitempty"`
+		GateForks      *bool     `json:"gate_forks,omitempty"`
 	}
 
 	// Build defines a build object.
@@ -48,6 +60,7 @@ type (
 		ID        int64   `json:"id"`
 		Number    int     `json:"number"`
 		Parent    int     `json:"parent"`
+		Promoted  bool    `json:"promoted"`
 		Event     string  `json:"event"`
 		Status    string  `json:"status"`
 		Error     string  `json:"error"`
@@ -72,24 +85,98 @@ type (
 		Reviewer  string  `json:"reviewed_by"`
 		Reviewed  int64   `json:"reviewed_at"`
 		Procs     []*Proc `json:"procs,omitempty"`
+
+		Approvals []*Approval `json:"approvals,omitempty"`
+	}
+
+	// Approval represents the approval of a gated build by a user.
+	Approval struct {
+		Login   string `json:"login"`
+		Created int64  `json:"created"`
 	}
 
 	// Proc represents a process in the build pipeline.
 	Proc struct {
-		ID       int64             `json:"id"`
-		PID      int               `json:"pid"`
-		PPID     int               `json:"ppid"`
-		PGID     int               `json:"pgid"`
-		Name     string            `json:"name"`
-		State    string            `json:"state"`
-		Error    string            `json:"error,omitempty"`
-		ExitCode int               `json:"exit_code"`
-		Started  int64             `json:"start_time,omitempty"`
-		Stopped  int64             `json:"end_time,omitempty"`
-		Machine  string            `json:"machine,omitempty"`
-		Platform string            `json:"platform,omitempty"`
-		Environ  map[string]string `json:"environ,omitempty"`
-		Children []*Proc           `json:"children,omitempty"`
+		ID           int64             `json:"id"`
+		PID          int               `json:"pid"`
+		PPID         int               `json:"ppid"`
+		PGID         int               `json:"pgid"`
+		Name         string            `json:"name"`
+		State        string            `json:"state"`
+		Error        string            `json:"error,omitempty"`
+		ExitCode     int               `json:"exit_code"`
+		Started      int64             `json:"start_time,omitempty"`
+		Stopped      int64             `json:"end_time,omitempty"`
+		PullStarted  int64             `json:"pull_start_time,omitempty"`
+		PullFinished int64             `json:"pull_end_time,omitempty"`
+		Machine      string            `json:"machine,omitempty"`
+		Platform     string            `json:"platform,omitempty"`
+		Environ      map[string]string `json:"environ,omitempty"`
+		Children     []*Proc           `json:"children,omitempty"`
+	}
+
+	// Config represents a pipeline config file of a build.
+	Config struct {
+		Name string `json:"name"`
+		Hash string `json:"hash"`
+		Data []byte `json:"data"`
+	}
+
+	// TestResult represents the result of a single test of a build.
+	TestResult struct {
+		BuildNumber int64   `json:"build_number"`
+		Commit      string  `json:"commit"`
+		PID         int     `json:"pid"`
+		File        string  `json:"file"`
+		Suite       string  `json:"suite"`
+		Name        string  `json:"name"`
+		Status      string  `json:"status"`
+		Duration    float64 `json:"duration"`
+		Message     string  `json:"message,omitempty"`
+	}
+
+	// TestHistory represents the results of a test in the latest builds.
+	TestHistory struct {
+		Suite   string        `json:"suite"`
+		Name    string        `json:"name"`
+		Passed  int           `json:"passed"`
+		Failed  int           `json:"failed"`
+		Skipped int           `json:"skipped"`
+		Flaky   bool          `json:"flaky"`
+		Results []*TestResult `json:"results"`
+	}
+
+	// Coverage represents the coverage of a build and its change compared
+	// to the base build.
+	Coverage struct {
+		Covered      float64           `json:"covered"`
+		CoveredLines int               `json:"covered_lines"`
+		TotalLines   int               `json:"total_lines"`
+		Base         int64             `json:"base,omitempty"`
+		BaseCovered  float64           `json:"base_covered,omitempty"`
+		Change       float64           `json:"change"`
+		DiffCovered  *float64          `json:"diff_covered,omitempty"`
+		Files        []*CoverageChange `json:"files"`
+	}
+
+	// CoverageChange represents the coverage of a source file and its
+	// change compared to the base build.
+	CoverageChange struct {
+		Name         string   `json:"name"`
+		Covered      float64  `json:"covered"`
+		CoveredLines int      `json:"covered_lines"`
+		TotalLines   int      `json:"total_lines"`
+		BaseCovered  *float64 `json:"base_covered,omitempty"`
+		Change       float64  `json:"change"`
+		Changed      bool     `json:"changed,omitempty"`
+	}
+
+	// Sender represents a sender on the allow or block list of a
+	// repository.
+	Sender struct {
+		Login string `json:"login"`
+		Allow bool   `json:"allow"`
+		Block bool   `json:"block"`
 	}
 
 	// Registry represents a docker registry with credentials.
@@ -109,6 +196,80 @@ type (
 		Value  string   `json:"value,omitempty"`
 		Images []string `json:"image"`
 		Events []string `json:"event"`
+
+		Environments []string `json:"environment,omitempty"`
+	}
+
+	// Environment represents a deployment environment of a repository and
+	// the rules protecting deployments to it.
+	Environment struct {
+		ID              int64    `json:"id"`
+		Name            string   `json:"name"`
+		Branches        []string `json:"branches"`
+		RequireApproval bool     `json:"require_approval"`
+		Deployed        *Build   `json:"deployed,omitempty"`
+	}
+
+	// EnvironmentPatch defines an environment patch request.
+	EnvironmentPatch struct {
+		Branches        *[]string `json:"branches,omitempty"`
+		RequireApproval *bool     `json:"require_approval,omitempty"`
+	}
+
+	// Role represents a woodpecker managed role granted to a user for an
+	// organization or a single repository.
+	Role struct {
+		ID     int64  `json:"id"`
+		Owner  string `json:"owner"`
+		RepoID int64  `json:"repo_id"`
+		Login  string `json:"login"`
+		Name   string `json:"role"`
+	}
+
+	// Webhook represents an outbound webhook subscription for build
+	// lifecycle events.
+	Webhook struct {
+		ID     int64    `json:"id"`
+		URL    string   `json:"url"`
+		Secret string   `json:"secret,omitempty"`
+		Events []string `json:"events"`
+		Active bool     `json:"active"`
+	}
+
+	// WebhookDelivery represents a single delivery of an event to a webhook.
+	WebhookDelivery struct {
+		ID        int64  `json:"id"`
+		WebhookID int64  `json:"webhook_id"`
+		Event     string `json:"event"`
+		Payload   string `json:"payload"`
+		Attempts  int    `json:"attempts"`
+		Status    int    `json:"status_code"`
+		Error     string `json:"error,omitempty"`
+		Success   bool   `json:"success"`
+		Created   int64  `json:"created"`
+		Finished  int64  `json:"finished"`
+	}
+
+	// AuditEvent represents a security relevant action recorded in the
+	// audit log.
+	AuditEvent struct {
+		ID      int64             `json:"id"`
+		Created int64             `json:"created"`
+		Actor   string            `json:"actor"`
+		Action  string            `json:"action"`
+		Repo    string            `json:"repo,omitempty"`
+		Target  string            `json:"target,omitempty"`
+		Data    map[string]string `json:"data,omitempty"`
+	}
+
+	// AuditFilter defines the optional conditions to query the audit log.
+	AuditFilter struct {
+		Actor  string
+		Action string
+		Repo   string
+		Before int64
+		After  int64
+		Limit  int
 	}
 
 	// Activity represents an item in the user's feed or timeline.